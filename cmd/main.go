@@ -11,10 +11,15 @@ import (
 	"github.com/NuEventTeam/events/internal/features/event"
 	"github.com/NuEventTeam/events/internal/features/handlers"
 	"github.com/NuEventTeam/events/internal/features/notification"
+	"github.com/NuEventTeam/events/internal/features/orders"
+	"github.com/NuEventTeam/events/internal/features/payment"
 	"github.com/NuEventTeam/events/internal/features/sms_provider"
+	"github.com/NuEventTeam/events/internal/features/token"
 	"github.com/NuEventTeam/events/internal/features/user"
+	"github.com/NuEventTeam/events/internal/features/wallet"
 	"github.com/NuEventTeam/events/internal/storage/database"
 	"github.com/NuEventTeam/events/internal/storage/keydb"
+	"github.com/NuEventTeam/events/internal/storage/repository/postgres"
 
 	"google.golang.org/api/option"
 	"log"
@@ -36,23 +41,40 @@ func main() {
 
 	db := database.NewDatabase(context.Background(), cfg.Database)
 	cache := keydb.New(context.Background(), cfg.Cache)
+	store := postgres.New(db)
 
 	sms := sms_provider.New(cfg.SMS)
-	assetsSvc := assets.NewS3Storage(cfg.CDN)
+	assetsSvc := assets.New(cfg.CDN, cfg.Upload)
 
 	userSvc := user.NewEventSvc(db, assetsSvc)
 
-	eventSvc := event.NewEventSvc(db, assetsSvc)
+	ordersSvc := orders.New(store, payment.New(cfg.Payments), cfg.Payments, cfg.IsDev())
 
-	authSvc := auth.New(db, sms, cfg.JWT)
+	eventSvc := event.NewEventSvc(db, assetsSvc, cache, ordersSvc, cfg.Event)
 
-	httpHandler := handlers.New(eventSvc, cache, userSvc, assetsSvc, authSvc, cfg.JWT.Secret, db)
+	tokens := token.MustNew(cfg.JWT)
+	ticketSigner := token.MustNewTicketSigner(cfg.Tickets, cfg.IsDev())
+	wallets := wallet.MustNew(cfg.Wallet)
 
-	application := app.New(cfg.Http.Port, httpHandler)
+	authSvc := auth.New(store, sms, tokens, cache, cfg.OTP, cfg.IsDev())
+
+	httpHandler := handlers.New(eventSvc, cache, userSvc, assetsSvc, authSvc, tokens, db, store, ordersSvc, ticketSigner, wallets)
+
+	// leave room for the non file form fields next to the images
+	bodyLimit := int(assetsSvc.Limits().MaxRequestSize) + 1<<20
+
+	application := app.New(cfg.Http.Port, bodyLimit, httpHandler)
 
 	go application.MustRun()
 
-	go log.Println(chat.RunChatServer(cfg.Ws.Port, db))
+	go func() {
+		log.Println(chat.RunChatServer(cfg.Ws.Port, store, tokens, authSvc, cache))
+	}()
+
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	go eventSvc.RunStatusScheduler(schedulerCtx, cfg.Event.StatusInterval)
+	go eventSvc.RunRecurrenceScheduler(schedulerCtx, cfg.Event.RecurrenceInterval)
+	go ordersSvc.RunHoldExpiry(schedulerCtx)
 
 	stop := make(chan os.Signal, 1)
 
@@ -60,6 +82,7 @@ func main() {
 
 	<-stop
 
+	stopScheduler()
 	application.Stop()
 	log.Println("application stopped")
 }
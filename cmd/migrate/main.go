@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"log"
+	"os"
+)
+
+func main() {
+	steps := flag.Int("steps", 0, "number of migrations to apply (up: 0 means all, down: 0 means 1)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: migrate [-steps n] up|down|version\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := "up"
+	if flag.NArg() > 0 {
+		command = flag.Arg(0)
+	}
+
+	cfg := config.MustLoad()
+
+	ctx := context.Background()
+
+	db := database.NewDatabase(ctx, cfg.Database)
+
+	switch command {
+	case "up":
+		_, err := db.MigrateUp(ctx, *steps)
+		if err != nil && !errors.Is(err, database.ErrNoMigrations) {
+			log.Fatal(err)
+		}
+	case "down":
+		_, err := db.MigrateDown(ctx, *steps)
+		if err != nil && !errors.Is(err, database.ErrNoMigrations) {
+			log.Fatal(err)
+		}
+	case "version":
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	version, err := db.MigrationVersion(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("schema version", version)
+}
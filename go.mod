@@ -1,9 +1,10 @@
 module github.com/NuEventTeam/events
 
-go 1.21.0
+go 1.22.2
 
 require (
 	firebase.google.com/go v3.13.0+incompatible
+	github.com/HugoSmits86/nativewebp v0.9.3
 	github.com/Masterminds/squirrel v1.5.4
 	github.com/aws/aws-sdk-go-v2 v1.26.0
 	github.com/aws/aws-sdk-go-v2/credentials v1.17.8
@@ -19,7 +20,10 @@ require (
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	github.com/oklog/ulid/v2 v2.1.0
 	github.com/redis/go-redis/v9 v9.5.1
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	github.com/smallstep/pkcs7 v0.2.3
 	golang.org/x/crypto v0.21.0
+	golang.org/x/image v0.15.0
 	google.golang.org/api v0.174.0
 )
 
@@ -17,10 +17,11 @@ type App struct {
 
 func New(
 	port int,
+	bodyLimit int,
 	httpHandler *handlers.Handler,
 ) *App {
 
-	httpServer := newHttpServer()
+	httpServer := newHttpServer(bodyLimit)
 
 	httpHandler.SetUpEventRoutes(httpServer)
 
@@ -49,11 +50,11 @@ func (a *App) Stop() {
 	log.Fatal(a.httpServer.Shutdown())
 }
 
-func newHttpServer() *fiber.App {
+func newHttpServer(bodyLimit int) *fiber.App {
 	httpServer := fiber.New(fiber.Config{
 		JSONEncoder:  sonic.Marshal,
 		JSONDecoder:  sonic.Unmarshal,
-		BodyLimit:    1024 * 1024 * 1024,
+		BodyLimit:    bodyLimit,
 		ReadTimeout:  20 * time.Hour,
 		WriteTimeout: 20 * time.Hour,
 	})
@@ -12,8 +12,14 @@ type Config struct {
 	Database Database `yaml:"database"`
 	Cache    Cache    `yaml:"cache"`
 	JWT      JWT      `yaml:"jwt"`
+	Tickets  Tickets  `yaml:"tickets"`
+	OTP      OTP      `yaml:"otp"`
 	Http     Http     `yaml:"http"`
 	CDN      CDN      `yaml:"cdn"`
+	Upload   Upload   `yaml:"upload"`
+	Event    Event    `yaml:"event"`
+	Payments Payments `yaml:"payments"`
+	Wallet   Wallet   `yaml:"wallet"`
 	SMS      SMS      `yaml:"sms"`
 	Ws       Ws       `yaml:"ws"`
 }
@@ -34,8 +40,59 @@ type Ws struct {
 }
 
 type JWT struct {
-	Secret string        `yaml:"secret"`
-	Expiry time.Duration `yaml:"expiry"`
+	// Secret is the HS256 key of tokens issued without a kid.
+	Secret        string        `yaml:"secret"`
+	Expiry        time.Duration `yaml:"expiry" env-default:"24h"`
+	RefreshExpiry time.Duration `yaml:"refresh_expiry" env-default:"168h"`
+	TicketExpiry  time.Duration `yaml:"ticket_expiry" env-default:"24h"`
+	Leeway        time.Duration `yaml:"leeway" env-default:"30s"`
+	Issuer        string        `yaml:"issuer" env-default:"nuevent"`
+	Audience      string        `yaml:"audience" env-default:"nuevent-api"`
+	// ActiveKey is the kid new tokens are signed with, the other keys are
+	// only used to verify tokens issued before a rotation.
+	ActiveKey string   `yaml:"active_key"`
+	Keys      []JWTKey `yaml:"keys"`
+	// LegacyUntil accepts the tokens signed with Secret before tokens carried
+	// their type, issuer and audience until then, so upgrading does not log
+	// every user out. Those tokens lived a day at most.
+	LegacyUntil time.Time `yaml:"legacy_until"`
+}
+
+type OTP struct {
+	Length         int           `yaml:"length" env-default:"6"`
+	Expiry         time.Duration `yaml:"expiry" env-default:"5m"`
+	ResendCooldown time.Duration `yaml:"resend_cooldown" env-default:"1m"`
+	// MaxAttempts failed verifications or logins per phone, MaxIPAttempts per
+	// ip, within AttemptWindow lock the phone or ip out. Every lockout in a
+	// row doubles the cooldown, starting at LockoutBase up to LockoutMax.
+	MaxAttempts   int64         `yaml:"max_attempts" env-default:"5"`
+	MaxIPAttempts int64         `yaml:"max_ip_attempts" env-default:"30"`
+	AttemptWindow time.Duration `yaml:"attempt_window" env-default:"15m"`
+	LockoutBase   time.Duration `yaml:"lockout_base" env-default:"1m"`
+	LockoutMax    time.Duration `yaml:"lockout_max" env-default:"24h"`
+}
+
+type JWTKey struct {
+	ID        string `yaml:"id"`
+	Algorithm string `yaml:"algorithm"`
+	// Secret is used by HS256 keys, RS256 and EdDSA keys are read from PEM
+	// files. A key with only a public key file can verify but not sign.
+	Secret         string `yaml:"secret"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+	PublicKeyFile  string `yaml:"public_key_file"`
+}
+
+// Tickets configures the Ed25519 key signing tickets. The private key file is
+// required outside dev, in dev a key is generated on start and tickets issued
+// before a restart stop verifying.
+type Tickets struct {
+	KeyID          string `yaml:"key_id" env-default:"t1"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+	// RetiredKeys maps the ids of earlier keys to their public key files, so
+	// tickets signed before a rotation still verify.
+	RetiredKeys map[string]string `yaml:"retired_keys"`
+	// Grace keeps a ticket valid for a while after its session ends.
+	Grace time.Duration `yaml:"grace" env-default:"12h"`
 }
 
 type GRPC struct {
@@ -66,12 +123,80 @@ type Cache struct {
 
 type CDN struct {
 	URL             string `yaml:"url"`
+	Backend         string `yaml:"backend" env-default:"local"`
+	LocalDir        string `yaml:"local_dir" env-default:"./static"`
+	Endpoint        string `yaml:"endpoint"`
+	UsePathStyle    bool   `yaml:"use_path_style"`
 	KeyID           string `yaml:"key_id"`
 	SecretAccessKey string `yaml:"secret_access_key"`
 	Region          string `yaml:"region"`
 	BucketName      string `yaml:"bucket_name"`
 }
 
+type Upload struct {
+	MaxFileSize    int64 `yaml:"max_file_size" env-default:"10485760"`
+	MaxRequestSize int64 `yaml:"max_request_size" env-default:"41943040"`
+	MaxEventImages int   `yaml:"max_event_images" env-default:"10"`
+	MaxDimension   int   `yaml:"max_dimension" env-default:"8000"`
+	MaxPixels      int64 `yaml:"max_pixels" env-default:"40000000"`
+}
+
+type Event struct {
+	InvitationExpiry time.Duration `yaml:"invitation_expiry" env-default:"168h"`
+	// StatusInterval is how often events are moved to ongoing and finished.
+	StatusInterval time.Duration `yaml:"status_interval" env-default:"1m"`
+	// RecurrenceHorizon is how far ahead occurrences of recurring events are
+	// created, RecurrenceInterval how often the horizon is moved.
+	RecurrenceHorizon  time.Duration `yaml:"recurrence_horizon" env-default:"720h"`
+	RecurrenceInterval time.Duration `yaml:"recurrence_interval" env-default:"1h"`
+}
+
+type Payments struct {
+	Provider string `yaml:"provider" env-default:"fake"`
+	Currency string `yaml:"currency" env-default:"KZT"`
+	// WebhookSecret verifies the notifications of the provider.
+	WebhookSecret string `yaml:"webhook_secret"`
+	// HoldDuration is how long a seat is kept for an unpaid order, expired
+	// holds are released every ExpiryInterval.
+	HoldDuration   time.Duration `yaml:"hold_duration" env-default:"15m"`
+	ExpiryInterval time.Duration `yaml:"expiry_interval" env-default:"1m"`
+}
+
+// Wallet configures the Apple and Google Wallet passes of tickets, a wallet
+// whose certificate or credentials are not set is disabled.
+type Wallet struct {
+	Organization string       `yaml:"organization" env-default:"NuEvent"`
+	Apple        AppleWallet  `yaml:"apple"`
+	Google       GoogleWallet `yaml:"google"`
+}
+
+type AppleWallet struct {
+	PassTypeID string `yaml:"pass_type_id"`
+	TeamID     string `yaml:"team_id"`
+	// CertFile and KeyFile are the PEM pass type certificate and its key,
+	// WWDRFile the Apple intermediate certificate, left out with self-signed
+	// certificates.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	WWDRFile string `yaml:"wwdr_file"`
+	// IconFile is the png shown on the pass, a plain one is used without it.
+	IconFile string `yaml:"icon_file"`
+}
+
+type GoogleWallet struct {
+	IssuerID string `yaml:"issuer_id"`
+	// CredentialsFile is the JSON key of the service account signing the
+	// save links.
+	CredentialsFile string   `yaml:"credentials_file"`
+	Origins         []string `yaml:"origins"`
+}
+
+// IsDev reports whether the service runs locally, where debugging helpers such
+// as echoing one time passwords are allowed.
+func (c *Config) IsDev() bool {
+	return c.Env == "local" || c.Env == "dev"
+}
+
 func MustLoad() *Config {
 	path := "./config/local.yaml"
 
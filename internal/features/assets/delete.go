@@ -2,9 +2,8 @@ package assets
 
 import (
 	"context"
+	"errors"
 	"github.com/NuEventTeam/events/pkg"
-	"log"
-	"os"
 	"path"
 	"strings"
 	"sync"
@@ -21,27 +20,37 @@ func (s *Assets) DeleteFile(ctx context.Context, path ...string) error {
 }
 
 func (s *Assets) delete(ctx context.Context, keys ...string) error {
+	var objects []string
+	for _, k := range keys {
+		k = strings.TrimPrefix(k, pkg.CDNBaseUrl)
+		k = strings.TrimPrefix(k, "/")
+		if k == "" {
+			continue
+		}
+		objects = append(objects, k)
+		if path.Ext(k) == renditionExt {
+			for _, r := range DefaultRenditions {
+				if r.Name != RenditionFull {
+					objects = append(objects, RenditionKey(k, r.Name))
+				}
+			}
+		}
+	}
+
 	wg := &sync.WaitGroup{}
-	wg.Add(len(keys))
+	wg.Add(len(objects))
 
-	for _, k := range keys {
-		k := k
+	errs := make([]error, len(objects))
+
+	for i, k := range objects {
+		i, k := i, k
 		go func(wg *sync.WaitGroup) {
 			defer wg.Done()
-			k = strings.TrimPrefix(k, pkg.CDNBaseUrl)
-			err := os.Remove(path.Join("static", k))
-			if err != nil {
-				log.Println("while deleting message", err)
-			}
-			//_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
-			//	Bucket: aws.String(s.bucket),
-			//	Key:    aws.String(k),
-			//})
-			//if err != nil {
-			//	log.Println("error while deleting images")
-			//}
+			errs[i] = s.store.Delete(ctx, k)
 		}(wg)
 
 	}
-	return nil
+	wg.Wait()
+
+	return errors.Join(errs...)
 }
@@ -1,8 +1,8 @@
 package assets
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
-	"log"
 )
 
 func (s Assets) GetFile() fiber.Handler {
@@ -12,17 +12,36 @@ func (s Assets) GetFile() fiber.Handler {
 			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"ok": false, "err": "set namespace"})
 		}
 
-		path := ctx.Params("namespace") + "/" + ctx.Params("key") + "/" + ctx.Params("filename")
-		log.Println(path)
-		exist := s.KeyExists(ctx.Context(), path)
-		if !exist {
+		key := ctx.Params("namespace") + "/" + ctx.Params("key") + "/" + ctx.Params("filename")
+
+		info, err := s.store.Head(ctx.Context(), key)
+		if errors.Is(err, ErrObjectNotFound) {
+			return ctx.SendStatus(fiber.StatusNotFound)
+		}
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"ok": false, "err": err.Error()})
+		}
+
+		url, err := s.store.PresignGet(ctx.Context(), key, presignExpiry)
+		if err == nil {
+			return ctx.Redirect(url, fiber.StatusTemporaryRedirect)
+		}
+		if !errors.Is(err, ErrPresignNotSupported) {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"ok": false, "err": err.Error()})
+		}
+
+		body, err := s.store.Get(ctx.Context(), key)
+		if errors.Is(err, ErrObjectNotFound) {
 			return ctx.SendStatus(fiber.StatusNotFound)
 		}
-		url, err := s.GetObjectURL(path)
-		log.Println(url)
 		if err != nil {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"ok": false, "err": err})
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"ok": false, "err": err.Error()})
 		}
-		return ctx.Redirect(url.URL, fiber.StatusPermanentRedirect)
+
+		if info.ContentType != "" {
+			ctx.Set(fiber.HeaderContentType, info.ContentType)
+		}
+
+		return ctx.SendStream(body, int(info.Size))
 	}
 }
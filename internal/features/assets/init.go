@@ -1,65 +1,61 @@
 package assets
 
 import (
-	"context"
-	"errors"
 	"github.com/NuEventTeam/events/internal/config"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
-	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"net/http"
+	"log"
 	"time"
 )
 
-type Assets struct {
-	client          *s3.Client        `json:"client,omitempty"`
-	presignedClient *s3.PresignClient `json:"presignedClient,omitempty"`
-	bucket          string            `json:"bucket,omitempty"`
-}
+const (
+	BackendLocal  = "local"
+	BackendS3     = "s3"
+	BackendMemory = "memory"
 
-func NewS3Storage(cfg config.CDN) *Assets {
+	presignExpiry = time.Hour
+)
 
-	client := s3.New(s3.Options{
-		Region:      cfg.Region,
-		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(cfg.KeyID, cfg.SecretAccessKey, "")),
-	})
+type Assets struct {
+	store  ObjectStore
+	limits config.Upload
+}
 
-	presignerClient := s3.NewPresignClient(client)
+func New(cfg config.CDN, limits config.Upload) *Assets {
+	var store ObjectStore
 
-	return &Assets{
-		client:          client,
-		presignedClient: presignerClient,
-		bucket:          cfg.BucketName,
+	switch cfg.Backend {
+	case BackendLocal, "":
+		dir := cfg.LocalDir
+		if dir == "" {
+			dir = "./static"
+		}
+		store = NewLocalStore(dir)
+	case BackendS3:
+		store = NewS3Store(cfg)
+	case BackendMemory:
+		store = NewMemoryStore()
+	default:
+		log.Fatalf("unknown cdn backend %q", cfg.Backend)
 	}
-}
 
-func (s *Assets) GetObjectURL(objectKey string) (*v4.PresignedHTTPRequest, error) {
+	assets := NewWithStore(store)
+	assets.limits = withDefaults(limits)
 
-	request, err := s.presignedClient.PresignGetObject(context.TODO(), &s3.GetObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(objectKey),
-	}, s3.WithPresignExpires(time.Hour))
+	return assets
+}
 
-	if err != nil {
-		return nil, err
-	}
+func NewWithStore(store ObjectStore) *Assets {
+	return &Assets{store: store, limits: DefaultUploadLimits}
+}
 
-	return request, err
+func (s *Assets) Store() ObjectStore {
+	return s.store
 }
 
-func (s *Assets) KeyExists(ctx context.Context, objectKey string) bool {
-	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(objectKey),
-	})
-	if err != nil {
-		var responseError *awshttp.ResponseError
-		if errors.As(err, &responseError) && responseError.ResponseError.HTTPStatusCode() == http.StatusNotFound {
-			return false
-		}
-		return false
+// LocalDir returns the directory files are kept in when the local backend is
+// used, empty otherwise.
+func (s *Assets) LocalDir() string {
+	if local, ok := s.store.(*LocalStore); ok {
+		return local.Root()
 	}
-	return true
+	return ""
 }
@@ -0,0 +1,104 @@
+package assets
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/nfnt/resize"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"path"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	RenditionThumb = "thumb"
+	RenditionCard  = "card"
+	RenditionFull  = "full"
+
+	renditionExt = ".webp"
+)
+
+type Rendition struct {
+	Name      string
+	MaxWidth  uint
+	MaxHeight uint
+}
+
+// DefaultRenditions are produced for every processed upload. The full
+// rendition is stored at the image key itself so existing urls keep working.
+var DefaultRenditions = []Rendition{
+	{Name: RenditionThumb, MaxWidth: 160, MaxHeight: 160},
+	{Name: RenditionCard, MaxWidth: 600, MaxHeight: 600},
+	{Name: RenditionFull, MaxWidth: 1600, MaxHeight: 1600},
+}
+
+// RenditionKey returns the object key of the named rendition of key.
+func RenditionKey(key, name string) string {
+	if name == RenditionFull {
+		return key
+	}
+	return strings.TrimSuffix(key, path.Ext(key)) + "_" + name + renditionExt
+}
+
+// RenditionURLs returns the cdn url of every default rendition of key, nil
+// for images stored before renditions were introduced.
+func RenditionURLs(key string) map[string]string {
+	key = strings.TrimPrefix(key, pkg.CDNBaseUrl)
+	if path.Ext(key) != renditionExt {
+		return nil
+	}
+
+	urls := make(map[string]string, len(DefaultRenditions))
+	for _, r := range DefaultRenditions {
+		urls[r.Name] = pkg.CDNBaseUrl + RenditionKey(key, r.Name)
+	}
+	return urls
+}
+
+// WithRenditions decodes the upload, applies its EXIF orientation and encodes
+// every rendition as WebP. Re-encoding drops all metadata of the original.
+// Renditions keep the aspect ratio and are never upscaled.
+func WithRenditions(renditions ...Rendition) OptFunc {
+	return func(img *Image) error {
+		if len(renditions) == 0 {
+			renditions = DefaultRenditions
+		}
+
+		data, err := io.ReadAll(img.file)
+		if err != nil {
+			return err
+		}
+
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+
+		decoded = applyOrientation(decoded, jpegOrientation(data))
+
+		img.renditions = make(map[string][]byte, len(renditions))
+		for _, r := range renditions {
+			resized := resize.Thumbnail(r.MaxWidth, r.MaxHeight, decoded, resize.Lanczos3)
+
+			buf := &bytes.Buffer{}
+			if err := nativewebp.Encode(buf, resized, nil); err != nil {
+				return fmt.Errorf("encoding %s rendition: %w", r.Name, err)
+			}
+			img.renditions[r.Name] = buf.Bytes()
+		}
+
+		img.file = nil
+		img.ext = renditionExt
+		if img.Filename != nil {
+			img.SetFilename(strings.TrimSuffix(*img.Filename, path.Ext(*img.Filename)) + renditionExt)
+		}
+
+		return nil
+	}
+}
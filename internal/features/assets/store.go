@@ -0,0 +1,30 @@
+package assets
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrObjectNotFound      = errors.New("object not found")
+	ErrPresignNotSupported = errors.New("presigned urls are not supported by the backend")
+)
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// ObjectStore is the storage backend behind Assets. Get and Head return
+// ErrObjectNotFound for missing keys, Delete of a missing key is not an error.
+type ObjectStore interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Head(ctx context.Context, key string) (ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
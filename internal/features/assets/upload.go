@@ -1,64 +1,106 @@
 package assets
 
 import (
-	"bufio"
+	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"log"
-	"os"
-	"path"
+	"strings"
 	"sync"
 )
 
-func (s *Assets) Upload(ctx context.Context, images ...Image) {
-	wg := &sync.WaitGroup{}
+type UploadError struct {
+	Key string
+	Err error
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("upload %s: %v", e.Key, e.Err)
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// UploadErrors holds one entry per object that failed to upload.
+type UploadErrors []*UploadError
+
+func (e UploadErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Upload stores the images, every rendition of processed ones, concurrently.
+// The returned error is UploadErrors when at least one object failed; the
+// others are left in place, callers that abort should Discard them.
+func (s *Assets) Upload(ctx context.Context, images ...Image) error {
+	type object struct {
+		key         string
+		body        io.Reader
+		contentType string
+	}
 
-	wg.Add(len(images))
+	var objects []object
 	for _, img := range images {
-		img := img
-		go func(wg *sync.WaitGroup) {
-			defer wg.Done()
-			if img.Filename == nil {
-				return
+		if img.Filename == nil {
+			continue
+		}
+		if len(img.renditions) > 0 {
+			for name, data := range img.renditions {
+				objects = append(objects, object{RenditionKey(*img.Filename, name), bytes.NewReader(data), GetContentType[renditionExt]})
 			}
-			//_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
-			//	Bucket:             aws.String(s.bucket),
-			//	Key:                img.Filename,
-			//	Body:               img.file,
-			//	ContentDisposition: aws.String(fmt.Sprintf("inline; filename=%s", img.Filename)),
-			//	ContentType:        aws.String(GetContentType[img.ext]),
-			//})
+			continue
+		}
+		if img.file != nil {
+			objects = append(objects, object{*img.Filename, img.file, GetContentType[img.ext]})
+		}
+	}
 
-			absPath := path.Join("./static", *img.Filename)
-			dir, _ := path.Split(absPath)
-			err := os.MkdirAll(dir, os.ModePerm)
-			if err != nil {
-				log.Println(err)
-				return
-			}
-			file, err := os.Create(absPath)
-			if err != nil {
-				log.Println(err)
-				return
-			}
-			writer := bufio.NewWriter(file)
-			scanner := bufio.NewScanner(img.file)
-			for scanner.Scan() {
-				text := scanner.Bytes()
-				text = append(text, '\n')
-				_, err := writer.Write(append(text)[:])
-				if err != nil {
-					log.Println(err)
-					return
-				}
+	wg := &sync.WaitGroup{}
+	errs := make([]*UploadError, len(objects))
 
-			}
+	wg.Add(len(objects))
+	for i, obj := range objects {
+		i, obj := i, obj
+		go func(wg *sync.WaitGroup) {
+			defer wg.Done()
 
-			err = writer.Flush()
+			err := s.store.Put(ctx, obj.key, obj.body, obj.contentType)
 			if err != nil {
-				log.Println("error while uploading to s3")
+				errs[i] = &UploadError{Key: obj.key, Err: err}
 			}
 		}(wg)
 
 	}
 	wg.Wait()
+
+	var failed UploadErrors
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
+
+// Discard removes the images' objects, it is used to compensate an upload
+// whose database changes were rolled back.
+func (s *Assets) Discard(ctx context.Context, images ...Image) {
+	keys := make([]string, 0, len(images))
+	for _, img := range images {
+		if img.Filename != nil {
+			keys = append(keys, *img.Filename)
+		}
+	}
+
+	if err := s.delete(context.WithoutCancel(ctx), keys...); err != nil {
+		log.Println("while discarding uploaded images", err)
+	}
 }
@@ -0,0 +1,176 @@
+package assets
+
+import (
+	"fmt"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/gofiber/fiber/v2"
+	"image"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+const (
+	ViolationTooMany      = "too_many_files"
+	ViolationFileTooLarge = "file_too_large"
+	ViolationTooLarge     = "request_too_large"
+	ViolationType         = "unsupported_type"
+	ViolationCorrupt      = "unreadable_image"
+	ViolationDimensions   = "dimensions_too_large"
+)
+
+var DefaultUploadLimits = config.Upload{
+	MaxFileSize:    10 << 20,
+	MaxRequestSize: 40 << 20,
+	MaxEventImages: 10,
+	MaxDimension:   8000,
+	MaxPixels:      40_000_000,
+}
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+type Violation struct {
+	File    string `json:"file,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists everything wrong with an upload, Status is the http
+// status the request should be rejected with.
+type ValidationError struct {
+	Status     int
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(status int, v Violation) {
+	if e.Status == 0 || status == fiber.StatusRequestEntityTooLarge {
+		e.Status = status
+	}
+	e.Violations = append(e.Violations, v)
+}
+
+func withDefaults(limits config.Upload) config.Upload {
+	if limits.MaxFileSize <= 0 {
+		limits.MaxFileSize = DefaultUploadLimits.MaxFileSize
+	}
+	if limits.MaxRequestSize <= 0 {
+		limits.MaxRequestSize = DefaultUploadLimits.MaxRequestSize
+	}
+	if limits.MaxEventImages <= 0 {
+		limits.MaxEventImages = DefaultUploadLimits.MaxEventImages
+	}
+	if limits.MaxDimension <= 0 {
+		limits.MaxDimension = DefaultUploadLimits.MaxDimension
+	}
+	if limits.MaxPixels <= 0 {
+		limits.MaxPixels = DefaultUploadLimits.MaxPixels
+	}
+	return limits
+}
+
+func (s *Assets) Limits() config.Upload {
+	return s.limits
+}
+
+// ValidateImages checks the uploaded files before any of them is decoded:
+// at most maxFiles files, size limits, the sniffed content type and the
+// dimensions read from the image header.
+func (s *Assets) ValidateImages(files []*multipart.FileHeader, maxFiles int) *ValidationError {
+	verr := &ValidationError{}
+
+	if maxFiles < 0 {
+		maxFiles = 0
+	}
+	if len(files) > maxFiles {
+		verr.add(fiber.StatusBadRequest, Violation{
+			Code:    ViolationTooMany,
+			Message: fmt.Sprintf("at most %d images can be uploaded", maxFiles),
+		})
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.Size
+
+		if f.Size > s.limits.MaxFileSize {
+			verr.add(fiber.StatusRequestEntityTooLarge, Violation{
+				File:    f.Filename,
+				Code:    ViolationFileTooLarge,
+				Message: fmt.Sprintf("file is larger than %d bytes", s.limits.MaxFileSize),
+			})
+			continue
+		}
+
+		if v, status, ok := s.checkImage(f); !ok {
+			verr.add(status, v)
+		}
+	}
+
+	if total > s.limits.MaxRequestSize {
+		verr.add(fiber.StatusRequestEntityTooLarge, Violation{
+			Code:    ViolationTooLarge,
+			Message: fmt.Sprintf("images are larger than %d bytes in total", s.limits.MaxRequestSize),
+		})
+	}
+
+	if len(verr.Violations) > 0 {
+		return verr
+	}
+	return nil
+}
+
+func (s *Assets) checkImage(f *multipart.FileHeader) (Violation, int, bool) {
+	file, err := f.Open()
+	if err != nil {
+		return Violation{File: f.Filename, Code: ViolationCorrupt, Message: "cannot open file"}, fiber.StatusBadRequest, false
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return Violation{File: f.Filename, Code: ViolationCorrupt, Message: "cannot read file"}, fiber.StatusBadRequest, false
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if !allowedImageTypes[contentType] {
+		return Violation{
+			File:    f.Filename,
+			Code:    ViolationType,
+			Message: fmt.Sprintf("%s is not a supported image type", contentType),
+		}, fiber.StatusUnsupportedMediaType, false
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return Violation{File: f.Filename, Code: ViolationCorrupt, Message: "cannot read file"}, fiber.StatusBadRequest, false
+	}
+
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return Violation{File: f.Filename, Code: ViolationCorrupt, Message: "cannot read image header"}, fiber.StatusBadRequest, false
+	}
+
+	if cfg.Width > s.limits.MaxDimension || cfg.Height > s.limits.MaxDimension ||
+		int64(cfg.Width)*int64(cfg.Height) > s.limits.MaxPixels {
+		return Violation{
+			File:    f.Filename,
+			Code:    ViolationDimensions,
+			Message: fmt.Sprintf("image is %dx%d, the limit is %d pixels per side and %d pixels in total", cfg.Width, cfg.Height, s.limits.MaxDimension, s.limits.MaxPixels),
+		}, fiber.StatusUnprocessableEntity, false
+	}
+
+	return Violation{}, 0, true
+}
@@ -3,19 +3,61 @@ package auth
 import (
 	"github.com/NuEventTeam/events/internal/config"
 	"github.com/NuEventTeam/events/internal/features/sms_provider"
-	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/features/token"
+	"github.com/NuEventTeam/events/internal/storage/keydb"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"time"
 )
 
 type Auth struct {
-	db          *database.Database
-	jwt         config.JWT
+	store       repository.Store
+	tokens      *token.Service
 	smsProvider *sms_provider.SMSProvider
+	cache       *keydb.Cache
+	limiter     limiter
+	otp         config.OTP
+	// echoOtp returns sent codes in the response, only for local development.
+	echoOtp bool
 }
 
-func New(db *database.Database, sms *sms_provider.SMSProvider, cfg config.JWT) *Auth {
+func New(store repository.Store, sms *sms_provider.SMSProvider, tokens *token.Service, cache *keydb.Cache, otp config.OTP, echoOtp bool) *Auth {
+	otp = otpDefaults(otp)
+
 	return &Auth{
-		db:          db,
-		jwt:         cfg,
+		store:       store,
+		tokens:      tokens,
 		smsProvider: sms,
+		cache:       cache,
+		limiter:     limiter{cache: cache, cfg: otp},
+		otp:         otp,
+		echoOtp:     echoOtp,
+	}
+}
+
+func otpDefaults(cfg config.OTP) config.OTP {
+	if cfg.Length < 4 || cfg.Length > 10 {
+		cfg.Length = 6
+	}
+	if cfg.Expiry <= 0 {
+		cfg.Expiry = 5 * time.Minute
+	}
+	if cfg.ResendCooldown <= 0 {
+		cfg.ResendCooldown = time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.MaxIPAttempts <= 0 {
+		cfg.MaxIPAttempts = 30
+	}
+	if cfg.AttemptWindow <= 0 {
+		cfg.AttemptWindow = 15 * time.Minute
+	}
+	if cfg.LockoutBase <= 0 {
+		cfg.LockoutBase = time.Minute
+	}
+	if cfg.LockoutMax < cfg.LockoutBase {
+		cfg.LockoutMax = 24 * time.Hour
 	}
+	return cfg
 }
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/NuEventTeam/events/internal/storage/keydb"
+	"github.com/gofiber/fiber/v2"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	scopeLogin = "login"
+	scopeOtp   = "otp"
+
+	// lockout levels are forgotten after a day without a new lockout
+	levelExpiry = 24 * time.Hour
+)
+
+type LockedError struct {
+	Wait time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("too many attempts, try again in %d seconds", seconds(e.Wait))
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
+
+// limiter counts failed attempts per phone and per ip in keydb and locks a
+// subject out once it reaches its limit.
+type limiter struct {
+	cache *keydb.Cache
+	cfg   config.OTP
+}
+
+type subject struct {
+	key   string
+	limit int64
+}
+
+func (l limiter) subjects(phone, ip string) []subject {
+	subjects := []subject{{key: "phone:" + phone, limit: l.cfg.MaxAttempts}}
+	if ip != "" {
+		subjects = append(subjects, subject{key: "ip:" + ip, limit: l.cfg.MaxIPAttempts})
+	}
+	return subjects
+}
+
+func key(scope, kind, subject string) string {
+	return fmt.Sprintf("auth:%s:%s:%s", scope, kind, subject)
+}
+
+// check returns a *LockedError when the phone or ip is locked out.
+func (l limiter) check(ctx context.Context, scope, phone, ip string) error {
+	for _, s := range l.subjects(phone, ip) {
+		wait, err := l.cache.TTL(ctx, key(scope, "lock", s.key))
+		if err != nil {
+			return err
+		}
+		if wait > 0 {
+			return &LockedError{Wait: wait}
+		}
+	}
+	return nil
+}
+
+// fail records a failed attempt. It returns a *LockedError when the attempt
+// locked the phone or ip out.
+func (l limiter) fail(ctx context.Context, scope, phone, ip string) error {
+	var locked *LockedError
+
+	for _, s := range l.subjects(phone, ip) {
+		attempts, err := l.cache.Incr(ctx, key(scope, "attempts", s.key), l.cfg.AttemptWindow)
+		if err != nil {
+			return err
+		}
+		if attempts < s.limit {
+			continue
+		}
+
+		level, err := l.cache.Incr(ctx, key(scope, "level", s.key), levelExpiry)
+		if err != nil {
+			return err
+		}
+
+		wait := l.cooldown(level)
+		err = l.cache.Set(ctx, key(scope, "lock", s.key), strconv.FormatInt(level, 10), wait)
+		if err != nil {
+			return err
+		}
+		err = l.cache.Del(ctx, key(scope, "attempts", s.key))
+		if err != nil {
+			return err
+		}
+
+		if locked == nil || wait > locked.Wait {
+			locked = &LockedError{Wait: wait}
+		}
+	}
+
+	if locked != nil {
+		return locked
+	}
+	return nil
+}
+
+// reset forgets the failed attempts of the phone after a success. The ip
+// keeps its counter, one good login must not unlock guessing other phones.
+func (l limiter) reset(ctx context.Context, scope, phone string) error {
+	for _, kind := range []string{"attempts", "level"} {
+		if err := l.cache.Del(ctx, key(scope, kind, "phone:"+phone)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l limiter) cooldown(level int64) time.Duration {
+	wait := l.cfg.LockoutBase
+	for i := int64(1); i < level && wait < l.cfg.LockoutMax; i++ {
+		wait *= 2
+	}
+	if wait > l.cfg.LockoutMax {
+		wait = l.cfg.LockoutMax
+	}
+	return wait
+}
+
+// tooManyAttempts answers with 429 and the seconds to wait when err is a
+// *LockedError, it returns false otherwise.
+func tooManyAttempts(ctx *fiber.Ctx, err error) (bool, error) {
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		return false, nil
+	}
+
+	retryAfter := seconds(locked.Wait)
+	ctx.Set(fiber.HeaderRetryAfter, strconv.FormatInt(retryAfter, 10))
+
+	return true, ctx.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"ok": false,
+		"data": fiber.Map{
+			"message":    locked.Error(),
+			"retryAfter": retryAfter,
+		},
+	})
+}
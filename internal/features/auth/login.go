@@ -2,16 +2,13 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/NuEventTeam/events/internal/models"
-	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/storage/repository"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/oklog/ulid/v2"
 	"golang.org/x/crypto/bcrypt"
-	"log"
-	"time"
 )
 
 var (
@@ -26,8 +23,9 @@ type AuthToken struct {
 }
 
 type LoginRequest struct {
-	Phone    string `json:"phone"`
-	Password string `json:"password"`
+	Phone      string `json:"phone"`
+	Password   string `json:"password"`
+	DeviceName string `json:"device_name"`
 }
 
 type LoginResponse struct {
@@ -44,35 +42,50 @@ func (a *Auth) LoginHandler() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, MsgCannotParseJSON, err)
 		}
 
+		err := a.limiter.check(ctx.Context(), scopeLogin, request.Phone, ctx.IP())
+		if ok, err := tooManyAttempts(ctx, err); ok {
+			return err
+		}
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
 		userID, err := a.CheckUserCredentials(ctx.Context(), &request.Phone, nil, request.Password)
+		if errors.Is(err, ErrInvalidCredentials) {
+			err = a.limiter.fail(ctx.Context(), scopeLogin, request.Phone, ctx.IP())
+			if ok, err := tooManyAttempts(ctx, err); ok {
+				return err
+			}
+			if err != nil {
+				return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+			}
+			return pkg.Error(ctx, fiber.StatusBadRequest, ErrInvalidCredentials.Error(), ErrInvalidCredentials)
+		}
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 
 		}
 
-		refreshToken := models.Token{
-			UserId:   &userID,
-			Token:    ulid.Make().String(),
-			Type:     TokenTypeRefresh,
-			Duration: 7 * 24 * time.Hour,
-		}
-		if val := ctx.Get("User-Agent", ""); val != "" {
-			refreshToken.UserAgent = &val
+		err = a.limiter.reset(ctx.Context(), scopeLogin, request.Phone)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
 		}
 
+		refreshToken := a.newSession(ctx, userID, request.DeviceName)
+
 		err = a.CreateToken(ctx.Context(), refreshToken)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 
 		}
 
-		accessToken, err := a.GetJWT(userID, refreshToken.UserAgent)
+		accessToken, err := a.GetJWT(userID, refreshToken.UserAgent, refreshToken.SessionId)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 
 		}
 
-		user, err := database.GetUser(ctx.Context(), a.db.GetDb(), database.GetUserArgss{UserID: &userID})
+		user, err := a.store.Users().GetProfile(ctx.Context(), userID)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 		}
@@ -93,63 +106,35 @@ func (a *Auth) LoginHandler() fiber.Handler {
 	}
 }
 
+// CreateToken stores the token. Refresh tokens belong to a session and live
+// next to the other sessions of the user, any other token replaces the
+// previous one of its type.
 func (a *Auth) CreateToken(ctx context.Context, token models.Token) error {
-	tx, err := a.db.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-
-	defer tx.Rollback(ctx)
-
-	err = database.DeleteToken(ctx, tx, token)
-	if err != nil {
-		return err
-	}
-	err = database.CreateToken(ctx, tx, token)
-	if err != nil {
-		return err
+	if token.Type == TokenTypeRefresh {
+		return a.store.Tokens().Create(ctx, token)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return err
-	}
+	return a.store.WithTx(ctx, func(tx repository.Store) error {
+		err := tx.Tokens().Delete(ctx, token)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		return tx.Tokens().Create(ctx, token)
+	})
 }
 
-func (a *Auth) GetJWT(userID int64, userAgent *string) (string, error) {
-	var (
-		key = []byte(a.jwt.Secret)
-	)
-	log.Println(userID)
-	expireTime := time.Now().Add(time.Hour * 24)
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	claims := token.Claims.(jwt.MapClaims)
-	claims["userId"] = userID
-	claims["exp"] = expireTime.Unix()
-	if userAgent != nil {
-		claims["userAgent"] = userAgent
-	}
-	tokenString, err := token.SignedString(key)
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
-
+func (a *Auth) GetJWT(userID int64, userAgent *string, sessionId *string) (string, error) {
+	return a.tokens.IssueAccess(userID, userAgent, sessionId)
 }
 
 func (a *Auth) CheckUserCredentials(ctx context.Context, phone *string, userID *int64, password string) (int64, error) {
-	user, err := a.db.GetUser(ctx, a.db.GetDb(), database.GetUserParams{
-		Phone:  phone,
-		UserID: userID,
-	})
+	user, err := a.store.Users().GetCredentials(ctx, phone, userID)
 	if err != nil {
 		return 0, err
 	}
 	if user == nil {
-		return 0, fmt.Errorf("something wring with password or phone")
+		return 0, ErrInvalidCredentials
 	}
 
 	ok := checkPasswordHash(password, user.Hash)
@@ -3,7 +3,6 @@ package auth
 import (
 	"context"
 	"github.com/NuEventTeam/events/internal/models"
-	"github.com/NuEventTeam/events/internal/storage/database"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,12 +11,18 @@ func (a *Auth) LogoutHandler() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 
 		userId := ctx.Locals("userId").(int64)
-		var agent *string
-		if ctx.Locals("userAgent").(string) != "" {
-			t := ctx.Locals("userAgent").(string)
-			agent = &t
+		token := models.Token{UserId: &userId, Type: TokenTypeRefresh}
+
+		if !ctx.QueryBool("all") {
+			if sessionId, _ := ctx.Locals("sessionId").(string); sessionId != "" {
+				token.SessionId = &sessionId
+			} else if agent, _ := ctx.Locals("userAgent").(string); agent != "" {
+				// access tokens issued before sessions only carry the user agent
+				token.UserAgent = &agent
+			}
 		}
-		err := a.Logout(ctx.Context(), models.Token{UserId: &userId, Type: TokenTypeRefresh, UserAgent: agent})
+
+		err := a.Logout(ctx.Context(), token)
 
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "logout failed", err)
@@ -27,6 +32,28 @@ func (a *Auth) LogoutHandler() fiber.Handler {
 	}
 }
 
+// Logout deletes the refresh tokens matching the token, every session of the
+// user when it has neither a session nor a user agent.
 func (a *Auth) Logout(ctx context.Context, token models.Token) error {
-	return database.DeleteToken(ctx, a.db.GetDb(), token)
+	sessions, err := a.store.Tokens().ListSessions(ctx, *token.UserId)
+	if err != nil {
+		return err
+	}
+
+	err = a.store.Tokens().Delete(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	var revoked []string
+	for _, s := range sessions {
+		switch {
+		case token.SessionId != nil && s.ID != *token.SessionId:
+		case token.UserAgent != nil && (s.UserAgent == nil || *s.UserAgent != *token.UserAgent):
+		default:
+			revoked = append(revoked, s.ID)
+		}
+	}
+	a.forgetSessions(ctx, revoked...)
+	return nil
 }
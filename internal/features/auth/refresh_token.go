@@ -3,20 +3,23 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/NuEventTeam/events/internal/models"
-	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/storage/repository"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5"
-	"log"
+	"github.com/oklog/ulid/v2"
 )
 
+var ErrTokenReused = fmt.Errorf("refresh token was already used")
+
 type RefreshTokenRequest struct {
 	Token string `json:"token"`
 }
 
 type RefreshTokenResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 func (a *Auth) RefreshTokenHandler() fiber.Handler {
@@ -26,34 +29,124 @@ func (a *Auth) RefreshTokenHandler() fiber.Handler {
 		if err := ctx.BodyParser(&request); err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, MsgCannotParseJSON)
 		}
-		token, err := a.VerifyToken(ctx.Context(), models.Token{Token: request.Token, Type: TokenTypeRefresh})
+
+		ip := ctx.IP()
+		token, err := a.RotateRefreshToken(ctx.Context(), request.Token, &ip)
 		if err != nil {
+			if errors.Is(err, ErrTokenReused) {
+				return pkg.Error(ctx, fiber.StatusUnauthorized, "token reused, session revoked", err)
+			}
 			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
 		}
 		if token == nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "token expired")
 		}
-		log.Println(*token.UserId)
-		accessToken, err := a.GetJWT(*token.UserId, token.UserAgent)
+
+		accessToken, err := a.GetJWT(*token.UserId, token.UserAgent, token.SessionId)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
 		}
 
-		return pkg.Success(ctx, fiber.Map{"token": accessToken})
+		return pkg.Success(ctx, RefreshTokenResponse{Token: accessToken, RefreshToken: token.Token})
+	}
+}
+
+// RotateRefreshToken replaces the refresh token with a new one of the same
+// session. Presenting a token that was already rotated means it leaked, so
+// the whole session is revoked and ErrTokenReused returned. A nil token means
+// the refresh token does not exist or expired.
+func (a *Auth) RotateRefreshToken(ctx context.Context, refreshToken string, ip *string) (*models.Token, error) {
+	var (
+		rotated *models.Token
+		reused  *models.Token
+	)
+
+	err := a.store.WithTx(ctx, func(tx repository.Store) error {
+		old, err := tx.Tokens().Get(ctx, models.Token{Token: refreshToken, Type: TokenTypeRefresh})
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		if old.RotatedAt != nil {
+			reused = &old
+			return nil
+		}
+
+		err = tx.Tokens().Rotate(ctx, old.Token)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				reused = &old
+				return nil
+			}
+			return err
+		}
+
+		next := old
+		next.Token = ulid.Make().String()
+		next.Duration = a.tokens.RefreshTTL()
+		next.RotatedAt = nil
+		if ip != nil {
+			next.IP = ip
+		}
+
+		err = tx.Tokens().Create(ctx, next)
+		if err != nil {
+			return err
+		}
+
+		rotated = &next
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reused != nil {
+		err = a.revokeFamily(ctx, *reused)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenReused
+	}
+
+	return rotated, nil
+}
+
+// revokeFamily deletes every refresh token of the token's session, tokens
+// issued before sessions existed are their own family.
+func (a *Auth) revokeFamily(ctx context.Context, token models.Token) error {
+	family := models.Token{UserId: token.UserId, Type: TokenTypeRefresh}
+	if token.SessionId != nil {
+		family.SessionId = token.SessionId
+	} else {
+		family.Token = token.Token
+	}
+
+	err := a.store.Tokens().Delete(ctx, family)
+	if err != nil {
+		return err
+	}
+
+	if token.SessionId != nil {
+		a.forgetSessions(ctx, *token.SessionId)
 	}
+	return nil
 }
 
 func (a *Auth) VerifyToken(ctx context.Context, token models.Token) (*models.Token, error) {
 
-	t, err := database.GetToken(ctx, a.db.GetDb(), token)
+	t, err := a.store.Tokens().Get(ctx, token)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
 	if token.Type != TokenTypeRefresh {
-		err = database.DeleteToken(ctx, a.db.GetDb(), t)
+		err = a.store.Tokens().Delete(ctx, t)
 		if err != nil {
 			return nil, err
 		}
@@ -6,8 +6,6 @@ import (
 	"github.com/NuEventTeam/events/internal/models"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
-	"github.com/oklog/ulid/v2"
-	"time"
 )
 
 var (
@@ -19,6 +17,7 @@ type RegisterRequest struct {
 	Password        string `json:"password"`
 	Token           string `json:"token"`
 	ConfirmPassword string `json:"confirm_password"`
+	DeviceName      string `json:"device_name"`
 }
 
 type RegisterResponse struct {
@@ -71,24 +70,14 @@ func (a *Auth) RegisterHandler() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 		}
 
-		refreshToken := models.Token{
-			UserId: &userID,
-			Token:  ulid.Make().String(),
-			Type:   TokenTypeRefresh,
-
-			Duration: 7 * 24 * time.Hour,
-		}
-
-		if val := ctx.Get("User-Agent", ""); val != "" {
-			refreshToken.UserAgent = &val
-		}
+		refreshToken := a.newSession(ctx, userID, request.DeviceName)
 
 		err = a.CreateToken(ctx.Context(), refreshToken)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 		}
 
-		accessToken, err := a.GetJWT(userID, refreshToken.UserAgent)
+		accessToken, err := a.GetJWT(userID, refreshToken.UserAgent, refreshToken.SessionId)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 		}
@@ -106,7 +95,7 @@ func (a *Auth) RegisterHandler() fiber.Handler {
 
 func (a *Auth) CreateUser(ctx context.Context, user models.User) (int64, error) {
 
-	exists, err := a.db.PhoneExists(ctx, a.db.GetDb(), user.Phone)
+	exists, err := a.store.Users().PhoneExists(ctx, user.Phone)
 	if err != nil {
 		return 0, err
 	}
@@ -121,7 +110,7 @@ func (a *Auth) CreateUser(ctx context.Context, user models.User) (int64, error)
 	}
 	user.Hash = hash
 
-	userID, err := a.db.CreateUser(ctx, a.db.GetDb(), user)
+	userID, err := a.store.Users().Create(ctx, user)
 	if err != nil {
 		return 0, err
 	}
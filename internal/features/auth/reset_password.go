@@ -3,7 +3,6 @@ package auth
 import (
 	"context"
 	"github.com/NuEventTeam/events/internal/models"
-	"github.com/NuEventTeam/events/internal/storage/database"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
 )
@@ -35,9 +34,7 @@ func (a *Auth) ResetPasswordHandler() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "token expired")
 		}
 
-		user, err := a.db.GetUser(ctx.Context(), a.db.GetDb(), database.GetUserParams{
-			UserID: token.UserId,
-		})
+		user, err := a.store.Users().GetCredentials(ctx.Context(), nil, token.UserId)
 		if user == nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "something went wrong", err)
 		}
@@ -46,7 +43,7 @@ func (a *Auth) ResetPasswordHandler() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "something went wrong", err)
 		}
 
-		err = database.DeleteToken(ctx.Context(), a.db.GetDb(), *token)
+		err = a.store.Tokens().Delete(ctx.Context(), *token)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "something went wrong", err)
 		}
@@ -60,5 +57,5 @@ func (a *Auth) UpdatePassword(ctx context.Context, password string, userID int64
 		return err
 	}
 
-	return a.db.UpdateUser(ctx, a.db.GetDb(), nil, &hash, userID)
+	return a.store.Users().UpdateCredentials(ctx, userID, nil, &hash)
 }
@@ -2,14 +2,13 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"github.com/NuEventTeam/events/internal/models"
-	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/storage/repository"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
-	"math/rand"
-	"strconv"
-	"time"
+	"math/big"
 )
 
 type SendOtpRequest struct {
@@ -18,7 +17,9 @@ type SendOtpRequest struct {
 }
 
 type SendOtpResponse struct {
-	Code string `json:"code"`
+	Code      string `json:"code,omitempty"`
+	ExpiresIn int64  `json:"expiresIn"`
+	ResendIn  int64  `json:"resendIn"`
 }
 
 func (a *Auth) SendOTPHandler() fiber.Handler {
@@ -33,7 +34,7 @@ func (a *Auth) SendOTPHandler() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, violation.Message, violation)
 		}
 
-		exist, err := a.db.PhoneExists(ctx.Context(), a.db.GetDb(), request.Phone)
+		exist, err := a.store.Users().PhoneExists(ctx.Context(), request.Phone)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
 		}
@@ -46,43 +47,84 @@ func (a *Auth) SendOTPHandler() fiber.Handler {
 
 		otp, err := a.SaveOtp(ctx.Context(), request.Phone, request.OtpType)
 		if err != nil {
-			return pkg.Error(ctx, fiber.StatusInternalServerError, err.Error(), err)
+			if ok, err := tooManyAttempts(ctx, err); ok {
+				return err
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
 		}
 
 		err = a.smsProvider.Send(ctx.Context(), request.Phone, otp.Code)
 		if err != nil {
+			// let the user retry right away, nothing was delivered
+			_ = a.cache.Del(ctx.Context(), resendKey(request.Phone, request.OtpType))
 			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
 		}
 
-		return pkg.Success(ctx, fiber.Map{"code": otp.Code})
+		response := SendOtpResponse{
+			ExpiresIn: seconds(a.otp.Expiry),
+			ResendIn:  seconds(a.otp.ResendCooldown),
+		}
+		if a.echoOtp {
+			response.Code = otp.Code
+		}
+
+		return pkg.Success(ctx, response)
 
 	}
 }
 
-func (a *Auth) SaveOtp(ctx context.Context, phone string, otpType int32) (models.Otp, error) {
+func resendKey(phone string, otpType int32) string {
+	return fmt.Sprintf("auth:otp:resend:%d:%s", otpType, phone)
+}
 
-	otp := models.Otp{
-		Phone:    phone,
-		Code:     generateOtp(),
-		OtpType:  otpType,
-		Duration: time.Minute,
+// SaveOtp replaces the code of the phone with a new one. It returns a
+// *LockedError while the previous code is in its resend cooldown.
+func (a *Auth) SaveOtp(ctx context.Context, phone string, otpType int32) (models.Otp, error) {
+	ok, err := a.cache.SetNX(ctx, resendKey(phone, otpType), 1, a.otp.ResendCooldown)
+	if err != nil {
+		return models.Otp{}, err
+	}
+	if !ok {
+		wait, err := a.cache.TTL(ctx, resendKey(phone, otpType))
+		if err != nil {
+			return models.Otp{}, err
+		}
+		return models.Otp{}, &LockedError{Wait: wait}
 	}
 
-	code, err := database.GetOtp(ctx, a.db.GetDb(), otp)
+	code, err := generateOtp(a.otp.Length)
 	if err != nil {
-		return otp, err
+		return models.Otp{}, err
 	}
-	if code != "" {
-		return otp, fmt.Errorf("code already sent")
+
+	otp := models.Otp{
+		Phone:    phone,
+		Code:     code,
+		OtpType:  otpType,
+		Duration: a.otp.Expiry,
 	}
 
-	err = database.CreateOtp(ctx, a.db.GetDb(), otp)
+	err = a.store.WithTx(ctx, func(tx repository.Store) error {
+		err := tx.Otps().Delete(ctx, otp)
+		if err != nil {
+			return err
+		}
+		return tx.Otps().Create(ctx, otp)
+	})
 	if err != nil {
 		return models.Otp{}, err
 	}
 	return otp, nil
 }
 
-func generateOtp() string {
-	return strconv.Itoa(rand.Intn(8999) + 1000)
+func generateOtp(length int) (string, error) {
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
 }
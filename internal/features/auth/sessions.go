@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/oklog/ulid/v2"
+	"log"
+	"strconv"
+	"time"
+)
+
+// sessionCacheTTL is how long a live session is remembered, and so how long a
+// revoked one keeps access when its cached answer could not be dropped.
+const sessionCacheTTL = time.Minute
+
+func sessionKey(sessionId string) string {
+	return fmt.Sprintf("session:%s", sessionId)
+}
+
+// newSession builds the refresh token of a new session for the device that
+// sent the request.
+func (a *Auth) newSession(ctx *fiber.Ctx, userId int64, deviceName string) models.Token {
+	sessionId := ulid.Make().String()
+
+	token := models.Token{
+		UserId:    &userId,
+		SessionId: &sessionId,
+		Token:     ulid.Make().String(),
+		Type:      TokenTypeRefresh,
+		Duration:  a.tokens.RefreshTTL(),
+	}
+
+	if val := ctx.Get("User-Agent", ""); val != "" {
+		token.UserAgent = &val
+	}
+	if deviceName == "" && token.UserAgent != nil {
+		deviceName = *token.UserAgent
+	}
+	if deviceName != "" {
+		token.DeviceName = &deviceName
+	}
+	if ip := ctx.IP(); ip != "" {
+		token.IP = &ip
+	}
+
+	return token
+}
+
+func (a *Auth) ListSessionsHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userId := ctx.Locals("userId").(int64)
+		current, _ := ctx.Locals("sessionId").(string)
+
+		sessions, err := a.store.Tokens().ListSessions(ctx.Context(), userId)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		for i := range sessions {
+			sessions[i].Current = sessions[i].ID == current
+		}
+
+		return pkg.Success(ctx, fiber.Map{"sessions": sessions})
+	}
+}
+
+func (a *Auth) RevokeSessionHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userId := ctx.Locals("userId").(int64)
+
+		err := a.RevokeSession(ctx.Context(), userId, ctx.Params("id"))
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return pkg.Error(ctx, fiber.StatusNotFound, "session not found", err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+func (a *Auth) RevokeSession(ctx context.Context, userId int64, sessionId string) error {
+	err := a.store.Tokens().DeleteSession(ctx, userId, sessionId)
+	if err != nil {
+		return err
+	}
+
+	a.forgetSessions(ctx, sessionId)
+	return nil
+}
+
+// SessionActive reports whether the session of an access token was not
+// revoked. Access tokens issued before sessions carry none and are valid
+// until they expire.
+func (a *Auth) SessionActive(ctx context.Context, userId int64, sessionId string) (bool, error) {
+	if sessionId == "" {
+		return true, nil
+	}
+
+	cached, err := a.cache.Get(ctx, sessionKey(sessionId))
+	if err == nil && cached == strconv.FormatInt(userId, 10) {
+		return true, nil
+	}
+
+	ok, err := a.store.Tokens().SessionExists(ctx, userId, sessionId)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if err := a.cache.Set(ctx, sessionKey(sessionId), userId, sessionCacheTTL); err != nil {
+		log.Println("could not cache session", sessionId, err)
+	}
+	return true, nil
+}
+
+// forgetSessions drops the cached answers of revoked sessions, so their
+// access tokens stop working right away.
+func (a *Auth) forgetSessions(ctx context.Context, sessionIds ...string) {
+	keys := make([]string, len(sessionIds))
+	for i, id := range sessionIds {
+		keys[i] = sessionKey(id)
+	}
+
+	if err := a.cache.Del(ctx, keys...); err != nil {
+		log.Println("could not forget sessions", sessionIds, err)
+	}
+}
@@ -2,8 +2,8 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
 	"github.com/NuEventTeam/events/internal/models"
-	"github.com/NuEventTeam/events/internal/storage/database"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
 	"github.com/oklog/ulid/v2"
@@ -37,15 +37,35 @@ func (a *Auth) VerifyOtpHandler() fiber.Handler {
 			OtpType: request.OtpType,
 		}
 
+		err := a.limiter.check(ctx.Context(), scopeOtp, request.Phone, ctx.IP())
+		if ok, err := tooManyAttempts(ctx, err); ok {
+			return err
+		}
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
 		ok, err := a.VerifyOtp(ctx.Context(), otp)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
 		}
 
 		if !ok {
+			err = a.limiter.fail(ctx.Context(), scopeOtp, request.Phone, ctx.IP())
+			if ok, err := tooManyAttempts(ctx, err); ok {
+				return err
+			}
+			if err != nil {
+				return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+			}
 			return pkg.Error(ctx, fiber.StatusBadRequest, "wrong code")
 		}
 
+		err = a.limiter.reset(ctx.Context(), scopeOtp, request.Phone)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
 		token := models.Token{
 			Token:    ulid.Make().String(),
 			Type:     OtpToTokenType[request.OtpType],
@@ -54,9 +74,7 @@ func (a *Auth) VerifyOtpHandler() fiber.Handler {
 		if request.OtpType == OtpTypeRegister {
 			token.Phone = &request.Phone
 		} else {
-			user, err := a.db.GetUser(ctx.Context(), a.db.GetDb(), database.GetUserParams{
-				Phone: &request.Phone,
-			})
+			user, err := a.store.Users().GetCredentials(ctx.Context(), &request.Phone, nil)
 			if err != nil {
 				return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
 			}
@@ -76,16 +94,16 @@ func (a *Auth) VerifyOtpHandler() fiber.Handler {
 }
 
 func (a *Auth) VerifyOtp(ctx context.Context, otp models.Otp) (bool, error) {
-	code, err := database.GetOtp(ctx, a.db.GetDb(), otp)
+	code, err := a.store.Otps().Get(ctx, otp)
 	if err != nil {
 		return false, err
 	}
 
-	if code != otp.Code {
+	if code == "" || subtle.ConstantTimeCompare([]byte(code), []byte(otp.Code)) != 1 {
 		return false, nil
 	}
 
-	err = database.DeleteOtp(ctx, a.db.GetDb(), otp)
+	err = a.store.Otps().Delete(ctx, otp)
 	if err != nil {
 		return false, err
 	}
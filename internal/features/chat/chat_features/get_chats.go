@@ -5,20 +5,20 @@ import (
 	"fmt"
 	"github.com/Masterminds/squirrel"
 	user_profile "github.com/NuEventTeam/events/internal/features/user/profile"
+	"github.com/NuEventTeam/events/internal/models"
 	"github.com/NuEventTeam/events/internal/storage/database"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
 	"log"
-	"time"
 )
 
 var qb = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
 type Chat struct {
-	EventID     int64     `json:"eventId"`
-	Title       string    `json:"title"`
-	Images      []string  `json:"images"`
-	LastMessage *Messages `json:"lastMessage"`
+	EventID     int64               `json:"eventId"`
+	Title       string              `json:"title"`
+	Images      []string            `json:"images"`
+	LastMessage *models.ChatMessage `json:"lastMessage"`
 }
 
 func GetChats(db *database.Database) fiber.Handler {
@@ -52,18 +52,7 @@ func GetChats(db *database.Database) fiber.Handler {
 	}
 }
 
-type Messages struct {
-	ID           int64     `json:"id"`
-	EventId      int64     `json:"eventId"`
-	UserId       int64     `json:"userId"`
-	Username     string    `json:"username"`
-	ProfileImage *string   `json:"profileImage"`
-	Message      string    `json:"message"`
-	CreatedAt    time.Time `json:"createdAt"`
-	IsMy         bool      `json:"isMy"`
-}
-
-func getLastMessages(ctx context.Context, db database.DBTX, userId int64) (map[int64]Messages, error) {
+func getLastMessages(ctx context.Context, db database.DBTX, userId int64) (map[int64]models.ChatMessage, error) {
 	query := `
 select  chat_members.event_id from chat_members
 		inner join chat_messages on chat_messages.event_id = event_followers.event_id
@@ -86,7 +75,7 @@ select  chat_members.event_id from chat_members
 	}
 	rows.Close()
 
-	q := qb.Select(`chat_messages.id, chat_messages.event_id,user_id,username,profile_image,chat_messages.created_at, messages`).
+	q := qb.Select(`chat_messages.id, chat_messages.event_id,user_id,username,profile_image,chat_messages.created_at, messages, chat_messages.kind`).
 		From("chat_messages").
 		InnerJoin("users on users.id = chat_messages.user_id").
 		Where(squirrel.Eq{"chat_messages.event_id": eventIds}).OrderBy("chat_messages desc")
@@ -96,18 +85,22 @@ select  chat_members.event_id from chat_members
 	}
 	log.Println(stmt)
 
-	messages := map[int64]Messages{}
+	messages := map[int64]models.ChatMessage{}
 	rows, err = db.Query(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var m Messages
-		err := rows.Scan(&m.ID, &m.EventId, &m.UserId, &m.Username, &m.ProfileImage, &m.CreatedAt, &m.Message)
+		var (
+			m    models.ChatMessage
+			kind int
+		)
+		err := rows.Scan(&m.ID, &m.EventId, &m.UserId, &m.Username, &m.ProfileImage, &m.CreatedAt, &m.Message, &kind)
 		if err != nil {
 			return nil, err
 		}
+		m.System = kind == pkg.ChatMessageKindSystem
 		if m.ProfileImage != nil {
 			profileImgUrl := fmt.Sprint(pkg.CDNBaseUrl, *m.ProfileImage)
 			m.ProfileImage = &profileImgUrl
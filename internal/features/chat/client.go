@@ -2,8 +2,7 @@ package chat
 
 import (
 	"context"
-	"github.com/NuEventTeam/events/internal/features/chat/chat_features"
-	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/storage/repository"
 	"github.com/bytedance/sonic"
 	"github.com/gorilla/websocket"
 	"log"
@@ -11,7 +10,7 @@ import (
 	"time"
 )
 
-var DB *database.Database
+var Store repository.Store
 
 const (
 	// Time allowed to write a message to the peer.
@@ -101,19 +100,23 @@ func (c *Client) readMessage(ctx context.Context) {
 			break
 		}
 
-		msg, err := chat_features.SaveMessage(DB.GetDb(), c.EventId, c.ClientId, string(payload))
+		msg, err := Store.Chat().SaveMessage(context.Background(), c.EventId, c.ClientId, string(payload))
 		if err != nil {
 			log.Println(err)
 			return
 		}
 
 		js, err := sonic.ConfigFastest.Marshal(msg)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
 
-		c.Manager.messageChan <- Message{
+		c.Manager.Publish(Message{
 			EventId: ctx.Value("eventId").(int64),
 			Payload: js,
 			From:    c.ClientId,
-		}
+		})
 	}
 }
 
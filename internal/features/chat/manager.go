@@ -1,19 +1,40 @@
 package chat
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/storage/keydb"
 	"log"
+	"strconv"
 	"sync"
+	"time"
 )
 
 var ChatManager *Manager
 
+const (
+	// presenceInterval is how often a node marks its users online, users not
+	// marked for presenceTTL are offline.
+	presenceInterval = 30 * time.Second
+	presenceTTL      = 3 * presenceInterval
+)
+
 type EventList map[int64]ClientList
 
+// Manager delivers the messages of event chats to the clients connected to
+// this node. Messages go through a keydb channel per event, so clients of
+// every replica see them.
 type Manager struct {
 	EventList   EventList
 	messageChan chan Message
+	cache       *keydb.Cache
+	sub         *keydb.Subscription
 	sync.RWMutex
+
+	// channels holds the events this node is subscribed to.
+	channels   map[int64]bool
+	channelsMu sync.Mutex
 }
 
 type Message struct {
@@ -23,50 +44,199 @@ type Message struct {
 	From    int64
 }
 
-func NewManager() *Manager {
+func NewManager(cache *keydb.Cache) *Manager {
 	return &Manager{
 		EventList:   make(EventList),
 		messageChan: make(chan Message, 1000),
+		cache:       cache,
+		sub:         cache.Subscribe(context.Background()),
+		channels:    make(map[int64]bool),
 	}
 }
 
+func eventChannel(eventId int64) string {
+	return fmt.Sprintf("chat:event:%d", eventId)
+}
+
+func presenceKey(eventId int64) string {
+	return fmt.Sprintf("chat:presence:%d", eventId)
+}
+
+// Unregister marks the user offline right away, when they are still connected
+// to another node that node marks them online again within presenceInterval.
 func (m *Manager) Unregister(client *Client) {
-	defer m.Unlock()
 	m.Lock()
-	if event, ok := m.EventList[client.EventId]; ok {
-		if client, ok := event[client.ClientId]; ok {
-			delete(m.EventList[client.EventId], client.ClientId)
-			close(client.SendMsgChan)
+	event, ok := m.EventList[client.EventId]
+	if !ok {
+		m.Unlock()
+		return
+	}
+	registered, ok := event[client.ClientId]
+	if ok {
+		delete(event, client.ClientId)
+		close(registered.SendMsgChan)
+	}
+	if len(event) == 0 {
+		delete(m.EventList, client.EventId)
+	}
+	m.Unlock()
+
+	if ok {
+		err := m.cache.ZRem(context.Background(), presenceKey(client.EventId), strconv.FormatInt(client.ClientId, 10))
+		if err != nil {
+			log.Println("could not mark user offline", client.ClientId, err)
 		}
 	}
+	m.syncChannel(client.EventId)
 }
 
 func (m *Manager) Register(client *Client) {
-	defer m.Unlock()
-	m.Lock()
 	if client == nil {
 		log.Println("nil client")
 		return
 	}
+
+	m.Lock()
 	if _, ok := m.EventList[client.EventId]; !ok {
 		m.EventList[client.EventId] = make(ClientList)
 	}
 	m.EventList[client.EventId][client.ClientId] = client
+	m.Unlock()
+
+	m.syncChannel(client.EventId)
+	err := m.cache.ZAdd(context.Background(), presenceKey(client.EventId), presenceTTL, float64(time.Now().Unix()), strconv.FormatInt(client.ClientId, 10))
+	if err != nil {
+		log.Println("could not mark user online", client.ClientId, err)
+	}
+}
+
+// syncChannel subscribes to the channel of the event while it has clients on
+// this node. It runs outside the lock of the clients, so concurrent calls are
+// serialized and settle on the latest state.
+func (m *Manager) syncChannel(eventId int64) {
+	m.channelsMu.Lock()
+	defer m.channelsMu.Unlock()
+
+	m.RLock()
+	_, active := m.EventList[eventId]
+	m.RUnlock()
+
+	if active == m.channels[eventId] {
+		return
+	}
+	if active {
+		if err := m.sub.Subscribe(context.Background(), eventChannel(eventId)); err != nil {
+			log.Println("could not join chat channel", eventId, err)
+			return
+		}
+		m.channels[eventId] = true
+		return
+	}
+	if err := m.sub.Unsubscribe(context.Background(), eventChannel(eventId)); err != nil {
+		log.Println("could not leave chat channel", eventId, err)
+		return
+	}
+	delete(m.channels, eventId)
+}
+
+// Publish sends the message to the clients of the event on every node, and
+// only to the local ones when keydb cannot be reached.
+func (m *Manager) Publish(message Message) {
+	js, err := json.Marshal(message)
+	if err == nil {
+		err = m.cache.Publish(context.Background(), eventChannel(message.EventId), js)
+	}
+	if err != nil {
+		log.Println("could not publish chat message", message.EventId, err)
+		m.messageChan <- message
+	}
+}
+
+// Online returns the users connected to the chat of the event on any node.
+func (m *Manager) Online(ctx context.Context, eventId int64) ([]int64, error) {
+	members, err := m.cache.ZRangeFrom(ctx, presenceKey(eventId), float64(time.Now().Add(-presenceTTL).Unix()))
+	if err != nil {
+		return nil, err
+	}
+
+	userIds := make([]int64, 0, len(members))
+	for _, member := range members {
+		userId, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		userIds = append(userIds, userId)
+	}
+	return userIds, nil
 }
 
 func (m *Manager) Run() {
+	ticker := time.NewTicker(presenceInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case message := <-m.messageChan:
-			for _, client := range m.EventList[message.EventId] {
-				if client.ClientId == message.From {
-					continue
-				}
-				client := client
-				go func() {
-					client.SendMsgChan <- message
-				}()
+			m.deliver(message)
+		case published, ok := <-m.sub.Messages():
+			if !ok {
+				return
+			}
+			var message Message
+			if err := json.Unmarshal([]byte(published.Payload), &message); err != nil {
+				log.Println("invalid chat message on", published.Channel, err)
+				continue
 			}
+			m.deliver(message)
+		case <-ticker.C:
+			m.markOnline()
+		}
+	}
+}
+
+func (m *Manager) deliver(message Message) {
+	m.RLock()
+	defer m.RUnlock()
+
+	for _, client := range m.EventList[message.EventId] {
+		if client.ClientId == message.From {
+			continue
+		}
+		client := client
+		go func() {
+			client.SendMsgChan <- message
+		}()
+	}
+}
+
+// markOnline keeps the users of this node online and drops the users of
+// nodes which stopped marking theirs.
+func (m *Manager) markOnline() {
+	online := map[int64][]string{}
+	m.RLock()
+	for eventId, clients := range m.EventList {
+		for userId := range clients {
+			online[eventId] = append(online[eventId], strconv.FormatInt(userId, 10))
+		}
+	}
+	m.RUnlock()
+
+	now := time.Now()
+	for eventId, userIds := range online {
+		ctx := context.Background()
+		if err := m.cache.ZAdd(ctx, presenceKey(eventId), presenceTTL, float64(now.Unix()), userIds...); err != nil {
+			log.Println("could not mark users online", eventId, err)
 		}
+		if err := m.cache.ZRemBelow(ctx, presenceKey(eventId), float64(now.Add(-presenceTTL).Unix())); err != nil {
+			log.Println("could not drop offline users", eventId, err)
+		}
+	}
+}
+
+// Broadcast delivers the payload to every client connected to the event chat.
+func Broadcast(eventId int64, payload []byte) {
+	if ChatManager == nil {
+		return
 	}
+	ChatManager.Publish(Message{EventId: eventId, Payload: payload})
 }
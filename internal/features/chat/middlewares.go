@@ -2,25 +2,37 @@ package chat
 
 import (
 	"context"
-	"github.com/NuEventTeam/events/pkg"
+	"github.com/NuEventTeam/events/internal/features/auth"
+	"github.com/NuEventTeam/events/internal/features/token"
 	"log"
 	"net/http"
 )
 
+var (
+	Tokens   *token.Service
+	Sessions *auth.Auth
+)
+
 func Authorize(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token := r.URL.Query().Get("token")
 		log.Println("TRYING TO CHATT")
-		userId, userAgent, err := pkg.ParseJWT(token, "my-32-character-ultra-secure-and-ultra-long-secret")
+		claims, err := Tokens.ParseAccess(token)
 		if err != nil {
 			w.WriteHeader(http.StatusUnauthorized)
 			w.Write([]byte("Malformed Token"))
 			log.Println(err)
 			return
 		}
+		if ok, err := Sessions.SessionActive(r.Context(), claims.UserID, claims.SessionID); !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Session Revoked"))
+			log.Println("session of the token is not active", claims.SessionID, err)
+			return
+		}
 
-		ctx := context.WithValue(r.Context(), "userId", userId)
-		ctx = context.WithValue(ctx, "userAgent", userAgent)
+		ctx := context.WithValue(r.Context(), "userId", claims.UserID)
+		ctx = context.WithValue(ctx, "userAgent", claims.UserAgent)
 		next.ServeHTTP(w, r.WithContext(ctx))
 
 	}
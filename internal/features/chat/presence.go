@@ -0,0 +1,31 @@
+package chat
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// PresenceHandler lists the users connected to the chat of the event on any
+// chat server.
+func PresenceHandler(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(mux.Vars(r)["eventId"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid event id"))
+		return
+	}
+
+	userIds, err := ChatManager.Online(r.Context(), eventID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("something went wrong"))
+		log.Println(err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]int64{"online": userIds})
+}
@@ -10,6 +10,7 @@ func getRouter() *mux.Router {
 	r := mux.NewRouter()
 
 	r.HandleFunc("/ws/{eventId}", Authorize(JoinChatHandler)).Methods("GET")
+	r.HandleFunc("/presence/{eventId}", Authorize(PresenceHandler)).Methods("GET")
 	r.HandleFunc("/test", func(writer http.ResponseWriter, request *http.Request) {
 		fmt.Fprintf(writer, "OK")
 		return
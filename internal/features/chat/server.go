@@ -2,16 +2,21 @@ package chat
 
 import (
 	"fmt"
-	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/features/auth"
+	"github.com/NuEventTeam/events/internal/features/token"
+	"github.com/NuEventTeam/events/internal/storage/keydb"
+	"github.com/NuEventTeam/events/internal/storage/repository"
 	"log"
 	"net/http"
 	"time"
 )
 
-func RunChatServer(port int, db *database.Database) error {
+func RunChatServer(port int, store repository.Store, tokens *token.Service, sessions *auth.Auth, cache *keydb.Cache) error {
 	log.Println("staring chat", port)
-	DB = db
-	ChatManager = NewManager()
+	Store = store
+	Tokens = tokens
+	Sessions = sessions
+	ChatManager = NewManager(cache)
 	go ChatManager.Run()
 	srv := &http.Server{
 		Handler:      getRouter(),
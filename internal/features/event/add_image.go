@@ -1,12 +1,15 @@
 package event
 
 import (
+	"errors"
+	"fmt"
 	"github.com/NuEventTeam/events/internal/features/assets"
 	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/database"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
 	"github.com/oklog/ulid/v2"
-	"log"
+	"mime/multipart"
 	"path"
 	"strconv"
 	"sync"
@@ -24,35 +27,21 @@ func (e Event) AddImage() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid eventID", err)
 		}
 
-		images := make([]assets.Image, len(form.File["images"]))
-		wg := sync.WaitGroup{}
-
-		for i, f := range form.File["images"] {
-			wg.Add(1)
-			f := f
-			go func(index int, wg *sync.WaitGroup) {
-				defer wg.Done()
-				file, err := f.Open()
-				if err != nil {
-					log.Println("cannot open the image file")
-					return
-				}
-
-				filename := ulid.Make().String() + path.Ext(f.Filename)
-
-				img, err := assets.NewImage(filename, file)
-
-				if err != nil {
-					log.Println("while uploading image")
-				} else {
-					images[index] = img
-				}
-			}(i, &wg)
+		existing, err := database.GetEventImages(ctx.Context(), e.db.GetDb(), eventId)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "could not get event images", err)
 		}
 
-		wg.Wait()
+		if verr := e.assets.ValidateImages(form.File["images"], e.assets.Limits().MaxEventImages-len(existing)); verr != nil {
+			return pkg.Error(ctx, verr.Status, verr.Violations, verr)
+		}
 
-		err = e.UpdateEvent(ctx.Context(), models.Event{ID: eventId, Images: images})
+		images, err := readImages(form.File["images"], assets.WithRenditions())
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "could not read images", err)
+		}
+
+		err = e.UpdateEvent(ctx.Context(), models.Event{ID: eventId, Images: images}, nil)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "could not add images", err)
 		}
@@ -60,3 +49,34 @@ func (e Event) AddImage() fiber.Handler {
 		return pkg.Success(ctx, fiber.StatusOK)
 	}
 }
+
+func readImages(files []*multipart.FileHeader, opts ...assets.OptFunc) ([]assets.Image, error) {
+	images := make([]assets.Image, len(files))
+	errs := make([]error, len(files))
+	wg := sync.WaitGroup{}
+
+	for i, f := range files {
+		wg.Add(1)
+		f := f
+		go func(index int, wg *sync.WaitGroup) {
+			defer wg.Done()
+			file, err := f.Open()
+			if err != nil {
+				errs[index] = fmt.Errorf("cannot open %s: %w", f.Filename, err)
+				return
+			}
+			defer file.Close()
+
+			filename := ulid.Make().String() + path.Ext(f.Filename)
+
+			images[index], errs[index] = assets.NewImage(filename, file, opts...)
+		}(i, &wg)
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
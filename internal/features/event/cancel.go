@@ -0,0 +1,136 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/features/chat"
+	"github.com/NuEventTeam/events/internal/features/event/ticket"
+	"github.com/NuEventTeam/events/internal/features/notification"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/bytedance/sonic"
+	"github.com/gofiber/fiber/v2"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxCancelReason = 500
+
+type CancelEventRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (e *Event) CancelEventHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request CancelEventRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		reason := strings.TrimSpace(request.Reason)
+		if reason == "" {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "reason is missing")
+		}
+		if utf8.RuneCountInString(reason) > maxCancelReason {
+			return pkg.Error(ctx, fiber.StatusBadRequest, fmt.Sprintf("reason is longer than %d characters", maxCancelReason))
+		}
+
+		userId := ctx.Locals("userId").(int64)
+
+		err = e.CancelEvent(ctx.Context(), int64(eventId), userId, reason)
+		if err != nil {
+			if errors.Is(err, ErrInvalidTransition) {
+				return pkg.Error(ctx, fiber.StatusConflict, "event cannot be canceled", err)
+			}
+			if errors.Is(err, ErrEventNotFound) {
+				return pkg.Error(ctx, fiber.StatusNotFound, err.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+// CancelEvent cancels the event, revokes its tickets, refunds its paid seats
+// and lets its chat and followers know. Offline verifiers learn of the revoked
+// tickets through the published revocations. Followers and the event itself
+// are kept, so the event stays in the history of its followers.
+func (e *Event) CancelEvent(ctx context.Context, eventId, userId int64, reason string) error {
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = changeStatus(ctx, tx, models.StatusChange{
+		EventID:   eventId,
+		To:        pkg.EventStatusCanceled,
+		ChangedBy: &userId,
+		Reason:    &reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg, err := database.CreateSystemChatMessage(ctx, tx, eventId, userId, "The event was canceled: "+reason)
+	if err != nil {
+		return err
+	}
+
+	event, err := database.GetEventByID(ctx, tx, eventId)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if err := ticket.RevokeEventTickets(ctx, e.cache, eventId); err != nil {
+		log.Println("could not revoke tickets", eventId, err)
+	}
+
+	if e.refunds != nil {
+		if err := e.refunds.RefundEvent(ctx, eventId); err != nil {
+			log.Println("could not refund orders", eventId, err)
+		}
+	}
+
+	if payload, err := sonic.ConfigFastest.Marshal(msg); err == nil {
+		chat.Broadcast(eventId, payload)
+	}
+
+	var title string
+	if event != nil && event.Title != nil {
+		title = *event.Title
+	}
+	go e.notifyCanceled(context.WithoutCancel(ctx), eventId, title, reason)
+
+	return nil
+}
+
+func (e *Event) notifyCanceled(ctx context.Context, eventId int64, title, reason string) {
+	tokens, err := database.GetFollowerDevices(ctx, e.db.GetDb(), eventId)
+	if err != nil {
+		log.Println("could not get follower devices", eventId, err)
+		return
+	}
+
+	err = notification.Notify(ctx, tokens, title+" was canceled", reason, map[string]string{
+		"type":    "event_canceled",
+		"eventId": strconv.FormatInt(eventId, 10),
+	})
+	if err != nil {
+		log.Println("could not notify followers", eventId, err)
+	}
+}
@@ -1,38 +1,19 @@
 package comments
 
 import (
-	"context"
-	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
-	"log"
-	"time"
 )
 
 type AddCommentRequest struct {
 	ParentId *int64 `json:"parentId"`
 	Text     string `json:"text"`
 	EventId  int64  `json:"eventId"`
-	AuthorId int64  `json:"-"`
 }
 
-type Comment struct {
-	CommentId int64     `json:"commentId"`
-	ParentId  *int64    `json:"parentId"`
-	Text      string    `json:"text"`
-	Author    Author    `json:"author"`
-	Children  []Comment `json:"children"`
-	CreatedAt time.Time `json:"createdAt"`
-}
-
-type Author struct {
-	ID            int64   `json:"id"`
-	Username      string  `json:"username"`
-	ProfileImage  *string `json:"profileImage"`
-	IsEventAuthor bool    `json:"isEventAuthor"`
-}
-
-func AddCommentHandler(db *database.Database) fiber.Handler {
+func AddCommentHandler(store repository.Store) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		authorId := ctx.Locals("userId").(int64)
 
@@ -40,56 +21,22 @@ func AddCommentHandler(db *database.Database) fiber.Handler {
 		if err := ctx.BodyParser(&request); err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "could not parse json", err)
 		}
-		request.AuthorId = authorId
-		comment, err := addComment(ctx.Context(), db.GetDb(), request)
+
+		author, err := store.Comments().GetAuthor(ctx.Context(), authorId)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "oops something went wrong", err)
 		}
 
-		author, err := getCommentAuthor(ctx.Context(), db.GetDb(), authorId)
+		comment, err := store.Comments().Add(ctx.Context(), models.Comment{
+			ParentId: request.ParentId,
+			EventId:  request.EventId,
+			Text:     request.Text,
+			Author:   author,
+		})
 		if err != nil {
-			log.Println("here")
 			return pkg.Error(ctx, fiber.StatusBadRequest, "oops something went wrong", err)
 		}
 
-		response := Comment{
-			CommentId: comment.CommentId,
-			ParentId:  request.ParentId,
-			Text:      request.Text,
-			Author:    author,
-			CreatedAt: comment.CreatedAt,
-		}
-
-		return pkg.Success(ctx, fiber.Map{"comment": response})
-	}
-}
-
-func getCommentAuthor(ctx context.Context, db database.DBTX, userId int64) (Author, error) {
-	query := `select id,username, profile_image from users where id = $1;`
-
-	var author Author
-	err := db.QueryRow(ctx, query, userId).Scan(&author.ID, &author.Username, &author.ProfileImage)
-	if err != nil {
-		return Author{}, err
-	}
-	if author.ProfileImage != nil {
-		*author.ProfileImage = pkg.CDNBaseUrl + *author.ProfileImage
-	}
-	return author, nil
-
-}
-
-func addComment(ctx context.Context, db database.DBTX, request AddCommentRequest) (Comment, error) {
-	query := `insert into comments (parent_id, text, author_id, event_id) values ($1, $2, $3, $4) returning id,created_at;`
-
-	args := []interface{}{request.ParentId, request.Text, request.AuthorId, request.EventId}
-
-	var c Comment
-
-	err := db.QueryRow(ctx, query, args...).Scan(&c.CommentId, &c.CreatedAt)
-	if err != nil {
-		return Comment{}, err
+		return pkg.Success(ctx, fiber.Map{"comment": comment})
 	}
-
-	return c, nil
 }
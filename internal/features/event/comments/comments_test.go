@@ -0,0 +1,109 @@
+package comments
+
+import (
+	"encoding/json"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository/memory"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type response struct {
+	Ok   bool `json:"ok"`
+	Data struct {
+		Comment  models.Comment   `json:"comment"`
+		Comments []models.Comment `json:"comments"`
+	} `json:"data"`
+}
+
+func newApp(store *memory.Store) *fiber.App {
+	app := fiber.New()
+	// stands in for MustAuth
+	app.Use(func(ctx *fiber.Ctx) error {
+		userId, _ := strconv.ParseInt(ctx.Get("X-User-Id"), 10, 64)
+		ctx.Locals("userId", userId)
+		return ctx.Next()
+	})
+	app.Post("/comment/add", AddCommentHandler(store))
+	app.Post("/comment/fetch", FetchCommentHandler(store))
+	return app
+}
+
+func call(t *testing.T, app *fiber.App, path string, userId int64, body any) (int, response) {
+	t.Helper()
+
+	js, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", path, strings.NewReader(string(js)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", strconv.FormatInt(userId, 10))
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, r
+}
+
+func TestAddAndFetchComments(t *testing.T) {
+	store := memory.New()
+	app := newApp(store)
+	authorId := store.AddUser(models.User{Username: "author"})
+	userId := store.AddUser(models.User{Username: "guest"})
+	eventId := store.AddEvent(models.Event{
+		Managers: []models.Manager{{
+			User: models.User{UserID: authorId},
+			Role: models.Role{Name: pkg.AuthorTitle, Permissions: []int64{pkg.PermissionUpdate}},
+		}},
+	})
+
+	status, first := call(t, app, "/comment/add", userId, AddCommentRequest{EventId: eventId, Text: "first"})
+	if status != fiber.StatusOK || first.Data.Comment.Author.Username != "guest" {
+		t.Fatalf("add comment = %d %+v, want a comment of guest", status, first.Data.Comment)
+	}
+	parentId := first.Data.Comment.CommentId
+	call(t, app, "/comment/add", authorId, AddCommentRequest{EventId: eventId, Text: "reply", ParentId: &parentId})
+	_, second := call(t, app, "/comment/add", userId, AddCommentRequest{EventId: eventId, Text: "second"})
+
+	status, fetched := call(t, app, "/comment/fetch", 0, FetchCommentRequest{EventId: eventId})
+	if status != fiber.StatusOK {
+		t.Fatalf("fetch comments = %d", status)
+	}
+	comments := fetched.Data.Comments
+	if len(comments) != 2 || comments[0].Text != "second" || comments[1].Text != "first" {
+		t.Fatalf("comments = %+v, want second and first", comments)
+	}
+	if len(comments[1].Children) != 1 || comments[1].Children[0].Text != "reply" || !comments[1].Children[0].Author.IsEventAuthor {
+		t.Errorf("children = %+v, want the reply of the event author", comments[1].Children)
+	}
+	if comments[1].Author.IsEventAuthor {
+		t.Error("comment of a guest is marked as the event author's")
+	}
+
+	_, fetched = call(t, app, "/comment/fetch", 0, FetchCommentRequest{EventId: eventId, LastParentId: second.Data.Comment.CommentId})
+	if len(fetched.Data.Comments) != 1 || fetched.Data.Comments[0].CommentId != parentId {
+		t.Errorf("comments after the last parent = %+v, want only the first", fetched.Data.Comments)
+	}
+}
+
+func TestAddCommentOfUnknownUser(t *testing.T) {
+	store := memory.New()
+	app := newApp(store)
+	eventId := store.AddEvent(models.Event{})
+
+	status, _ := call(t, app, "/comment/add", 42, AddCommentRequest{EventId: eventId, Text: "hi"})
+	if status != fiber.StatusBadRequest {
+		t.Errorf("add comment of an unknown user = %d, want %d", status, fiber.StatusBadRequest)
+	}
+}
@@ -2,6 +2,7 @@ package event
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/NuEventTeam/events/internal/features/assets"
 	"github.com/NuEventTeam/events/internal/features/chat/chat_features"
@@ -11,10 +12,7 @@ import (
 	"github.com/NuEventTeam/events/pkg/types"
 	"github.com/bytedance/sonic"
 	"github.com/gofiber/fiber/v2"
-	"github.com/oklog/ulid/v2"
 	"log"
-	"path"
-	"sync"
 )
 
 type CreateEventRequest struct {
@@ -35,6 +33,55 @@ type CreateEventRequest struct {
 	Categories       []int64        `json:"categories"`
 	RemoveCategories []int64        `json:"removeCategories"`
 	RemoveImages     []int64        `json:"removeImages"`
+	// Sessions replace the single location fields above for events held
+	// several times or at several venues.
+	Sessions        []SessionRequest `json:"sessions"`
+	RemoveLocations []int64          `json:"removeLocations"`
+	// Recurrence repeats the only session of a new event by its rule.
+	Recurrence *RecurrenceRequest `json:"recurrence"`
+}
+
+type SessionRequest struct {
+	ID        int64          `json:"locationId"`
+	Address   string         `json:"address"`
+	Longitude float64        `json:"lg"`
+	Latitude  float64        `json:"lt"`
+	Seats     *int64         `json:"seats"`
+	StartsAt  types.DateTime `json:"starts_at"`
+	EndsAt    types.DateTime `json:"end_at"`
+}
+
+// sessions returns the requested sessions, the single location fields make up
+// the only session of requests without sessions.
+func (c *CreateEventRequest) sessions() []SessionRequest {
+	if len(c.Sessions) > 0 {
+		return c.Sessions
+	}
+	return []SessionRequest{{
+		ID:        c.LocId,
+		Address:   c.Address,
+		Longitude: c.Longitude,
+		Latitude:  c.Latitude,
+		Seats:     c.Seats,
+		StartsAt:  c.StartsAt,
+		EndsAt:    c.EndsAt,
+	}}
+}
+
+func (s SessionRequest) location() models.Location {
+	seats := s.Seats
+	if seats == nil {
+		seats = new(int64)
+	}
+	return models.Location{
+		ID:        s.ID,
+		Address:   &s.Address,
+		Longitude: &s.Longitude,
+		Latitude:  &s.Latitude,
+		Seats:     seats,
+		StartsAt:  &s.StartsAt,
+		EndsAt:    &s.EndsAt,
+	}
 }
 
 func (c *CreateEventRequest) FromPayload(payload []byte) error {
@@ -73,43 +120,28 @@ func (e *Event) CreateEventHandler() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, violations)
 		}
 
-		images := make([]assets.Image, len(form.File["images"]))
-		wg := sync.WaitGroup{}
-
-		for i, f := range form.File["images"] {
-			wg.Add(1)
-			f := f
-			go func(index int, wg *sync.WaitGroup) {
-				defer wg.Done()
-				log.Println(f.Filename)
-				file, err := f.Open()
-				if err != nil {
-					log.Println("cannot open the image file")
-					return
-				}
-
-				filename := ulid.Make().String() + path.Ext(f.Filename)
+		maxImages := e.assets.Limits().MaxEventImages
+		if request.ID != 0 {
+			existing, err := database.GetEventImages(ctx.Context(), e.db.GetDb(), request.ID)
+			if err != nil {
+				return pkg.Error(ctx, fiber.StatusInternalServerError, "could not get event images", err)
+			}
+			maxImages -= len(existing) - len(request.RemoveImages)
+		}
 
-				img, err := assets.NewImage(filename, file, assets.WithWidthAndHeight(500, 500))
+		if verr := e.assets.ValidateImages(form.File["images"], maxImages); verr != nil {
+			return pkg.Error(ctx, verr.Status, verr.Violations, verr)
+		}
 
-				if err != nil {
-					log.Println("while uploading image")
-				} else {
-					images[index] = img
-				}
-			}(i, &wg)
+		images, err := readImages(form.File["images"], assets.WithRenditions())
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "could not read images", err)
 		}
 
-		wg.Wait()
-		log.Printf("%+v", images)
 		if request.MinAge == nil {
 			request.MinAge = new(int64)
 			*request.MinAge = 0
 		}
-		if request.Seats == nil {
-			request.Seats = new(int64)
-			*request.Seats = 0
-		}
 		event := models.Event{
 			Title:       &request.Title,
 			Description: &request.Description,
@@ -117,23 +149,25 @@ func (e *Event) CreateEventHandler() fiber.Handler {
 			MinAge:      request.MinAge,
 			Images:      images,
 			CategoryIds: request.Categories,
-			Locations: []models.Location{{
-				ID:        request.LocId,
-				Address:   &request.Address,
-				Longitude: &request.Longitude,
-				Latitude:  &request.Latitude,
-				Seats:     request.Seats,
-				StartsAt:  &request.StartsAt,
-				EndsAt:    &request.EndsAt,
-			}},
 			Managers: []models.Manager{{
 				User: models.User{UserID: userId},
 				Role: models.Role{
 					Name:        pkg.AuthorTitle,
-					Permissions: []int64{pkg.PermissionRead, pkg.PermissionVerify, pkg.PermissionUpdate}},
+					Permissions: []int64{pkg.PermissionRead, pkg.PermissionVerify, pkg.PermissionUpdate, pkg.PermissionManageTeam, pkg.PermissionViewPhones}},
 			}},
 			Attendees: nil,
 		}
+		if request.Recurrence != nil {
+			rec, err := request.Recurrence.recurrence(request.sessions()[0])
+			if err != nil {
+				return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+			}
+			event.Recurrence = &rec
+		} else {
+			for _, session := range request.sessions() {
+				event.Locations = append(event.Locations, session.location())
+			}
+		}
 		if request.Price != nil {
 			event.LocalPrice = new(int64)
 			*event.LocalPrice = int64(*request.Price * 100)
@@ -143,8 +177,16 @@ func (e *Event) CreateEventHandler() fiber.Handler {
 			event.RemoveCategories = request.RemoveCategories
 			log.Println(request.RemoveImages)
 			event.RemoveImagesIds = request.RemoveImages
-			err = e.UpdateEvent(ctx.Context(), event)
+			event.RemoveLocationIds = request.RemoveLocations
+			// the single location fields only update the location they name
+			if len(request.Sessions) == 0 && request.LocId == 0 {
+				event.Locations = nil
+			}
+			err = e.UpdateEvent(ctx.Context(), event, nil)
 			if err != nil {
+				if errors.Is(err, ErrLocationHasAttendees) || errors.Is(err, ErrLastLocation) {
+					return pkg.Error(ctx, fiber.StatusConflict, err.Error(), err)
+				}
 				if err != nil {
 					return pkg.Error(ctx, fiber.StatusInternalServerError, err.Error(), err)
 				}
@@ -152,6 +194,9 @@ func (e *Event) CreateEventHandler() fiber.Handler {
 			return pkg.Success(ctx, fiber.Map{"event_id": request.ID})
 		}
 		eventID, err := e.createEvent(ctx.Context(), event)
+		if errors.Is(err, ErrNoOccurrences) {
+			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+		}
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusInternalServerError, err.Error(), err)
 		}
@@ -187,13 +232,28 @@ func (e Event) createEvent(ctx context.Context, event models.Event) (int64, erro
 		return 0, err
 	}
 
-	e.assets.Upload(ctx, event.Images...)
-
 	err = database.AddEventLocations(ctx, tx, eventId, event.Locations...)
 	if err != nil {
 		return 0, err
 	}
 
+	if event.Recurrence != nil {
+		rec := event.Recurrence
+		rec.EventID = eventId
+		rec.ID, err = database.CreateRecurrence(ctx, tx, *rec)
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := e.materialize(ctx, tx, rec)
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			return 0, ErrNoOccurrences
+		}
+	}
+
 	for _, m := range event.Managers {
 		log.Println(m.User.UserID)
 		m.Role.EventID = eventId
@@ -220,7 +280,14 @@ func (e Event) createEvent(ctx context.Context, event models.Event) (int64, erro
 		}
 	}
 
+	err = e.assets.Upload(ctx, event.Images...)
+	if err != nil {
+		e.assets.Discard(ctx, event.Images...)
+		return 0, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
+		e.assets.Discard(ctx, event.Images...)
 		return 0, err
 	}
 
@@ -0,0 +1,124 @@
+package followers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/NuEventTeam/events/pkg/xlsx"
+	"github.com/gofiber/fiber/v2"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	exportTimeout    = 10 * time.Minute
+	exportTimeLayout = "2006-01-02 15:04:05"
+)
+
+// ExportFollowers streams every follower of the event as csv or xlsx. Phones
+// are only exported to managers allowed to see them.
+func ExportFollowers(store repository.Store) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userId := ctx.Locals("userId").(int64)
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		format := ctx.Query("format", "csv")
+		if format != "csv" && format != "xlsx" {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "format must be csv or xlsx")
+		}
+
+		phones, err := store.Events().CheckPermission(ctx.Context(), int64(eventId), userId, pkg.PermissionViewPhones)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		header := []string{"username", "firstname", "lastname", "registered_at", "checked_in", "checked_in_at"}
+		if phones {
+			header = []string{"username", "firstname", "lastname", "phone", "registered_at", "checked_in", "checked_in_at"}
+		}
+
+		filename := fmt.Sprintf("event-%d-attendees.%s", eventId, format)
+		ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+		if format == "xlsx" {
+			ctx.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		} else {
+			ctx.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		}
+
+		// the status is sent before the rows, errors past this point can
+		// only cut the file short
+		ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			c, cancel := context.WithTimeout(context.Background(), exportTimeout)
+			defer cancel()
+
+			if err := exportFollowers(c, store, int64(eventId), format, header, phones, w); err != nil {
+				log.Println("could not export followers of event", eventId, err)
+			}
+		})
+		return nil
+	}
+}
+
+func exportFollowers(ctx context.Context, store repository.Store, eventId int64, format string, header []string, phones bool, w *bufio.Writer) error {
+	var write func([]string) error
+	var done func() error
+	safe := func(s string) string { return s }
+	if format == "xlsx" {
+		x := xlsx.NewWriter(w, "Attendees")
+		write, done = x.Write, x.Close
+	} else {
+		c := csv.NewWriter(w)
+		write = c.Write
+		done = func() error {
+			c.Flush()
+			return c.Error()
+		}
+		safe = csvSafe
+	}
+
+	if err := write(header); err != nil {
+		return err
+	}
+
+	err := store.Followers().Each(ctx, eventId, func(a models.Attendee) error {
+		row := []string{safe(a.Username), safe(a.Firstname), "", a.RegisteredAt.Format(exportTimeLayout), "no", ""}
+		if a.Lastname != nil {
+			row[2] = safe(*a.Lastname)
+		}
+		if a.Attended {
+			row[4] = "yes"
+		}
+		if a.CheckedInAt != nil {
+			row[5] = a.CheckedInAt.Format(exportTimeLayout)
+		}
+		if phones {
+			row = append(row[:3], append([]string{a.Phone}, row[3:]...)...)
+		}
+		return write(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := done(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// csvSafe keeps spreadsheets from running names as formulas.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
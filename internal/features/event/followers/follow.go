@@ -4,18 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/NuEventTeam/events/internal/features/chat/chat_features"
-	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgerrcode"
-	"github.com/jackc/pgx/v5/pgconn"
-	"log"
+	"slices"
 	"strconv"
 	"time"
 )
 
-func FollowEvent(db *database.Database) fiber.Handler {
+func FollowEvent(store repository.Store) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		userId := ctx.Locals("userId").(int64)
 
@@ -24,91 +22,196 @@ func FollowEvent(db *database.Database) fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
 		}
 
-		err = checkEventStatus(ctx.Context(), db.GetDb(), eventId)
+		locationId := int64(ctx.QueryInt("locationId", 0))
+
+		event, location, err := CheckRegistration(ctx.Context(), store, eventId, locationId, userId)
 		if err != nil {
+			if errors.Is(err, pkg.ErrAgeRestricted) {
+				return pkg.Error(ctx, fiber.StatusForbidden, err.Error(), err)
+			}
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 		}
 
-		log.Println("here we are adding follower")
-		log.Println(userId, eventId)
-		err = addFollower(ctx.Context(), db, eventId, userId)
+		if event.Price > 0 {
+			return pkg.Error(ctx, fiber.StatusPaymentRequired, "event is paid, order a seat")
+		}
+
+		ticketTypes, err := store.Tickets().ListTypes(ctx.Context(), eventId)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "something went wrong", err)
+		}
+		if len(ticketTypes) > 0 {
+			return pkg.Error(ctx, fiber.StatusPaymentRequired, "event has ticket types, order a ticket")
+		}
+
+		position, err := addFollower(ctx.Context(), store, eventId, location.ID, userId)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "something went wrong", err)
 		}
 
-		return pkg.Success(ctx, nil)
+		if position > 0 {
+			return pkg.Success(ctx, fiber.Map{"locationId": location.ID, "waitlisted": true, "position": position})
+		}
+
+		return pkg.Success(ctx, fiber.Map{"locationId": location.ID})
 	}
 }
 
-func addFollower(ctx context.Context, db *database.Database, eventId, followerId int64) error {
-	tx, err := db.BeginTx(ctx)
+// addFollower registers the user to the session and takes one of its seats.
+// When the session is full the user joins its waitlist instead, and their
+// place in line is returned.
+func addFollower(ctx context.Context, store repository.Store, eventId, locationId, followerId int64) (int64, error) {
+	err := store.WithTx(ctx, func(tx repository.Store) error {
+		return follow(ctx, tx, eventId, locationId, followerId)
+	})
+	if !errors.Is(err, errSessionFull) {
+		return 0, err
+	}
+
+	err = store.Waitlist().Join(ctx, eventId, locationId, followerId)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	defer tx.Rollback(ctx)
 
-	err = database.AddEventFollower(ctx, tx, eventId, followerId)
+	_, position, err := store.Waitlist().Position(ctx, eventId, followerId)
+	return position, err
+}
+
+var errSessionFull = errors.New("session is full")
+
+// follow must run in a transaction, which is rolled back when the session is
+// full.
+func follow(ctx context.Context, tx repository.Store, eventId, locationId, followerId int64) error {
+	err := tx.Followers().Add(ctx, eventId, locationId, followerId)
 	if err != nil {
-		var e *pgconn.PgError
-		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
-			log.Println("trest", err)
+		if errors.Is(err, repository.ErrAlreadyExists) {
 			return nil
 		}
 		return err
 	}
-	log.Println("added f")
-	err = increaseFollowerCount(ctx, tx, eventId)
+
+	ok, err := tx.Events().ReserveSeat(ctx, locationId)
 	if err != nil {
 		return err
 	}
-	log.Println("increade count")
-	err = chat_features.AddChatMember(ctx, tx, eventId, followerId, pkg.ChatRoleUser)
+	if !ok {
+		return errSessionFull
+	}
+
+	return registered(ctx, tx, eventId, followerId)
+}
+
+// Register makes the user a follower of the session without taking a seat,
+// for users whose seat is already reserved. It must run in a transaction.
+func Register(ctx context.Context, tx repository.Store, eventId, locationId, userId int64) error {
+	err := tx.Followers().Add(ctx, eventId, locationId, userId)
 	if err != nil {
 		return err
 	}
-	if err := tx.Commit(ctx); err != nil {
+
+	return registered(ctx, tx, eventId, userId)
+}
+
+// registered updates the event for a follower who got a seat.
+func registered(ctx context.Context, tx repository.Store, eventId, followerId int64) error {
+	err := tx.Events().ChangeFollowerCount(ctx, eventId, 1)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Waitlist().Leave(ctx, eventId, followerId)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		return err
 	}
-	return nil
+
+	return tx.Chat().AddMember(ctx, eventId, followerId, pkg.ChatRoleUser)
+}
+
+// CheckRegistration returns the event and the session the user may register
+// to, see checkEventStatus.
+func CheckRegistration(ctx context.Context, store repository.Store, eventId, locationId, userId int64) (*models.Event, models.Location, error) {
+	user, err := store.Users().GetProfile(ctx, userId)
+	if err != nil {
+		return nil, models.Location{}, err
+	}
+
+	return checkEventStatus(ctx, store.Events(), eventId, locationId, user.BirthDate)
 }
 
-func increaseFollowerCount(ctx context.Context, db database.DBTX, userId int64) error {
-	query := `update events set follower_count = follower_count + 1 where id = $1`
+// registrationOpen reports whether the status takes registrations. Ongoing
+// events still take them for the sessions and occurrences to come.
+func registrationOpen(status int) bool {
+	return status == pkg.EventStatusCreated || status == pkg.EventStatusOngoing
+}
 
-	_, err := db.Exec(ctx, query, userId)
-	return err
+// SessionOpen reports whether users may still register to the session of the
+// event, which is until the session starts.
+func SessionOpen(event *models.Event, location models.Location, now time.Time) bool {
+	if event.Status == nil || !registrationOpen(*event.Status) {
+		return false
+	}
+	return location.StartsAt == nil || now.Before(time.Time(*location.StartsAt))
 }
 
-func checkEventStatus(ctx context.Context, db database.DBTX, eventId int64) error {
-	event, err := database.GetEventByID(ctx, db, eventId)
+// checkEventStatus returns the event and the session the user registers to.
+// Without a locationId the event must have a single upcoming session. The user
+// must be in the age range of the event.
+func checkEventStatus(ctx context.Context, events repository.Events, eventId, locationId int64, birthDate time.Time) (*models.Event, models.Location, error) {
+	event, err := events.GetByID(ctx, eventId)
 	if err != nil {
-		return err
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.Location{}, fmt.Errorf("event not exists")
+		}
+		return nil, models.Location{}, err
+	}
+
+	if !registrationOpen(*event.Status) {
+		return nil, models.Location{}, fmt.Errorf("event cannot be foullowed, status:%d", *event.Status)
 	}
 
-	if event == nil {
-		return fmt.Errorf("event not exists")
+	if err := pkg.CheckAge(birthDate, event.MinAge, event.MaxAge, time.Now()); err != nil {
+		return nil, models.Location{}, err
 	}
-	location, err := database.GetEventLocations(ctx, db, eventId)
+
+	locations, err := events.GetLocations(ctx, eventId)
 	if err != nil {
-		return err
+		return nil, models.Location{}, err
 	}
 
-	if location[0].StartsAt != nil {
-		if time.Now().After(time.Time(*location[0].StartsAt)) {
-			return fmt.Errorf("event regisration is closed")
+	if len(locations) == 0 {
+		return nil, models.Location{}, fmt.Errorf("event has no location")
+	}
+
+	var upcoming []models.Location
+	for _, l := range locations {
+		if SessionOpen(event, l, time.Now()) {
+			upcoming = append(upcoming, l)
 		}
 	}
 
-	if location[0].Seats != nil {
-		if *location[0].Seats == *location[0].AttendeesCount {
-			return fmt.Errorf("event is full")
+	var location *models.Location
+	if locationId == 0 {
+		if len(upcoming) > 1 {
+			return nil, models.Location{}, fmt.Errorf("event has several sessions, choose one")
+		}
+		if len(upcoming) == 1 {
+			location = &upcoming[0]
+		}
+	} else {
+		for i := range locations {
+			if locations[i].ID == locationId {
+				location = &locations[i]
+			}
+		}
+		if location == nil {
+			return nil, models.Location{}, fmt.Errorf("session not found")
 		}
 	}
 
-	if *event.Status != pkg.EventStatusCreated {
-		return fmt.Errorf("event cannot be foullowed, status:%d", *event.Status)
+	if location == nil || !slices.ContainsFunc(upcoming, func(l models.Location) bool { return l.ID == location.ID }) {
+		return nil, models.Location{}, fmt.Errorf("event regisration is closed")
 	}
 
-	return nil
+	return event, *location, nil
 
 }
@@ -0,0 +1,156 @@
+package followers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository/memory"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/NuEventTeam/events/pkg/types"
+	"github.com/gofiber/fiber/v2"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type response struct {
+	Ok   bool           `json:"ok"`
+	Data map[string]any `json:"data"`
+}
+
+func newApp(store *memory.Store) *fiber.App {
+	app := fiber.New()
+	// stands in for MustAuth
+	app.Use(func(ctx *fiber.Ctx) error {
+		userId, _ := strconv.ParseInt(ctx.Get("X-User-Id"), 10, 64)
+		ctx.Locals("userId", userId)
+		return ctx.Next()
+	})
+	app.Post("/follow/:eventId", FollowEvent(store))
+	app.Post("/unfollow/:eventId", Unfollow(store))
+	app.Post("/exist/:eventId", CheckIfFollowed(store))
+	app.Get("/waitlist/:eventId", WaitlistPosition(store))
+	return app
+}
+
+func call(t *testing.T, app *fiber.App, method, path string, userId int64) (int, response) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-User-Id", strconv.FormatInt(userId, 10))
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
+
+// addEvent seeds an event with a single session of the given seats starting
+// at startsAt.
+func addEvent(store *memory.Store, seats int64, startsAt time.Time) (int64, int64) {
+	eventId := store.AddEvent(models.Event{
+		Locations: []models.Location{{
+			StartsAt: new(types.DateTime).FromTime(&startsAt),
+			Seats:    &seats,
+		}},
+	})
+	event, _ := store.Events().GetByID(context.Background(), eventId)
+	return eventId, event.Locations[0].ID
+}
+
+func TestFollowUnfollow(t *testing.T) {
+	store := memory.New()
+	app := newApp(store)
+	eventId, locationId := addEvent(store, 10, time.Now().Add(24*time.Hour))
+	userId := store.AddUser(models.User{})
+	ctx := context.Background()
+
+	status, body := call(t, app, "POST", fmt.Sprint("/follow/", eventId), userId)
+	if status != fiber.StatusOK || body.Data["locationId"] != float64(locationId) {
+		t.Fatalf("follow = %d %v, want session %d", status, body, locationId)
+	}
+	status, body = call(t, app, "POST", fmt.Sprint("/exist/", eventId), userId)
+	if status != fiber.StatusOK || body.Data["follows"] != true {
+		t.Errorf("exist = %d %v, want the user to follow", status, body)
+	}
+
+	status, _ = call(t, app, "POST", fmt.Sprint("/follow/", eventId), userId)
+	if status != fiber.StatusOK {
+		t.Fatalf("second follow = %d", status)
+	}
+	event, _ := store.Events().GetByID(ctx, eventId)
+	if event.FollowerCount != 1 || *event.Locations[0].AttendeesCount != 1 {
+		t.Errorf("followers %d, attendees %d after following twice, want 1 and 1", event.FollowerCount, *event.Locations[0].AttendeesCount)
+	}
+
+	status, _ = call(t, app, "POST", fmt.Sprint("/unfollow/", eventId), userId)
+	if status != fiber.StatusOK {
+		t.Fatalf("unfollow = %d", status)
+	}
+	status, body = call(t, app, "POST", fmt.Sprint("/exist/", eventId), userId)
+	if status != fiber.StatusOK || body.Data["follows"] != false {
+		t.Errorf("exist after unfollowing = %d %v, want the user not to follow", status, body)
+	}
+	event, _ = store.Events().GetByID(ctx, eventId)
+	if event.FollowerCount != 0 || *event.Locations[0].AttendeesCount != 0 {
+		t.Errorf("followers %d, attendees %d after unfollowing, want 0 and 0", event.FollowerCount, *event.Locations[0].AttendeesCount)
+	}
+}
+
+func TestFollowUnknownEvent(t *testing.T) {
+	store := memory.New()
+	app := newApp(store)
+	userId := store.AddUser(models.User{})
+
+	status, _ := call(t, app, "POST", "/follow/42", userId)
+	if status != fiber.StatusBadRequest {
+		t.Errorf("follow of an unknown event = %d, want %d", status, fiber.StatusBadRequest)
+	}
+}
+
+func TestUnfollowPaid(t *testing.T) {
+	store := memory.New()
+	app := newApp(store)
+	eventId, locationId := addEvent(store, 10, time.Now().Add(24*time.Hour))
+	userId := store.AddUser(models.User{})
+	ctx := context.Background()
+
+	orderId, err := store.Orders().Create(ctx, models.Order{EventID: eventId, LocationID: locationId, UserID: userId, Status: pkg.OrderStatusPaid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Followers().Add(ctx, eventId, locationId, userId); err != nil {
+		t.Fatal(err)
+	}
+
+	status, _ := call(t, app, "POST", fmt.Sprint("/unfollow/", eventId), userId)
+	if status != fiber.StatusConflict {
+		t.Errorf("unfollow of a paid registration = %d, want %d", status, fiber.StatusConflict)
+	}
+	if ok, _ := store.Followers().Exists(ctx, eventId, userId); !ok {
+		t.Error("paid follower was removed")
+	}
+	if order, _ := store.Orders().Get(ctx, orderId); order.Status != pkg.OrderStatusPaid {
+		t.Errorf("order status = %d, want paid", order.Status)
+	}
+}
+
+func TestFollowStartedSession(t *testing.T) {
+	store := memory.New()
+	app := newApp(store)
+	eventId, _ := addEvent(store, 10, time.Now().Add(-time.Hour))
+	userId := store.AddUser(models.User{})
+
+	status, _ := call(t, app, "POST", fmt.Sprint("/follow/", eventId), userId)
+	if status != fiber.StatusBadRequest {
+		t.Errorf("follow of a started session = %d, want %d", status, fiber.StatusBadRequest)
+	}
+}
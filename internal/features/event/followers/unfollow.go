@@ -2,13 +2,20 @@ package followers
 
 import (
 	"context"
-	"github.com/NuEventTeam/events/internal/storage/database"
+	"errors"
+	"github.com/NuEventTeam/events/internal/features/notification"
+	"github.com/NuEventTeam/events/internal/storage/repository"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
+	"log"
 	"strconv"
 )
 
-func Unfollow(db *database.Database) fiber.Handler {
+// ErrPaidRegistration is returned for users leaving an event they paid for,
+// their seat is only given back with a refund of the order.
+var ErrPaidRegistration = errors.New("paid registrations cannot be canceled")
+
+func Unfollow(store repository.Store) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 
 		userId := ctx.Locals("userId").(int64)
@@ -18,42 +25,122 @@ func Unfollow(db *database.Database) fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
 		}
 
-		err = removeFollower(ctx.Context(), db, eventId, userId)
+		promoted, err := removeFollower(ctx.Context(), store, eventId, userId)
 		if err != nil {
+			if errors.Is(err, ErrPaidRegistration) {
+				return pkg.Error(ctx, fiber.StatusConflict, err.Error(), err)
+			}
 			return pkg.Error(ctx, fiber.StatusBadRequest, "something went wrong", err)
 		}
 
+		if len(promoted) > 0 {
+			go notifyPromoted(context.WithoutCancel(ctx.Context()), store, eventId, promoted)
+		}
+
 		return pkg.Success(ctx, nil)
 	}
 }
 
-func removeFollower(ctx context.Context, db *database.Database, eventId, followerId int64) error {
-	tx, err := db.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
+// removeFollower frees the seat of the user and hands it to the next user on
+// the waitlist of the session, who is returned. Users still on the waitlist
+// just leave it.
+func removeFollower(ctx context.Context, store repository.Store, eventId, followerId int64) ([]int64, error) {
+	var promoted []int64
 
-	defer tx.Rollback(ctx)
+	err := store.WithTx(ctx, func(tx repository.Store) error {
+		_, err := tx.Orders().GetByUser(ctx, eventId, followerId, pkg.OrderStatusPaid)
+		if err == nil {
+			return ErrPaidRegistration
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
 
-	err = database.RemoveEventFollower(ctx, tx, eventId, followerId)
-	if err != nil {
+		locationId, err := tx.Followers().Remove(ctx, eventId, followerId)
+		if errors.Is(err, repository.ErrNotFound) {
+			return tx.Waitlist().Leave(ctx, eventId, followerId)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Events().ChangeFollowerCount(ctx, eventId, -1)
+		if err != nil {
+			return err
+		}
+
+		if locationId == 0 {
+			return nil
+		}
+
+		err = tx.Events().ChangeAttendeesCount(ctx, locationId, -1)
+		if err != nil {
+			return err
+		}
+
+		promoted, err = promote(ctx, tx, eventId, locationId)
 		return err
+	})
+
+	return promoted, err
+}
+
+// promote registers waitlisted users to the session while it has free seats.
+func promote(ctx context.Context, tx repository.Store, eventId, locationId int64) ([]int64, error) {
+	var promoted []int64
+
+	for {
+		ok, err := tx.Events().ReserveSeat(ctx, locationId)
+		if err != nil || !ok {
+			return promoted, err
+		}
+
+		userId, err := tx.Waitlist().Next(ctx, locationId)
+		if errors.Is(err, repository.ErrNotFound) {
+			return promoted, tx.Events().ChangeAttendeesCount(ctx, locationId, -1)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.Followers().Add(ctx, eventId, locationId, userId)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			err = tx.Events().ChangeAttendeesCount(ctx, locationId, -1)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = registered(ctx, tx, eventId, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		promoted = append(promoted, userId)
 	}
+}
 
-	err = decreaseFollowerCount(ctx, tx, eventId)
+func notifyPromoted(ctx context.Context, store repository.Store, eventId int64, userIds []int64) {
+	tokens, err := store.Users().DeviceTokens(ctx, userIds...)
 	if err != nil {
-		return err
+		log.Println("could not get devices of promoted users", eventId, err)
+		return
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return err
+	var title string
+	if event, err := store.Events().GetByID(ctx, eventId); err == nil && event.Title != nil {
+		title = *event.Title
 	}
-	return nil
-}
-
-func decreaseFollowerCount(ctx context.Context, db database.DBTX, userId int64) error {
-	query := `update events set follower_count = follower_count - 1 where id = $1`
 
-	_, err := db.Exec(ctx, query, userId)
-	return err
+	err = notification.Notify(ctx, tokens, "A seat opened up", "You are now registered to "+title, map[string]string{
+		"type":    "waitlist_promoted",
+		"eventId": strconv.FormatInt(eventId, 10),
+	})
+	if err != nil {
+		log.Println("could not notify promoted users", eventId, err)
+	}
 }
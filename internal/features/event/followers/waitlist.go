@@ -0,0 +1,32 @@
+package followers
+
+import (
+	"errors"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+// WaitlistPosition returns the session the user waits for and their place in
+// its line.
+func WaitlistPosition(store repository.Store) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userId := ctx.Locals("userId").(int64)
+
+		eventId, err := strconv.ParseInt(ctx.Params("eventId"), 10, 64)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		locationId, position, err := store.Waitlist().Position(ctx.Context(), eventId, userId)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return pkg.Error(ctx, fiber.StatusNotFound, "user is not on the waitlist", err)
+			}
+			return pkg.Error(ctx, fiber.StatusBadRequest, "oops something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"locationId": locationId, "position": position})
+	}
+}
@@ -0,0 +1,78 @@
+package followers
+
+import (
+	"context"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository/memory"
+	"github.com/gofiber/fiber/v2"
+	"testing"
+	"time"
+)
+
+func TestFollowWaitlistUnfollow(t *testing.T) {
+	store := memory.New()
+	app := newApp(store)
+	eventId, locationId := addEvent(store, 1, time.Now().Add(24*time.Hour))
+	first, second := store.AddUser(models.User{}), store.AddUser(models.User{})
+	ctx := context.Background()
+
+	status, body := call(t, app, "POST", fmt.Sprint("/follow/", eventId), first)
+	if status != fiber.StatusOK || body.Data["waitlisted"] != nil {
+		t.Fatalf("follow = %d %v, want a seat", status, body)
+	}
+
+	status, body = call(t, app, "POST", fmt.Sprint("/follow/", eventId), second)
+	if status != fiber.StatusOK || body.Data["waitlisted"] != true || body.Data["position"] != 1.0 {
+		t.Fatalf("follow of a full session = %d %v, want position 1 on the waitlist", status, body)
+	}
+
+	status, body = call(t, app, "GET", fmt.Sprint("/waitlist/", eventId), second)
+	if status != fiber.StatusOK || body.Data["locationId"] != float64(locationId) || body.Data["position"] != 1.0 {
+		t.Errorf("waitlist = %d %v, want position 1 for session %d", status, body, locationId)
+	}
+
+	status, _ = call(t, app, "POST", fmt.Sprint("/unfollow/", eventId), first)
+	if status != fiber.StatusOK {
+		t.Fatalf("unfollow = %d", status)
+	}
+
+	if ok, _ := store.Followers().Exists(ctx, eventId, first); ok {
+		t.Error("user still follows after unfollowing")
+	}
+	if ok, _ := store.Followers().Exists(ctx, eventId, second); !ok {
+		t.Error("waitlisted user was not given the freed seat")
+	}
+	status, _ = call(t, app, "GET", fmt.Sprint("/waitlist/", eventId), second)
+	if status != fiber.StatusNotFound {
+		t.Errorf("waitlist of the promoted user = %d, want %d", status, fiber.StatusNotFound)
+	}
+
+	event, _ := store.Events().GetByID(ctx, eventId)
+	if event.FollowerCount != 1 || *event.Locations[0].AttendeesCount != 1 {
+		t.Errorf("followers %d, attendees %d, want 1 and 1", event.FollowerCount, *event.Locations[0].AttendeesCount)
+	}
+}
+
+func TestUnfollowWaitlisted(t *testing.T) {
+	store := memory.New()
+	app := newApp(store)
+	eventId, _ := addEvent(store, 1, time.Now().Add(24*time.Hour))
+	first, second := store.AddUser(models.User{}), store.AddUser(models.User{})
+
+	call(t, app, "POST", fmt.Sprint("/follow/", eventId), first)
+	call(t, app, "POST", fmt.Sprint("/follow/", eventId), second)
+
+	status, _ := call(t, app, "POST", fmt.Sprint("/unfollow/", eventId), second)
+	if status != fiber.StatusOK {
+		t.Fatalf("unfollow = %d", status)
+	}
+
+	status, _ = call(t, app, "GET", fmt.Sprint("/waitlist/", eventId), second)
+	if status != fiber.StatusNotFound {
+		t.Errorf("waitlist after leaving it = %d, want %d", status, fiber.StatusNotFound)
+	}
+	if ok, _ := store.Followers().Exists(context.Background(), eventId, first); !ok {
+		t.Error("follower lost their seat")
+	}
+}
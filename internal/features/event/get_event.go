@@ -72,6 +72,11 @@ func (e *Event) getEventByID(ctx context.Context, eventId int64) (*models.Event,
 		return nil, err
 	}
 
+	recurrence, err := database.GetEventRecurrence(ctx, e.db.GetDb(), eventId)
+	if err != nil {
+		return nil, err
+	}
+
 	images, err := database.GetEventImages(ctx, e.db.GetDb(), eventId)
 	if err != nil {
 		return nil, err
@@ -82,10 +87,17 @@ func (e *Event) getEventByID(ctx context.Context, eventId int64) (*models.Event,
 		return nil, err
 	}
 
+	ticketTypes, err := database.GetTicketTypes(ctx, e.db.GetDb(), eventId)
+	if err != nil {
+		return nil, err
+	}
+
 	event.Categories = categories
 	event.Locations = locations
+	event.Recurrence = recurrence
 	event.Images = images
 	event.Managers = managers
+	event.TicketTypes = ticketTypes
 
 	return event, nil
 }
@@ -219,6 +231,7 @@ func (e *Event) getEventAllEvents(ctx context.Context, db database.DBTX, lastId
 		if err != nil {
 			return nil, err
 		}
+		i.Renditions = assets.RenditionURLs(i.Url)
 		i.Url = pkg.CDNBaseUrl + i.Url
 		val := events[i.EventID]
 		val.Images = append(val.Images, i)
@@ -1,10 +1,13 @@
 package event
 
 import (
+	"context"
 	"errors"
 	"github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/config"
 	"github.com/NuEventTeam/events/internal/features/assets"
 	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/storage/keydb"
 )
 
 var qb = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
@@ -13,14 +16,25 @@ var (
 	ErrNoPermission = errors.New("user has no permission")
 )
 
+// Refunder gives the money of the paid seats of a canceled event back.
+type Refunder interface {
+	RefundEvent(ctx context.Context, eventId int64) error
+}
+
 type Event struct {
-	db     *database.Database
-	assets *assets.Assets
+	db      *database.Database
+	assets  *assets.Assets
+	cache   *keydb.Cache
+	refunds Refunder
+	cfg     config.Event
 }
 
-func NewEventSvc(db *database.Database, assets *assets.Assets) *Event {
+func NewEventSvc(db *database.Database, assets *assets.Assets, cache *keydb.Cache, refunds Refunder, cfg config.Event) *Event {
 	return &Event{
-		db:     db,
-		assets: assets,
+		db:      db,
+		assets:  assets,
+		cache:   cache,
+		refunds: refunds,
+		cfg:     cfg,
 	}
 }
@@ -0,0 +1,265 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"github.com/NuEventTeam/events/internal/features/chat/chat_features"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrAlreadyInvited     = errors.New("user already has a pending invitation")
+	ErrInvitationNotFound = errors.New("invitation not found or expired")
+)
+
+type InviteManagerRequest struct {
+	Username string `json:"username"`
+	RoleId   int64  `json:"roleId"`
+}
+
+func (e *Event) InviteManagerHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request InviteManagerRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		if request.Username == "" {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "username is missing")
+		}
+
+		userId := ctx.Locals("userId").(int64)
+
+		invitation, err := e.InviteManager(ctx.Context(), int64(eventId), userId, request.Username, request.RoleId)
+		if err != nil {
+			return managerError(ctx, err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"invitation": invitation})
+	}
+}
+
+func (e *Event) ListInvitationsHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		id := int64(eventId)
+		invitations, err := database.GetPendingInvitations(ctx.Context(), e.db.GetDb(), database.GetInvitationsArgs{EventID: &id})
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"invitations": invitations})
+	}
+}
+
+// RevokeInvitationHandler lets the inviter take back a pending invitation.
+func (e *Event) RevokeInvitationHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		invitationId, err := ctx.ParamsInt("invitationId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid invitation id", err)
+		}
+
+		userId := ctx.Locals("userId").(int64)
+		id := int64(eventId)
+
+		inv, err := database.CloseInvitation(ctx.Context(), e.db.GetDb(), database.CloseInvitationArgs{
+			ID:        int64(invitationId),
+			Status:    pkg.InvitationStatusRevoked,
+			InvitedBy: &userId,
+			EventID:   &id,
+		})
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+		if inv == nil {
+			return pkg.Error(ctx, fiber.StatusNotFound, ErrInvitationNotFound.Error(), ErrInvitationNotFound)
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+func (e *Event) AcceptInvitationHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		invitationId, err := ctx.ParamsInt("invitationId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid invitation id", err)
+		}
+
+		userId := ctx.Locals("userId").(int64)
+
+		manager, err := e.AcceptInvitation(ctx.Context(), int64(invitationId), userId)
+		if err != nil {
+			return managerError(ctx, err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"manager": manager})
+	}
+}
+
+func (e *Event) DeclineInvitationHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		invitationId, err := ctx.ParamsInt("invitationId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid invitation id", err)
+		}
+
+		userId := ctx.Locals("userId").(int64)
+
+		inv, err := database.CloseInvitation(ctx.Context(), e.db.GetDb(), database.CloseInvitationArgs{
+			ID:     int64(invitationId),
+			Status: pkg.InvitationStatusDeclined,
+			UserID: &userId,
+		})
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+		if inv == nil {
+			return pkg.Error(ctx, fiber.StatusNotFound, ErrInvitationNotFound.Error(), ErrInvitationNotFound)
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+func (e *Event) InviteManager(ctx context.Context, eventId, inviterId int64, username string, roleId int64) (models.ManagerInvitation, error) {
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return models.ManagerInvitation{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	user, err := database.GetUser(ctx, tx, database.GetUserArgss{Username: &username})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ManagerInvitation{}, ErrUserNotFound
+		}
+		return models.ManagerInvitation{}, err
+	}
+
+	role, err := assignableRole(ctx, tx, eventId, roleId, inviterId)
+	if err != nil {
+		return models.ManagerInvitation{}, err
+	}
+
+	exists, err := database.IsEventManager(ctx, tx, eventId, user.UserID)
+	if err != nil {
+		return models.ManagerInvitation{}, err
+	}
+	if exists {
+		return models.ManagerInvitation{}, ErrAlreadyManager
+	}
+
+	exists, err = database.HasPendingInvitation(ctx, tx, eventId, user.UserID)
+	if err != nil {
+		return models.ManagerInvitation{}, err
+	}
+	if exists {
+		return models.ManagerInvitation{}, ErrAlreadyInvited
+	}
+
+	invitation, err := database.CreateManagerInvitation(ctx, tx, models.ManagerInvitation{
+		EventID:   eventId,
+		UserID:    user.UserID,
+		Username:  user.Username,
+		InvitedBy: inviterId,
+		Role:      *role,
+		ExpiresAt: time.Now().Add(e.cfg.InvitationExpiry),
+	})
+	if err != nil {
+		return models.ManagerInvitation{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.ManagerInvitation{}, err
+	}
+
+	return invitation, nil
+}
+
+// AcceptInvitation makes the invitee a manager with the invited role and an
+// admin of the event chat.
+func (e *Event) AcceptInvitation(ctx context.Context, invitationId, userId int64) (models.Manager, error) {
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return models.Manager{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	inv, err := database.CloseInvitation(ctx, tx, database.CloseInvitationArgs{
+		ID:     invitationId,
+		Status: pkg.InvitationStatusAccepted,
+		UserID: &userId,
+	})
+	if err != nil {
+		return models.Manager{}, err
+	}
+	if inv == nil {
+		return models.Manager{}, ErrInvitationNotFound
+	}
+
+	exists, err := database.IsEventManager(ctx, tx, inv.EventID, userId)
+	if err != nil {
+		return models.Manager{}, err
+	}
+	if exists {
+		return models.Manager{}, ErrAlreadyManager
+	}
+
+	role, err := database.GetRole(ctx, tx, inv.EventID, inv.Role.ID)
+	if err != nil {
+		return models.Manager{}, err
+	}
+	if role == nil {
+		return models.Manager{}, ErrRoleNotFound
+	}
+
+	manager := models.Manager{
+		EventId: inv.EventID,
+		User:    models.User{UserID: userId},
+		Role:    *role,
+	}
+
+	err = database.AddEventManager(ctx, tx, inv.EventID, manager)
+	if err != nil {
+		return models.Manager{}, err
+	}
+
+	err = chat_features.AddChatMember(ctx, tx, inv.EventID, userId, pkg.ChatRoleAdmin)
+	if err != nil {
+		return models.Manager{}, err
+	}
+
+	// followers are already chat members with the user role
+	err = database.UpdateChatMemberRole(ctx, tx, inv.EventID, userId, pkg.ChatRoleAdmin)
+	if err != nil {
+		return models.Manager{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Manager{}, err
+	}
+
+	return manager, nil
+}
@@ -0,0 +1,66 @@
+package like
+
+import (
+	"context"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository/memory"
+	"github.com/gofiber/fiber/v2"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func newApp(store *memory.Store) *fiber.App {
+	app := fiber.New()
+	// stands in for MustAuth
+	app.Use(func(ctx *fiber.Ctx) error {
+		userId, _ := strconv.ParseInt(ctx.Get("X-User-Id"), 10, 64)
+		ctx.Locals("userId", userId)
+		return ctx.Next()
+	})
+	app.Post("/like/:eventId", LikeEvent(store))
+	return app
+}
+
+func like(t *testing.T, app *fiber.App, path string, userId int64) int {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", path, nil)
+	req.Header.Set("X-User-Id", strconv.FormatInt(userId, 10))
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestLikeEvent(t *testing.T) {
+	store := memory.New()
+	app := newApp(store)
+	eventId := store.AddEvent(models.Event{})
+	userId, otherId := store.AddUser(models.User{}), store.AddUser(models.User{})
+	ctx := context.Background()
+
+	if status := like(t, app, fmt.Sprint("/like/", eventId), userId); status != fiber.StatusOK {
+		t.Fatalf("like = %d", status)
+	}
+	if liked, _ := store.Events().IsLiked(ctx, eventId, userId); !liked {
+		t.Error("event is not liked after liking it")
+	}
+	if liked, _ := store.Events().IsLiked(ctx, eventId, otherId); liked {
+		t.Error("event is liked by another user")
+	}
+
+	if status := like(t, app, fmt.Sprint("/like/", eventId), userId); status != fiber.StatusOK {
+		t.Fatalf("second like = %d", status)
+	}
+	if liked, _ := store.Events().IsLiked(ctx, eventId, userId); liked {
+		t.Error("second like did not take the like back")
+	}
+
+	if status := like(t, app, "/like/abc", userId); status != fiber.StatusBadRequest {
+		t.Errorf("like of an invalid event id = %d, want %d", status, fiber.StatusBadRequest)
+	}
+}
@@ -0,0 +1,198 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	ErrManagerNotFound = errors.New("user is not a manager of the event")
+	ErrAlreadyManager  = errors.New("user is already a manager of the event")
+	ErrManageSelf      = errors.New("cannot change your own role")
+)
+
+type ManagerRequest struct {
+	RoleId int64 `json:"roleId"`
+}
+
+func (e *Event) ListManagersHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		managers, err := database.GetEventManagers(ctx.Context(), e.db.GetDb(), int64(eventId))
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"managers": managers})
+	}
+}
+
+func (e *Event) UpdateManagerHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request ManagerRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		managerId, err := ctx.ParamsInt("userId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid user id", err)
+		}
+
+		if int64(managerId) == ctx.Locals("userId").(int64) {
+			return pkg.Error(ctx, fiber.StatusBadRequest, ErrManageSelf.Error(), ErrManageSelf)
+		}
+
+		err = e.ChangeManagerRole(ctx.Context(), int64(eventId), int64(managerId), request.RoleId, ctx.Locals("userId").(int64))
+		if err != nil {
+			return managerError(ctx, err)
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+func (e *Event) RemoveManagerHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		managerId, err := ctx.ParamsInt("userId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid user id", err)
+		}
+
+		if int64(managerId) == ctx.Locals("userId").(int64) {
+			return pkg.Error(ctx, fiber.StatusBadRequest, ErrManageSelf.Error(), ErrManageSelf)
+		}
+
+		err = e.RemoveManager(ctx.Context(), int64(eventId), int64(managerId))
+		if err != nil {
+			return managerError(ctx, err)
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+func managerError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrManagerNotFound),
+		errors.Is(err, ErrInvitationNotFound):
+		return pkg.Error(ctx, fiber.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrAlreadyManager), errors.Is(err, ErrAlreadyInvited), errors.Is(err, ErrAuthorRole):
+		return pkg.Error(ctx, fiber.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrPermissionNotHeld):
+		return pkg.Error(ctx, fiber.StatusForbidden, err.Error(), err)
+	}
+	return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+}
+
+// assignableRole returns the role when it belongs to the event and is not the
+// author role, which only the creator of the event holds. The granter must
+// hold every permission of the role.
+func assignableRole(ctx context.Context, db database.DBTX, eventId, roleId, granterId int64) (*models.Role, error) {
+	role, err := database.GetRole(ctx, db, eventId, roleId)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	if role.Name == pkg.AuthorTitle {
+		return nil, ErrAuthorRole
+	}
+	if err := checkGrantable(ctx, db, eventId, granterId, role.Permissions); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (e *Event) ChangeManagerRole(ctx context.Context, eventId, userId, roleId, granterId int64) error {
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := e.checkNotAuthor(ctx, tx, eventId, userId); err != nil {
+		return err
+	}
+
+	_, err = assignableRole(ctx, tx, eventId, roleId, granterId)
+	if err != nil {
+		return err
+	}
+
+	ok, err := database.UpdateManager(ctx, tx, eventId, userId, roleId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrManagerNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (e *Event) RemoveManager(ctx context.Context, eventId, userId int64) error {
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	exists, err := database.IsEventManager(ctx, tx, eventId, userId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrManagerNotFound
+	}
+
+	if err := e.checkNotAuthor(ctx, tx, eventId, userId); err != nil {
+		return err
+	}
+
+	err = database.RemoveManagers(ctx, tx, eventId, userId)
+	if err != nil {
+		return err
+	}
+
+	err = database.UpdateChatMemberRole(ctx, tx, eventId, userId, pkg.ChatRoleUser)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// checkNotAuthor keeps the author of the event from being demoted or removed
+// by co-managers.
+func (e *Event) checkNotAuthor(ctx context.Context, db database.DBTX, eventId, userId int64) error {
+	managers, err := database.GetEventManagers(ctx, db, eventId)
+	if err != nil {
+		return err
+	}
+	for _, m := range managers {
+		if m.User.UserID == userId && m.Role.Name == pkg.AuthorTitle {
+			return ErrAuthorRole
+		}
+	}
+	return nil
+}
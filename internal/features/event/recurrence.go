@@ -0,0 +1,336 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/NuEventTeam/events/pkg/rrule"
+	"github.com/NuEventTeam/events/pkg/types"
+	"github.com/gofiber/fiber/v2"
+	"log"
+	"slices"
+	"time"
+)
+
+const (
+	ScopeThis   = "this"
+	ScopeFuture = "future"
+)
+
+var (
+	ErrOccurrenceNotFound = errors.New("occurrence not found")
+	ErrNotRecurring       = errors.New("session is not an occurrence of a recurring event")
+	ErrNoOccurrences      = errors.New("recurrence has no occurrences")
+	ErrRecurrenceDay      = errors.New("future occurrences of a rule with days cannot move to another day")
+	ErrInvalidSchedule    = errors.New("occurrence must end after it starts")
+)
+
+type RecurrenceRequest struct {
+	Rule       string           `json:"rule"`
+	Exceptions []types.DateTime `json:"exceptions"`
+}
+
+// recurrence returns the template for the occurrences of the session.
+func (r RecurrenceRequest) recurrence(session SessionRequest) (models.Recurrence, error) {
+	rule, err := rrule.Parse(r.Rule)
+	if err != nil {
+		return models.Recurrence{}, err
+	}
+
+	rec := models.Recurrence{
+		Rule:      rule.String(),
+		StartsAt:  time.Time(session.StartsAt),
+		EndsAt:    time.Time(session.EndsAt),
+		Address:   &session.Address,
+		Longitude: &session.Longitude,
+		Latitude:  &session.Latitude,
+		Seats:     session.Seats,
+	}
+	if rec.Seats == nil {
+		rec.Seats = new(int64)
+	}
+	for _, e := range r.Exceptions {
+		rec.Exceptions = append(rec.Exceptions, time.Time(e))
+	}
+	rec.MaterializedUntil = rec.StartsAt
+
+	return rec, nil
+}
+
+type OccurrenceRequest struct {
+	Scope     string          `json:"scope"`
+	Address   *string         `json:"address"`
+	Longitude *float64        `json:"longitude"`
+	Latitude  *float64        `json:"latitude"`
+	Seats     *int64          `json:"seats"`
+	StartsAt  *types.DateTime `json:"startsAt"`
+	EndsAt    *types.DateTime `json:"endsAt"`
+}
+
+// EditOccurrenceHandler edits one occurrence of a recurring event, or with the
+// future scope the occurrence and every later one.
+func (e *Event) EditOccurrenceHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request OccurrenceRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		locationId, err := ctx.ParamsInt("locationId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid location id", err)
+		}
+
+		if request.Scope != ScopeThis && request.Scope != ScopeFuture {
+			return pkg.Error(ctx, fiber.StatusBadRequest, fmt.Sprintf("scope must be %s or %s", ScopeThis, ScopeFuture))
+		}
+		if request.Seats != nil && *request.Seats < 0 {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "seats cannot be negative")
+		}
+
+		err = e.EditOccurrence(ctx.Context(), int64(eventId), int64(locationId), request)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrOccurrenceNotFound):
+				return pkg.Error(ctx, fiber.StatusNotFound, err.Error(), err)
+			case errors.Is(err, ErrNotRecurring), errors.Is(err, ErrRecurrenceDay), errors.Is(err, ErrInvalidSchedule):
+				return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+func (e *Event) EditOccurrence(ctx context.Context, eventId, locationId int64, request OccurrenceRequest) error {
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	locations, err := database.GetEventLocations(ctx, tx, eventId)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(locations, func(l models.Location) bool { return l.ID == locationId })
+	if i < 0 {
+		return ErrOccurrenceNotFound
+	}
+	occurrence := locations[i]
+	if occurrence.RecurrenceID == nil || occurrence.OccurrenceAt == nil {
+		return ErrNotRecurring
+	}
+
+	startsAt := time.Time(*occurrence.StartsAt)
+	if request.StartsAt != nil {
+		startsAt = time.Time(*request.StartsAt)
+	}
+	endsAt := time.Time(*occurrence.EndsAt)
+	if request.EndsAt != nil {
+		endsAt = time.Time(*request.EndsAt)
+	} else {
+		endsAt = endsAt.Add(startsAt.Sub(time.Time(*occurrence.StartsAt)))
+	}
+	if !endsAt.After(startsAt) {
+		return ErrInvalidSchedule
+	}
+
+	change := models.Location{
+		Address:   request.Address,
+		Longitude: request.Longitude,
+		Latitude:  request.Latitude,
+		Seats:     request.Seats,
+		StartsAt:  request.StartsAt,
+		EndsAt:    request.EndsAt,
+	}
+
+	if request.Scope == ScopeThis {
+		if request.StartsAt != nil && request.EndsAt == nil {
+			change.EndsAt = change.EndsAt.FromTime(&endsAt)
+		}
+
+		err = database.UpdateLocation(ctx, tx, eventId, locationId, change)
+		if err != nil {
+			return err
+		}
+
+		err = database.DetachOccurrence(ctx, tx, eventId, locationId)
+		if err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	}
+
+	rec, err := database.LockRecurrence(ctx, tx, *occurrence.RecurrenceID)
+	if err != nil {
+		return err
+	}
+	if rec == nil {
+		return ErrNotRecurring
+	}
+
+	shift := startsAt.Sub(time.Time(*occurrence.StartsAt))
+	from := *occurrence.OccurrenceAt
+	err = moveFuture(rec, from, shift, endsAt.Sub(startsAt), request)
+	if err != nil {
+		return err
+	}
+
+	err = database.UpdateFutureOccurrences(ctx, tx, rec.ID, from, shift, endsAt.Sub(startsAt), change)
+	if err != nil {
+		return err
+	}
+
+	err = database.UpdateRecurrence(ctx, tx, *rec)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// moveFuture moves the template of the recurrence along with the occurrences
+// from the given one on, so later occurrences are created the same way.
+func moveFuture(rec *models.Recurrence, from time.Time, shift, length time.Duration, request OccurrenceRequest) error {
+	rule, err := rrule.Parse(rec.Rule)
+	if err != nil {
+		return err
+	}
+	if len(rule.ByDay) > 0 && from.Add(shift).Weekday() != from.Weekday() {
+		return ErrRecurrenceDay
+	}
+
+	rec.StartsAt = rec.StartsAt.Add(shift)
+	rec.EndsAt = rec.StartsAt.Add(length)
+	rec.MaterializedUntil = rec.MaterializedUntil.Add(shift)
+	for i, ex := range rec.Exceptions {
+		if !ex.Before(from) {
+			rec.Exceptions[i] = ex.Add(shift)
+		}
+	}
+	if request.Address != nil {
+		rec.Address = request.Address
+	}
+	if request.Longitude != nil {
+		rec.Longitude = request.Longitude
+	}
+	if request.Latitude != nil {
+		rec.Latitude = request.Latitude
+	}
+	if request.Seats != nil {
+		rec.Seats = request.Seats
+	}
+	return nil
+}
+
+// horizon is how far occurrences of the recurrence are created, events
+// starting later than the horizon still get their first occurrences.
+func (e *Event) horizon(rec models.Recurrence, now time.Time) time.Time {
+	start := now
+	if rec.StartsAt.After(start) {
+		start = rec.StartsAt
+	}
+	return start.Add(e.cfg.RecurrenceHorizon)
+}
+
+// materialize creates the occurrences of the recurrence up to the horizon,
+// skipping its exceptions.
+func (e *Event) materialize(ctx context.Context, db database.DBTX, rec *models.Recurrence) (int, error) {
+	horizon := e.horizon(*rec, time.Now())
+	if !rec.MaterializedUntil.Before(horizon) {
+		return 0, nil
+	}
+
+	starts, err := occurrenceStarts(*rec, horizon)
+	if err != nil {
+		return 0, err
+	}
+
+	err = database.AddOccurrences(ctx, db, *rec, starts...)
+	if err != nil {
+		return 0, err
+	}
+
+	rec.MaterializedUntil = horizon
+	return len(starts), database.UpdateRecurrence(ctx, db, *rec)
+}
+
+// occurrenceStarts returns the starts of the occurrences not created yet up to
+// the horizon, without the exceptions.
+func occurrenceStarts(rec models.Recurrence, horizon time.Time) ([]time.Time, error) {
+	rule, err := rrule.Parse(rec.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	var starts []time.Time
+	for _, t := range rule.Between(rec.StartsAt, rec.MaterializedUntil, horizon) {
+		if !slices.ContainsFunc(rec.Exceptions, t.Equal) {
+			starts = append(starts, t)
+		}
+	}
+	return starts, nil
+}
+
+// RunRecurrenceScheduler keeps the occurrences of recurring events created up
+// to the horizon, until ctx is done.
+func (e *Event) RunRecurrenceScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		e.materializeDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Event) materializeDue(ctx context.Context) {
+	ids, err := database.GetDueRecurrences(ctx, e.db.GetDb(), time.Now().Add(e.cfg.RecurrenceHorizon))
+	if err != nil {
+		log.Println("could not get due recurrences", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := e.materializeRecurrence(ctx, id); err != nil {
+			log.Println("could not materialize recurrence", id, err)
+		}
+	}
+}
+
+func (e *Event) materializeRecurrence(ctx context.Context, recurrenceId int64) error {
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// the lock keeps other instances from creating the same occurrences
+	rec, err := database.LockRecurrence(ctx, tx, recurrenceId)
+	if err != nil || rec == nil {
+		return err
+	}
+
+	if _, err := e.materialize(ctx, tx, rec); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,150 @@
+package event
+
+import (
+	"errors"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/NuEventTeam/events/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func at(m time.Month, d, h int) time.Time {
+	return time.Date(2024, m, d, h, 0, 0, 0, time.UTC)
+}
+
+func TestMaterializeWindows(t *testing.T) {
+	e := &Event{cfg: config.Event{RecurrenceHorizon: 7 * 24 * time.Hour}}
+	rec := models.Recurrence{
+		Rule:              "FREQ=DAILY;COUNT=10",
+		StartsAt:          at(1, 1, 10),
+		EndsAt:            at(1, 1, 12),
+		Exceptions:        []time.Time{at(1, 3, 10)},
+		MaterializedUntil: at(1, 1, 10),
+	}
+
+	// the scheduler runs daily, every run continues where the last one stopped
+	var got []time.Time
+	for now := at(1, 1, 0); now.Before(at(1, 20, 0)); now = now.Add(24 * time.Hour) {
+		horizon := e.horizon(rec, now)
+		if !rec.MaterializedUntil.Before(horizon) {
+			continue
+		}
+
+		starts, err := occurrenceStarts(rec, horizon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, starts...)
+		rec.MaterializedUntil = horizon
+	}
+
+	var want []time.Time
+	for d := 1; d <= 10; d++ {
+		if d != 3 {
+			want = append(want, at(1, d, 10))
+		}
+	}
+	if !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Errorf("occurrences = %v, want %v", got, want)
+	}
+}
+
+func TestHorizonOfLaterEvent(t *testing.T) {
+	e := &Event{cfg: config.Event{RecurrenceHorizon: 24 * time.Hour}}
+	rec := models.Recurrence{StartsAt: at(3, 1, 10)}
+
+	if got := e.horizon(rec, at(1, 1, 0)); !got.Equal(at(3, 2, 10)) {
+		t.Errorf("horizon = %v, want %v", got, at(3, 2, 10))
+	}
+}
+
+func TestMoveFuture(t *testing.T) {
+	address := "new place"
+	seats := int64(40)
+
+	tests := []struct {
+		name    string
+		rule    string
+		from    time.Time
+		shift   time.Duration
+		length  time.Duration
+		request OccurrenceRequest
+		want    models.Recurrence
+		err     error
+	}{
+		{
+			name:   "later the same day",
+			rule:   "FREQ=WEEKLY;BYDAY=MO,WE",
+			from:   at(1, 10, 10),
+			shift:  2 * time.Hour,
+			length: 3 * time.Hour,
+			want: models.Recurrence{
+				StartsAt:          at(1, 1, 12),
+				EndsAt:            at(1, 1, 15),
+				Exceptions:        []time.Time{at(1, 8, 10), at(1, 15, 12)},
+				MaterializedUntil: at(1, 31, 12),
+			},
+		},
+		{
+			name:   "another day without days in the rule",
+			rule:   "FREQ=DAILY",
+			from:   at(1, 10, 10),
+			shift:  24 * time.Hour,
+			length: 2 * time.Hour,
+			request: OccurrenceRequest{
+				Scope:   ScopeFuture,
+				Address: &address,
+				Seats:   &seats,
+			},
+			want: models.Recurrence{
+				StartsAt:          at(1, 2, 10),
+				EndsAt:            at(1, 2, 12),
+				Address:           &address,
+				Seats:             &seats,
+				Exceptions:        []time.Time{at(1, 8, 10), at(1, 16, 10)},
+				MaterializedUntil: at(2, 1, 10),
+			},
+		},
+		{
+			name:  "another day with days in the rule",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE",
+			from:  at(1, 10, 10),
+			shift: 24 * time.Hour,
+			err:   ErrRecurrenceDay,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := models.Recurrence{
+				Rule:              tt.rule,
+				StartsAt:          at(1, 1, 10),
+				EndsAt:            at(1, 1, 12),
+				Exceptions:        []time.Time{at(1, 8, 10), at(1, 15, 10)},
+				MaterializedUntil: at(1, 31, 10),
+			}
+
+			err := moveFuture(&rec, tt.from, tt.shift, tt.length, tt.request)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("moveFuture() error = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			if !rec.StartsAt.Equal(tt.want.StartsAt) || !rec.EndsAt.Equal(tt.want.EndsAt) {
+				t.Errorf("template = %v-%v, want %v-%v", rec.StartsAt, rec.EndsAt, tt.want.StartsAt, tt.want.EndsAt)
+			}
+			if !rec.MaterializedUntil.Equal(tt.want.MaterializedUntil) {
+				t.Errorf("materialized until = %v, want %v", rec.MaterializedUntil, tt.want.MaterializedUntil)
+			}
+			if !slices.EqualFunc(rec.Exceptions, tt.want.Exceptions, time.Time.Equal) {
+				t.Errorf("exceptions = %v, want %v", rec.Exceptions, tt.want.Exceptions)
+			}
+			if rec.Address != tt.want.Address || rec.Seats != tt.want.Seats {
+				t.Errorf("overrides = %v, %v, want %v, %v", rec.Address, rec.Seats, tt.want.Address, tt.want.Seats)
+			}
+		})
+	}
+}
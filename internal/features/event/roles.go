@@ -0,0 +1,269 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("role with this name already exists")
+	ErrAuthorRole        = errors.New("author role cannot be changed")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrPermissionNotHeld = errors.New("cannot grant permissions you do not hold")
+)
+
+var permissions = []int64{pkg.PermissionRead, pkg.PermissionUpdate, pkg.PermissionVerify, pkg.PermissionManageTeam, pkg.PermissionViewPhones}
+
+type RoleRequest struct {
+	Name        *string `json:"name"`
+	Permissions []int64 `json:"permissions"`
+}
+
+func (r RoleRequest) validate() error {
+	if r.Name != nil && strings.TrimSpace(*r.Name) == "" {
+		return errors.New("role name is empty")
+	}
+	for _, p := range r.Permissions {
+		if !slices.Contains(permissions, p) {
+			return ErrUnknownPermission
+		}
+	}
+	return nil
+}
+
+func (e *Event) ListRolesHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		roles, err := database.GetEventRoles(ctx.Context(), e.db.GetDb(), int64(eventId))
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"roles": roles})
+	}
+}
+
+func (e *Event) CreateRoleHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request RoleRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		if request.Name == nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "role name is missing")
+		}
+		if err := request.validate(); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+		}
+
+		role, err := e.CreateRole(ctx.Context(), models.Role{
+			EventID:     int64(eventId),
+			Name:        strings.TrimSpace(*request.Name),
+			Permissions: request.Permissions,
+		}, ctx.Locals("userId").(int64))
+		if err != nil {
+			if errors.Is(err, ErrRoleExists) || errors.Is(err, ErrAuthorRole) {
+				return pkg.Error(ctx, fiber.StatusConflict, err.Error(), err)
+			}
+			if errors.Is(err, ErrPermissionNotHeld) {
+				return pkg.Error(ctx, fiber.StatusForbidden, err.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"role": role})
+	}
+}
+
+func (e *Event) UpdateRoleHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request RoleRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		roleId, err := ctx.ParamsInt("roleId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid role id", err)
+		}
+
+		if err := request.validate(); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+		}
+
+		role, err := e.UpdateRole(ctx.Context(), int64(eventId), int64(roleId), ctx.Locals("userId").(int64), request)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrRoleNotFound):
+				return pkg.Error(ctx, fiber.StatusNotFound, err.Error(), err)
+			case errors.Is(err, ErrRoleExists), errors.Is(err, ErrAuthorRole):
+				return pkg.Error(ctx, fiber.StatusConflict, err.Error(), err)
+			case errors.Is(err, ErrPermissionNotHeld):
+				return pkg.Error(ctx, fiber.StatusForbidden, err.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"role": role})
+	}
+}
+
+// CreateRole creates the role with permissions the granter holds.
+func (e *Event) CreateRole(ctx context.Context, role models.Role, granterId int64) (models.Role, error) {
+	if strings.EqualFold(role.Name, pkg.AuthorTitle) {
+		return models.Role{}, ErrAuthorRole
+	}
+
+	slices.Sort(role.Permissions)
+	role.Permissions = slices.Compact(role.Permissions)
+
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return models.Role{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = checkGrantable(ctx, tx, role.EventID, granterId, role.Permissions)
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	role.ID, err = database.CreateRole(ctx, tx, role)
+	if err != nil {
+		return models.Role{}, roleErr(err)
+	}
+
+	err = database.AddRolePermissions(ctx, tx, role)
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Role{}, err
+	}
+
+	return role, nil
+}
+
+// UpdateRole renames the role and replaces its permissions with the requested
+// ones, nil permissions keep the current ones. The granter can only add
+// permissions they hold.
+func (e *Event) UpdateRole(ctx context.Context, eventId, roleId, granterId int64, request RoleRequest) (models.Role, error) {
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return models.Role{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	role, err := database.GetRole(ctx, tx, eventId, roleId)
+	if err != nil {
+		return models.Role{}, err
+	}
+	if role == nil {
+		return models.Role{}, ErrRoleNotFound
+	}
+	if role.Name == pkg.AuthorTitle {
+		return models.Role{}, ErrAuthorRole
+	}
+
+	if request.Name != nil {
+		role.Name = strings.TrimSpace(*request.Name)
+		if strings.EqualFold(role.Name, pkg.AuthorTitle) {
+			return models.Role{}, ErrAuthorRole
+		}
+
+		err = database.UpdateRole(ctx, tx, eventId, roleId, role.Name)
+		if err != nil {
+			return models.Role{}, roleErr(err)
+		}
+	}
+
+	if request.Permissions != nil {
+		var removed, added []int64
+		for _, p := range role.Permissions {
+			if !slices.Contains(request.Permissions, p) {
+				removed = append(removed, p)
+			}
+		}
+		for _, p := range request.Permissions {
+			if !slices.Contains(role.Permissions, p) && !slices.Contains(added, p) {
+				added = append(added, p)
+			}
+		}
+
+		err = checkGrantable(ctx, tx, eventId, granterId, added)
+		if err != nil {
+			return models.Role{}, err
+		}
+
+		err = database.RemoveRolePermissions(ctx, tx, roleId, removed...)
+		if err != nil {
+			return models.Role{}, err
+		}
+
+		err = database.AddRolePermissions(ctx, tx, models.Role{ID: roleId, Permissions: added})
+		if err != nil {
+			return models.Role{}, err
+		}
+
+		role.Permissions, err = database.GetRolePermissions(ctx, tx, roleId)
+		if err != nil {
+			return models.Role{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Role{}, err
+	}
+
+	return *role, nil
+}
+
+// checkGrantable keeps managers from granting permissions they do not hold,
+// through roles or by assigning them.
+func checkGrantable(ctx context.Context, db database.DBTX, eventId, granterId int64, permissions []int64) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	ok, err := database.CheckPermission(ctx, db, eventId, granterId, permissions...)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPermissionNotHeld
+	}
+	return nil
+}
+
+func roleErr(err error) error {
+	var e *pgconn.PgError
+	if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
+		return ErrRoleExists
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"log"
+	"slices"
+	"time"
+)
+
+var (
+	ErrEventNotFound     = errors.New("event not found")
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// transitions lists the statuses an event can move to, canceled and finished
+// events are final.
+var transitions = map[int][]int{
+	pkg.EventStatusCreated: {pkg.EventStatusOngoing, pkg.EventStatusCanceled},
+	pkg.EventStatusOngoing: {pkg.EventStatusFinished, pkg.EventStatusCanceled},
+}
+
+func CanTransition(from, to int) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// ChangeStatus moves the event to the status and records the transition.
+// changedBy is nil for transitions made by the scheduler.
+func (e *Event) ChangeStatus(ctx context.Context, eventId int64, to int, changedBy *int64, reason *string) error {
+	tx, err := e.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = changeStatus(ctx, tx, models.StatusChange{EventID: eventId, To: to, ChangedBy: changedBy, Reason: reason})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func changeStatus(ctx context.Context, db database.DBTX, change models.StatusChange) error {
+	from, err := database.LockEventStatus(ctx, db, change.EventID)
+	if err != nil {
+		return err
+	}
+	if from == nil {
+		return ErrEventNotFound
+	}
+
+	if !CanTransition(*from, change.To) {
+		return fmt.Errorf("%w: from %d to %d", ErrInvalidTransition, *from, change.To)
+	}
+
+	change.From = *from
+
+	err = database.SetEventStatus(ctx, db, change.EventID, change.To)
+	if err != nil {
+		return err
+	}
+
+	return database.AddStatusHistory(ctx, db, change)
+}
+
+func (e *Event) StatusHistoryHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		history, err := database.GetStatusHistory(ctx.Context(), e.db.GetDb(), int64(eventId))
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"history": history})
+	}
+}
+
+// RunStatusScheduler moves events to ongoing once their first location starts
+// and to finished once their last location ends, until ctx is done.
+func (e *Event) RunStatusScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		e.advanceStatuses(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Event) advanceStatuses(ctx context.Context) {
+	now := time.Now()
+
+	started, err := database.GetEventsStartedBefore(ctx, e.db.GetDb(), pkg.EventStatusCreated, now)
+	if err != nil {
+		log.Println("could not get started events", err)
+		return
+	}
+	e.advance(ctx, started, pkg.EventStatusOngoing)
+
+	// events that also ended while the scheduler was down are ongoing by now
+	ended, err := database.GetEventsEndedBefore(ctx, e.db.GetDb(), pkg.EventStatusOngoing, now)
+	if err != nil {
+		log.Println("could not get ended events", err)
+		return
+	}
+	e.advance(ctx, ended, pkg.EventStatusFinished)
+}
+
+func (e *Event) advance(ctx context.Context, eventIds []int64, to int) {
+	for _, id := range eventIds {
+		err := e.ChangeStatus(ctx, id, to, nil, nil)
+		// another instance may have moved the event already
+		if err != nil && !errors.Is(err, ErrInvalidTransition) && !errors.Is(err, ErrEventNotFound) {
+			log.Println("could not change status of event", id, err)
+		}
+	}
+}
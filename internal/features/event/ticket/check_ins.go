@@ -0,0 +1,25 @@
+package ticket
+
+import (
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CheckInLog lists the ticket scans of the event, newest first.
+func CheckInLog(db *database.Database) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		lastId := ctx.QueryInt("lastId", 0)
+		checkIns, err := database.GetCheckIns(ctx.Context(), db.GetDb(), int64(eventId), int64(lastId))
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"checkIns": checkIns})
+	}
+}
@@ -2,17 +2,18 @@ package ticket
 
 import (
 	"context"
+	"errors"
+	"github.com/NuEventTeam/events/internal/features/token"
+	"github.com/NuEventTeam/events/internal/models"
 	"github.com/NuEventTeam/events/internal/storage/database"
-	"github.com/NuEventTeam/events/internal/storage/keydb"
 	"github.com/NuEventTeam/events/pkg"
+	"github.com/NuEventTeam/events/pkg/ticketcode"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/oklog/ulid/v2"
 	"log"
 	"time"
 )
 
-func GenerateTicket(db *database.Database, cache *keydb.Cache, secret string) fiber.Handler {
+func GenerateTicket(db *database.Database, tokens *token.Service, signer *token.TicketSigner) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		userId := ctx.Locals("userId").(int64)
 
@@ -21,35 +22,144 @@ func GenerateTicket(db *database.Database, cache *keydb.Cache, secret string) fi
 			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
 		}
 
-		ok, err := checkIfFollows(ctx.Context(), db.GetDb(), int64(eventId), userId)
+		t, err := issueTicket(ctx.Context(), db.GetDb(), tokens, signer, int64(eventId), userId)
 		if err != nil {
-			return pkg.Error(ctx, fiber.StatusBadRequest, "oops something went wrong", err)
+			return ticketError(ctx, err)
 		}
 
-		if !ok {
-			return pkg.Error(ctx, fiber.StatusBadRequest, "user is not registered to event")
-		}
+		return pkg.Success(ctx, fiber.Map{
+			"ticket": t.Code,
+		})
+	}
+}
 
-		token, err := generateTicketToken(secret, int64(eventId), userId)
-		if err != nil {
-			return pkg.Error(ctx, fiber.StatusBadRequest, "oops something went wrong", err)
-		}
+var (
+	errNotRegistered  = errors.New("user is not registered to event")
+	errSessionRemoved = errors.New("session of the registration was removed")
+)
 
-		key := ulid.Make().String()
+// issued is a signed ticket with the session and tier passes show.
+type issued struct {
+	ticketcode.Ticket
+	Code       string
+	Location   models.Location
+	TicketType *models.TicketType
+}
 
-		err = cache.Set(ctx.Context(), key, token, time.Hour*24)
-		if err != nil {
-			return pkg.Error(ctx, fiber.StatusBadRequest, "oops something went wrong", err)
+// issueTicket signs a ticket of the user valid until the grace period after
+// its session ends, sessions without an end use the ticket ttl.
+func issueTicket(ctx context.Context, db database.DBTX, tokens *token.Service, signer *token.TicketSigner, eventId, userId int64) (issued, error) {
+	ok, err := checkIfFollows(ctx, db, eventId, userId)
+	if err != nil {
+		return issued{}, err
+	}
+	if !ok {
+		return issued{}, errNotRegistered
+	}
+
+	ticketType, err := checkAccess(ctx, db, eventId, userId)
+	if err != nil {
+		return issued{}, err
+	}
+
+	locationId, _, err := database.GetFollowerLocation(ctx, db, eventId, userId)
+	if err != nil {
+		return issued{}, err
+	}
+	if locationId == nil {
+		return issued{}, errSessionRemoved
+	}
+
+	locations, err := database.GetEventLocations(ctx, db, eventId)
+	if err != nil {
+		return issued{}, err
+	}
+
+	t := issued{
+		Ticket: ticketcode.Ticket{
+			EventID:    eventId,
+			LocationID: *locationId,
+			UserID:     userId,
+			ExpiresAt:  time.Now().Add(tokens.TicketTTL()),
+		},
+		TicketType: ticketType,
+	}
+	for _, l := range locations {
+		if l.ID == *locationId {
+			t.Location = l
+			if l.EndsAt != nil {
+				t.ExpiresAt = time.Time(*l.EndsAt).Add(signer.Grace())
+			}
 		}
+	}
+	if ticketType != nil {
+		t.TicketTypeID = ticketType.ID
+	}
 
-		return pkg.Success(ctx, fiber.Map{
-			"ticket": key,
-		})
+	t.Code = signer.Sign(t.Ticket)
+	return t, nil
+}
+
+func ticketError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, pkg.ErrAgeRestricted):
+		return pkg.Error(ctx, fiber.StatusForbidden, err.Error(), err)
+	case errors.Is(err, errNotPaid):
+		return pkg.Error(ctx, fiber.StatusPaymentRequired, err.Error(), err)
+	case errors.Is(err, errNotRegistered), errors.Is(err, errSessionRemoved):
+		return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+	}
+	return pkg.Error(ctx, fiber.StatusBadRequest, "oops something went wrong", err)
+}
+
+var errNotPaid = errors.New("seat of the event is not paid")
+
+// checkAccess keeps users registered before the age range of the event changed
+// from getting a ticket. Events with a price or ticket types need a paid
+// order, whose ticket type is returned.
+func checkAccess(ctx context.Context, db database.DBTX, eventId, userId int64) (*models.TicketType, error) {
+	event, err := database.GetEventByID(ctx, db, eventId)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, errors.New("event not found")
+	}
+
+	user, err := database.GetUser(ctx, db, database.GetUserArgss{UserID: &userId})
+	if err != nil {
+		return nil, err
+	}
+
+	err = pkg.CheckAge(user.BirthDate, event.MinAge, event.MaxAge, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	types, err := database.GetTicketTypes(ctx, db, eventId)
+	if err != nil {
+		return nil, err
+	}
+	if event.Price <= 0 && len(types) == 0 {
+		return nil, nil
+	}
+
+	order, err := database.GetUserOrder(ctx, db, eventId, userId, pkg.OrderStatusPaid)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errNotPaid
 	}
+	if order.TicketTypeID == nil {
+		return nil, nil
+	}
+
+	return database.GetTicketType(ctx, db, *order.TicketTypeID)
 }
 
 func checkIfFollows(ctx context.Context, db database.DBTX, eventId, userId int64) (bool, error) {
-	query := `select count(*) from events inner join event_followers on event_followers.event_id = events.id where status = 1 and events.id = $1 and event_followers.user_id = $2`
+	query := `select count(*) from events inner join event_followers on event_followers.event_id = events.id where status in (1, 2) and events.id = $1 and event_followers.user_id = $2`
 
 	args := []interface{}{eventId, userId}
 	var count int64
@@ -60,23 +170,3 @@ func checkIfFollows(ctx context.Context, db database.DBTX, eventId, userId int64
 	log.Println(count)
 	return count > 0, nil
 }
-
-func generateTicketToken(secret string, eventId, userId int64) (string, error) {
-	var (
-		key = []byte(secret)
-	)
-	expireTime := time.Now().Add(time.Hour * 24)
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	claims := token.Claims.(jwt.MapClaims)
-	claims["userId"] = userId
-	claims["eventId"] = eventId
-	claims["exp"] = expireTime.Unix()
-
-	tokenString, err := token.SignedString(key)
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
-}
@@ -0,0 +1,100 @@
+package ticket
+
+import (
+	"fmt"
+	"github.com/NuEventTeam/events/internal/features/token"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/skip2/go-qrcode"
+	"strings"
+	"time"
+)
+
+const (
+	minQRSize = 128
+	maxQRSize = 1024
+)
+
+// TicketQR renders a freshly signed ticket as a png or svg QR code.
+func TicketQR(db *database.Database, tokens *token.Service, signer *token.TicketSigner) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userId := ctx.Locals("userId").(int64)
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		format := ctx.Query("format", "png")
+		if format != "png" && format != "svg" {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "format must be png or svg")
+		}
+		size := min(max(ctx.QueryInt("size", 256), minQRSize), maxQRSize)
+
+		t, err := issueTicket(ctx.Context(), db.GetDb(), tokens, signer, int64(eventId), userId)
+		if err != nil {
+			return ticketError(ctx, err)
+		}
+
+		qr, err := qrcode.New(t.Code, qrcode.Medium)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		ctx.Set(fiber.HeaderCacheControl, "no-store")
+		if format == "svg" {
+			ctx.Type("svg")
+			return ctx.SendString(svg(qr.Bitmap(), size))
+		}
+
+		png, err := qr.PNG(size)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+		ctx.Type("png")
+		return ctx.Send(png)
+	}
+}
+
+// svg draws the dark modules of the bitmap as a single path, one unit per
+// module.
+func svg(bitmap [][]bool, size int) string {
+	n := len(bitmap)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.String()
+}
+
+// PublicKeys publishes the keys tickets are signed with for offline
+// verification.
+func PublicKeys(signer *token.TicketSigner) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		return pkg.Success(ctx, fiber.Map{"keys": signer.PublicKeys()})
+	}
+}
+
+// Revocations publishes the tickets of canceled events for offline
+// verification, since is the unix time of the last revocation a verifier has.
+func Revocations(db *database.Database) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		since := time.Unix(int64(ctx.QueryInt("since")), 0)
+
+		revocations, err := database.GetTicketRevocations(ctx.Context(), db.GetDb(), since)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"revocations": revocations})
+	}
+}
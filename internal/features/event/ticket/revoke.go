@@ -0,0 +1,24 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/storage/keydb"
+)
+
+// eventTicketsKey is the set of ticket keys issued for an event before tickets
+// were signed codes.
+func eventTicketsKey(eventId int64) string {
+	return fmt.Sprintf("tickets:event:%d", eventId)
+}
+
+// RevokeEventTickets deletes every ticket key issued for the event. Signed
+// codes are revoked by the status of the event and the published revocations.
+func RevokeEventTickets(ctx context.Context, cache *keydb.Cache, eventId int64) error {
+	keys, err := cache.SMembers(ctx, eventTicketsKey(eventId))
+	if err != nil {
+		return err
+	}
+
+	return cache.Del(ctx, append(keys, eventTicketsKey(eventId))...)
+}
@@ -3,82 +3,190 @@ package ticket
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/features/token"
+	"github.com/NuEventTeam/events/internal/models"
 	"github.com/NuEventTeam/events/internal/storage/database"
 	"github.com/NuEventTeam/events/internal/storage/keydb"
 	"github.com/NuEventTeam/events/pkg"
+	"github.com/NuEventTeam/events/pkg/ticketcode"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"log"
 )
 
-func VerifyTicket(db *database.Database, cache *keydb.Cache, secret string) fiber.Handler {
+// admission is what a ticket admits, from a signed code or a legacy key.
+type admission struct {
+	UserID       int64
+	EventID      int64
+	LocationID   int64
+	TicketTypeID int64
+	TicketType   string
+}
+
+// VerifyTicket accepts signed ticket codes and the keys of tickets issued
+// before them. Offline checks cannot see revoked registrations, this one
+// does. A ticket is let in once, every scan goes to the check-in log.
+func VerifyTicket(db *database.Database, cache *keydb.Cache, tokens *token.Service, signer *token.TicketSigner) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		ticketId := ctx.Query("ticket")
+		scannerId := ctx.Locals("userId").(int64)
+
+		ticketParam := ctx.Query("ticket")
 		eventIdParam, err := ctx.ParamsInt("eventId")
-		log.Println("ticker id", ticketId)
-		ticket, err := cache.Get(ctx.Context(), ticketId)
 		if err != nil {
-			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		var ticket admission
+		if ticketcode.IsCode(ticketParam) {
+			ticket, err = fromCode(ctx.Context(), db.GetDb(), signer, ticketParam)
+		} else {
+			ticket, err = fromLegacyKey(ctx.Context(), cache, tokens, ticketParam)
+		}
+
+		scan := models.CheckIn{EventID: int64(eventIdParam), ScannedBy: scannerId}
+		if ticket.UserID != 0 {
+			scan.UserID = &ticket.UserID
+		}
+		if ticket.LocationID != 0 {
+			scan.LocationID = &ticket.LocationID
 		}
-		log.Println("ticket", ticket)
 
-		followerId, eventId, err := ParseTicket(ticket.(string), secret)
 		if err != nil {
-			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+			if errors.Is(err, ticketcode.ErrExpired) || errors.Is(err, jwt.ErrTokenExpired) {
+				return rejectScan(ctx, db.GetDb(), scan, pkg.CheckInExpired, err.Error(), err)
+			}
+			return rejectScan(ctx, db.GetDb(), scan, pkg.CheckInInvalid, err.Error(), err)
 		}
-		log.Println(eventIdParam, eventId)
+
+		followerId, eventId := ticket.UserID, ticket.EventID
 		if eventId != int64(eventIdParam) {
-			if err != nil {
-				return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
-			}
+			return rejectScan(ctx, db.GetDb(), scan, pkg.CheckInWrongEvent, "ticket is for another event")
+		}
+		status, err := database.GetEventStatus(ctx.Context(), db.GetDb(), eventId)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+		}
+		if status != nil && *status == pkg.EventStatusCanceled {
+			return rejectScan(ctx, db.GetDb(), scan, pkg.CheckInCanceled, "event was canceled")
 		}
-		log.Println("Follower ID", "event id", followerId, eventId)
 		ok, err := checkIfFollows(ctx.Context(), db.GetDb(), eventId, followerId)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 		}
 		if !ok {
-			return pkg.Error(ctx, fiber.StatusBadRequest, "user is not registered to event")
+			return rejectScan(ctx, db.GetDb(), scan, pkg.CheckInNotRegistered, "user is not registered to event")
+		}
+		// tickets issued before events had sessions carry no location
+		if ticket.LocationID != 0 {
+			locationId, _, err := database.GetFollowerLocation(ctx.Context(), db.GetDb(), eventId, followerId)
+			if err != nil {
+				return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+			}
+			if locationId == nil || *locationId != ticket.LocationID {
+				return rejectScan(ctx, db.GetDb(), scan, pkg.CheckInWrongSession, "ticket is for another session")
+			}
+			if sessionId := ctx.QueryInt("locationId"); sessionId != 0 && int64(sessionId) != ticket.LocationID {
+				return rejectScan(ctx, db.GetDb(), scan, pkg.CheckInWrongSession, "ticket is for another session")
+			}
 		}
-		err = setAttended(ctx.Context(), db.GetDb(), eventId, followerId)
+
+		ok, err = database.CheckInFollower(ctx.Context(), db.GetDb(), eventId, followerId, scannerId)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 		}
+		if !ok {
+			at, scanner, err := database.GetFollowerCheckIn(ctx.Context(), db.GetDb(), eventId, followerId)
+			if err != nil {
+				return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+			}
+			if at == "" {
+				return rejectScan(ctx, db.GetDb(), scan, pkg.CheckInNotRegistered, "user is not registered to event")
+			}
+			return rejectScan(ctx, db.GetDb(), scan, pkg.CheckInDuplicate, fmt.Sprintf("already checked in at %s by %s", at, scanner))
+		}
+
+		scan.Result = pkg.CheckInOK
+		logScan(ctx.Context(), db.GetDb(), scan)
 
-		return pkg.Success(ctx, nil)
+		// door staff see the tier the attendee bought
+		return pkg.Success(ctx, fiber.Map{
+			"userId":       followerId,
+			"locationId":   ticket.LocationID,
+			"ticketTypeId": ticket.TicketTypeID,
+			"ticketType":   ticket.TicketType,
+		})
 	}
 }
 
-func setAttended(ctx context.Context, db database.DBTX, eventId, userId int64) error {
-	query := `update event_followers set attended = true , updated_at = now() where event_id = $1 and user_id = $2`
+// rejectScan logs the failed scan with its reason and answers with it.
+// Duplicate scans are a conflict, the rest a bad request.
+func rejectScan(ctx *fiber.Ctx, db database.DBTX, scan models.CheckIn, result int, reason string, err ...error) error {
+	scan.Result = result
+	scan.Reason = &reason
+	logScan(ctx.Context(), db, scan)
 
-	args := []interface{}{eventId, userId}
+	status := fiber.StatusBadRequest
+	if result == pkg.CheckInDuplicate {
+		status = fiber.StatusConflict
+	}
+	return pkg.Error(ctx, status, reason, err...)
+}
 
-	_, err := db.Exec(ctx, query, args...)
-	return err
+// logScan does not fail the scan, the attendee is at the door.
+func logScan(ctx context.Context, db database.DBTX, scan models.CheckIn) {
+	if err := database.AddCheckIn(ctx, db, scan); err != nil {
+		log.Println("could not log check-in of event", scan.EventID, err)
+	}
 }
 
-func ParseTicket(ticket string, secret string) (int64, int64, error) {
-	token, err := jwt.Parse(ticket, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("Invalid sign method")
-		}
-		return []byte(secret), nil
-	})
+// fromCode returns the ticket of an expired code along with ErrExpired, so
+// the scan can be logged against its holder.
+func fromCode(ctx context.Context, db database.DBTX, signer *token.TicketSigner, code string) (admission, error) {
+	t, err := signer.Verify(code)
+	if err != nil && !errors.Is(err, ticketcode.ErrExpired) {
+		return admission{}, err
+	}
+
+	ticket := admission{
+		UserID:       t.UserID,
+		EventID:      t.EventID,
+		LocationID:   t.LocationID,
+		TicketTypeID: t.TicketTypeID,
+	}
 	if err != nil {
-		return 0, 0, err
+		return ticket, err
+	}
+	if t.TicketTypeID != 0 {
+		ticketType, err := database.GetTicketType(ctx, db, t.TicketTypeID)
+		if err != nil {
+			return admission{}, err
+		}
+		if ticketType != nil {
+			ticket.TicketType = ticketType.Name
+		}
 	}
 
-	if !token.Valid {
-		return 0, 0, jwt.ErrTokenExpired
+	return ticket, nil
+}
+
+func fromLegacyKey(ctx context.Context, cache *keydb.Cache, tokens *token.Service, key string) (admission, error) {
+	cached, err := cache.Get(ctx, key)
+	if err != nil {
+		return admission{}, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, 0, jwt.ErrTokenInvalidClaims
+	ticketToken, _ := cached.(string)
+	claims, err := tokens.ParseTicket(ticketToken)
+	if err != nil {
+		return admission{}, err
 	}
-	userId := int64(claims["userId"].(float64))
-	eventId := int64(claims["eventId"].(float64))
 
-	return userId, eventId, nil
+	return admission{
+		UserID:       claims.UserID,
+		EventID:      claims.EventID,
+		LocationID:   claims.LocationID,
+		TicketTypeID: claims.TicketTypeID,
+		TicketType:   claims.TicketType,
+	}, nil
 }
@@ -0,0 +1,118 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/features/token"
+	"github.com/NuEventTeam/events/internal/features/wallet"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+// ApplePass returns the ticket as an Apple Wallet .pkpass bundle.
+func ApplePass(db *database.Database, tokens *token.Service, signer *token.TicketSigner, wallets *wallet.Wallet) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		pass, err := walletPass(ctx.Context(), db.GetDb(), tokens, signer, int64(eventId), ctx.Locals("userId").(int64))
+		if err != nil {
+			return ticketError(ctx, err)
+		}
+
+		bundle, err := wallets.ApplePass(pass)
+		if err != nil {
+			if errors.Is(err, wallet.ErrAppleDisabled) {
+				return pkg.Error(ctx, fiber.StatusNotImplemented, err.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		ctx.Set(fiber.HeaderContentType, "application/vnd.apple.pkpass")
+		ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="ticket.pkpass"`)
+		return ctx.Send(bundle)
+	}
+}
+
+// GooglePass returns the link saving the ticket to Google Wallet.
+func GooglePass(db *database.Database, tokens *token.Service, signer *token.TicketSigner, wallets *wallet.Wallet) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		pass, err := walletPass(ctx.Context(), db.GetDb(), tokens, signer, int64(eventId), ctx.Locals("userId").(int64))
+		if err != nil {
+			return ticketError(ctx, err)
+		}
+
+		url, err := wallets.GoogleSaveURL(pass)
+		if err != nil {
+			if errors.Is(err, wallet.ErrGoogleDisabled) {
+				return pkg.Error(ctx, fiber.StatusNotImplemented, err.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"saveUrl": url})
+	}
+}
+
+// walletPass issues a ticket and fills in the event, session and holder.
+func walletPass(ctx context.Context, db database.DBTX, tokens *token.Service, signer *token.TicketSigner, eventId, userId int64) (wallet.Pass, error) {
+	t, err := issueTicket(ctx, db, tokens, signer, eventId, userId)
+	if err != nil {
+		return wallet.Pass{}, err
+	}
+
+	event, err := database.GetEventByID(ctx, db, eventId)
+	if err != nil {
+		return wallet.Pass{}, err
+	}
+	if event == nil {
+		return wallet.Pass{}, errors.New("event not found")
+	}
+
+	user, err := database.GetUser(ctx, db, database.GetUserArgss{UserID: &userId})
+	if err != nil {
+		return wallet.Pass{}, err
+	}
+
+	pass := wallet.Pass{
+		Serial:    fmt.Sprintf("%d-%d", eventId, userId),
+		Code:      t.Code,
+		EventID:   eventId,
+		Holder:    user.Firstname,
+		Latitude:  t.Location.Latitude,
+		Longitude: t.Location.Longitude,
+		ExpiresAt: t.ExpiresAt,
+	}
+	if event.Title != nil {
+		pass.EventTitle = *event.Title
+	}
+	if user.Lastname != nil {
+		pass.Holder += " " + *user.Lastname
+	}
+	if t.TicketType != nil {
+		pass.TicketType = t.TicketType.Name
+	}
+	if t.Location.Address != nil {
+		pass.Address = *t.Location.Address
+	}
+	if t.Location.StartsAt != nil {
+		startsAt := time.Time(*t.Location.StartsAt)
+		pass.StartsAt = &startsAt
+	}
+	if t.Location.EndsAt != nil {
+		endsAt := time.Time(*t.Location.EndsAt)
+		pass.EndsAt = &endsAt
+	}
+
+	return pass, nil
+}
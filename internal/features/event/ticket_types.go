@@ -0,0 +1,269 @@
+package event
+
+import (
+	"errors"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/NuEventTeam/events/pkg/types"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTicketTypeNotFound = errors.New("ticket type not found")
+	ErrPromoCodeNotFound  = errors.New("promo code not found")
+	ErrPromoCodeExists    = errors.New("promo code already exists")
+)
+
+type TicketTypeRequest struct {
+	Name       string          `json:"name"`
+	Price      float64         `json:"price"`
+	Quota      *int64          `json:"quota"`
+	SalesStart *types.DateTime `json:"salesStart"`
+	SalesEnd   *types.DateTime `json:"salesEnd"`
+}
+
+func (r TicketTypeRequest) validate() error {
+	switch {
+	case strings.TrimSpace(r.Name) == "":
+		return errors.New("ticket type name is empty")
+	case r.Price < 0:
+		return errors.New("price cannot be negative")
+	case r.Quota != nil && *r.Quota < 1:
+		return errors.New("quota must be positive")
+	case r.SalesStart != nil && r.SalesEnd != nil && !time.Time(*r.SalesEnd).After(time.Time(*r.SalesStart)):
+		return errors.New("sales must end after they start")
+	}
+	return nil
+}
+
+func (r TicketTypeRequest) ticketType(eventId int64) models.TicketType {
+	t := models.TicketType{
+		EventID: eventId,
+		Name:    strings.TrimSpace(r.Name),
+		Price:   r.Price,
+		Quota:   r.Quota,
+	}
+	if r.SalesStart != nil {
+		start := time.Time(*r.SalesStart)
+		t.SalesStart = &start
+	}
+	if r.SalesEnd != nil {
+		end := time.Time(*r.SalesEnd)
+		t.SalesEnd = &end
+	}
+	return t
+}
+
+func (e *Event) ListTicketTypesHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		ticketTypes, err := database.GetTicketTypes(ctx.Context(), e.db.GetDb(), int64(eventId))
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"ticketTypes": ticketTypes})
+	}
+}
+
+func (e *Event) CreateTicketTypeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request TicketTypeRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		if err := request.validate(); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+		}
+
+		t := request.ticketType(int64(eventId))
+		t.ID, err = database.CreateTicketType(ctx.Context(), e.db.GetDb(), t)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"ticketType": t})
+	}
+}
+
+// UpdateTicketTypeHandler changes a ticket type, orders already made keep the
+// price they were made with.
+func (e *Event) UpdateTicketTypeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request TicketTypeRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		typeId, err := ctx.ParamsInt("typeId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid ticket type id", err)
+		}
+
+		if err := request.validate(); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+		}
+
+		t := request.ticketType(int64(eventId))
+		t.ID = int64(typeId)
+
+		ok, err := database.UpdateTicketType(ctx.Context(), e.db.GetDb(), t)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+		if !ok {
+			return pkg.Error(ctx, fiber.StatusNotFound, ErrTicketTypeNotFound.Error())
+		}
+
+		return pkg.Success(ctx, fiber.Map{"ticketType": t})
+	}
+}
+
+func (e *Event) DeleteTicketTypeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		typeId, err := ctx.ParamsInt("typeId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid ticket type id", err)
+		}
+
+		ok, err := database.DeleteTicketType(ctx.Context(), e.db.GetDb(), int64(eventId), int64(typeId))
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+		if !ok {
+			return pkg.Error(ctx, fiber.StatusNotFound, ErrTicketTypeNotFound.Error())
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+type PromoCodeRequest struct {
+	Code      string          `json:"code"`
+	Kind      int             `json:"kind"`
+	Value     float64         `json:"value"`
+	MaxUses   *int64          `json:"maxUses"`
+	ExpiresAt *types.DateTime `json:"expiresAt"`
+}
+
+func (r PromoCodeRequest) validate() error {
+	switch {
+	case strings.TrimSpace(r.Code) == "":
+		return errors.New("code is empty")
+	case r.Kind != pkg.PromoKindPercent && r.Kind != pkg.PromoKindFixed:
+		return errors.New("unknown promo code kind")
+	case r.Kind == pkg.PromoKindPercent && (r.Value < 1 || r.Value > 100 || r.Value != float64(int64(r.Value))):
+		return errors.New("percent must be a whole number from 1 to 100")
+	case r.Kind == pkg.PromoKindFixed && r.Value <= 0:
+		return errors.New("discount must be positive")
+	case r.MaxUses != nil && *r.MaxUses < 1:
+		return errors.New("max uses must be positive")
+	}
+	return nil
+}
+
+func (e *Event) ListPromoCodesHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		codes, err := database.GetPromoCodes(ctx.Context(), e.db.GetDb(), int64(eventId))
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"promoCodes": codes})
+	}
+}
+
+func (e *Event) CreatePromoCodeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request PromoCodeRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		if err := request.validate(); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+		}
+
+		promo := models.PromoCode{
+			EventID: int64(eventId),
+			Code:    strings.TrimSpace(request.Code),
+			Kind:    request.Kind,
+			Value:   request.Value,
+			MaxUses: request.MaxUses,
+		}
+		if request.ExpiresAt != nil {
+			expiresAt := time.Time(*request.ExpiresAt)
+			promo.ExpiresAt = &expiresAt
+		}
+
+		promo.ID, err = database.CreatePromoCode(ctx.Context(), e.db.GetDb(), promo)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return pkg.Error(ctx, fiber.StatusConflict, ErrPromoCodeExists.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"promoCode": promo})
+	}
+}
+
+func (e *Event) DeletePromoCodeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		eventId, err := ctx.ParamsInt("eventId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		promoId, err := ctx.ParamsInt("promoId")
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid promo code id", err)
+		}
+
+		ok, err := database.DeletePromoCode(ctx.Context(), e.db.GetDb(), int64(eventId), int64(promoId))
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+		if !ok {
+			return pkg.Error(ctx, fiber.StatusNotFound, ErrPromoCodeNotFound.Error())
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
@@ -2,6 +2,7 @@ package event
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/NuEventTeam/events/internal/models"
 	"github.com/NuEventTeam/events/internal/storage/database"
@@ -9,25 +10,53 @@ import (
 	"github.com/NuEventTeam/events/pkg/types"
 	"github.com/gofiber/fiber/v2"
 	"log"
+	"slices"
+	"time"
 )
 
+var (
+	ErrLocationHasAttendees = errors.New("session has attendees")
+	ErrLastLocation         = errors.New("event must keep at least one session")
+)
+
+type LocationRequest struct {
+	ID        int64           `json:"locationId"`
+	Address   *string         `json:"address"`
+	Longitude *float64        `json:"longitude"`
+	Latitude  *float64        `json:"latitude"`
+	StartsAt  *types.DateTime `json:"startsAt"`
+	EndsAt    *types.DateTime `json:"endsAt"`
+	Seats     *int64          `json:"seats"`
+}
+
 type UpdateEventRequest struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Location    *struct {
-		ID        int64           `json:"locationId"`
-		Address   *string         `json:"address"`
-		Longitude *float64        `json:"longitude"`
-		Latitude  *float64        `json:"latitude"`
-		StartsAt  *types.DateTime `json:"startsAt"`
-		EndsAt    *types.DateTime `json:"endsAt"`
-		Seats     *int64          `json:"seats"`
-	} `json:"location"`
-	Categories     []int64 `json:"categories"`
-	AgeMax         *int64  `json:"ageMax"`
-	AgeMin         *int64  `json:"ageMin"`
-	Status         *int    `json:"status"`
-	RemoveImageIds []int64 `json:"removeImages"`
+	Title       *string          `json:"title"`
+	Description *string          `json:"description"`
+	Location    *LocationRequest `json:"location"`
+	// Locations without an id are added as new sessions.
+	Locations       []LocationRequest `json:"locations"`
+	RemoveLocations []int64           `json:"removeLocations"`
+	Categories      []int64           `json:"categories"`
+	AgeMax          *int64            `json:"ageMax"`
+	AgeMin          *int64            `json:"ageMin"`
+	Status          *int              `json:"status"`
+	RemoveImageIds  []int64           `json:"removeImages"`
+}
+
+func (l LocationRequest) validate() error {
+	if l.ID != 0 {
+		return nil
+	}
+	if l.Address == nil || l.Longitude == nil || l.Latitude == nil || l.StartsAt == nil || l.EndsAt == nil {
+		return errors.New("new sessions need an address, coordinates, start and end")
+	}
+	if l.StartsAt.Before(time.Now()) {
+		return errors.New("new sessions cannot start in the past")
+	}
+	if l.EndsAt.Before(time.Time(*l.StartsAt)) {
+		return errors.New("improper ending time")
+	}
+	return nil
 }
 
 func (e Event) UpdateEventHandler() fiber.Handler {
@@ -50,18 +79,22 @@ func (e Event) UpdateEventHandler() fiber.Handler {
 			MaxAge:          request.AgeMax,
 			MinAge:          request.AgeMin,
 			CategoryIds:     request.Categories,
-			Status:          request.Status,
 			RemoveImagesIds: request.RemoveImageIds,
 		}
 
+		locations := request.Locations
 		if request.Location != nil {
-			l := request.Location
-			var startsAt, endsAt *types.DateTime
-			if l.StartsAt != nil {
-				startsAt = l.StartsAt
+			locations = append(locations, *request.Location)
+		}
+		for _, l := range locations {
+			if err := l.validate(); err != nil {
+				return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+			}
+			if l.Seats != nil && *l.Seats < 0 {
+				return pkg.Error(ctx, fiber.StatusBadRequest, "seats cannot be negative")
 			}
-			if l.EndsAt != nil {
-				endsAt = l.EndsAt
+			if l.ID == 0 && l.Seats == nil {
+				l.Seats = new(int64)
 			}
 			event.Locations = append(event.Locations, models.Location{
 				ID:        l.ID,
@@ -69,27 +102,55 @@ func (e Event) UpdateEventHandler() fiber.Handler {
 				Address:   l.Address,
 				Longitude: l.Longitude,
 				Latitude:  l.Latitude,
-				StartsAt:  startsAt,
-				EndsAt:    endsAt,
+				StartsAt:  l.StartsAt,
+				EndsAt:    l.EndsAt,
 				Seats:     l.Seats,
 			})
 		}
+		event.RemoveLocationIds = request.RemoveLocations
 
-		err = e.UpdateEvent(ctx.Context(), event)
+		if request.Status != nil && *request.Status == pkg.EventStatusCanceled {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "events are canceled through the cancel endpoint")
+		}
+
+		var status *models.StatusChange
+		if request.Status != nil {
+			userId := ctx.Locals("userId").(int64)
+			status = &models.StatusChange{EventID: int64(eventId), To: *request.Status, ChangedBy: &userId}
+		}
+
+		err = e.UpdateEvent(ctx.Context(), event, status)
 		if err != nil {
+			if errors.Is(err, ErrLocationHasAttendees) || errors.Is(err, ErrLastLocation) || errors.Is(err, ErrInvalidTransition) {
+				return pkg.Error(ctx, fiber.StatusConflict, err.Error(), err)
+			}
+			if errors.Is(err, ErrEventNotFound) {
+				return pkg.Error(ctx, fiber.StatusNotFound, err.Error(), err)
+			}
 			return err
 		}
 		return pkg.Success(ctx, nil)
 	}
 }
 
-func (e Event) UpdateEvent(ctx context.Context, event models.Event) error {
+// UpdateEvent applies the changes of the event and, when status is set, its
+// status change in one transaction.
+func (e Event) UpdateEvent(ctx context.Context, event models.Event, status *models.StatusChange) error {
 	tx, err := e.db.BeginTx(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
+	var removedUrls []string
+
+	if status != nil {
+		err = changeStatus(ctx, tx, *status)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = database.UpdateMainEvent(ctx, tx, event)
 	if err != nil {
 		return err
@@ -108,12 +169,9 @@ func (e Event) UpdateEvent(ctx context.Context, event models.Event) error {
 			return err
 		}
 
-		urls := make([]string, len(imgs))
 		for _, i := range imgs {
-			urls = append(urls, i.Url)
+			removedUrls = append(removedUrls, i.Url)
 		}
-
-		e.assets.DeleteFile(ctx, urls...)
 	}
 
 	if len(event.CategoryIds) > 0 {
@@ -128,8 +186,19 @@ func (e Event) UpdateEvent(ctx context.Context, event models.Event) error {
 		}
 	}
 
-	if len(event.Locations) > 0 {
-		err := database.UpdateLocation(ctx, tx, event.ID, event.Locations[0].ID, event.Locations[0])
+	for _, l := range event.Locations {
+		if l.ID == 0 {
+			err = database.AddEventLocations(ctx, tx, event.ID, l)
+		} else {
+			err = database.UpdateLocation(ctx, tx, event.ID, l.ID, l)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(event.RemoveLocationIds) > 0 {
+		err := removeLocations(ctx, tx, event.ID, event.RemoveLocationIds)
 		if err != nil {
 			return err
 		}
@@ -143,11 +212,50 @@ func (e Event) UpdateEvent(ctx context.Context, event models.Event) error {
 		if err != nil {
 			return err
 		}
-		e.assets.Upload(ctx, event.Images...)
+
+		err = e.assets.Upload(ctx, event.Images...)
+		if err != nil {
+			e.assets.Discard(ctx, event.Images...)
+			return err
+		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
+		e.assets.Discard(ctx, event.Images...)
 		return err
 	}
+
+	if len(removedUrls) > 0 {
+		err = e.assets.DeleteFile(ctx, removedUrls...)
+		if err != nil {
+			log.Println("while deleting removed images", err)
+		}
+	}
+
 	return nil
 }
+
+// removeLocations removes sessions nobody registered for, keeping at least one
+// session for the event.
+func removeLocations(ctx context.Context, db database.DBTX, eventId int64, ids []int64) error {
+	locations, err := database.GetEventLocations(ctx, db, eventId)
+	if err != nil {
+		return err
+	}
+
+	remaining := 0
+	for _, l := range locations {
+		if !slices.Contains(ids, l.ID) {
+			remaining++
+			continue
+		}
+		if l.AttendeesCount != nil && *l.AttendeesCount > 0 {
+			return fmt.Errorf("%w: %d", ErrLocationHasAttendees, l.ID)
+		}
+	}
+	if remaining == 0 {
+		return ErrLastLocation
+	}
+
+	return database.RemoveLocations(ctx, db, eventId, ids...)
+}
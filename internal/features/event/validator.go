@@ -2,6 +2,7 @@ package event
 
 import (
 	"fmt"
+	"github.com/NuEventTeam/events/pkg/rrule"
 	"mime/multipart"
 	"time"
 )
@@ -15,19 +16,51 @@ func requireFormFiled(form *multipart.Form, field string) (string, bool) {
 func (c *CreateEventRequest) Validate() []string {
 	m := []string{}
 
-	if c.Date.Before(time.Now()) {
+	if len(c.Sessions) == 0 && c.Date.Before(time.Now()) {
 		m = append(m, "date cannot be set before current date")
 	}
 
-	if c.StartsAt.Before(time.Now().Add(time.Hour * 2)) {
-		m = append(m, "event start time mast be at least 2 hour before creations")
+	for i, s := range c.sessions() {
+		prefix := ""
+		if len(c.Sessions) > 0 {
+			prefix = fmt.Sprintf("session %d: ", i+1)
+		}
+		m = append(m, s.validate(prefix)...)
 	}
 
-	if c.EndsAt.Before(time.Time(c.StartsAt)) {
-		m = append(m, "improper ending time")
+	if c.Recurrence != nil {
+		if c.ID != 0 {
+			m = append(m, "recurrence can only be set when creating an event")
+		}
+		if len(c.Sessions) > 1 {
+			m = append(m, "recurring events have a single session")
+		}
+		if _, err := rrule.Parse(c.Recurrence.Rule); err != nil {
+			m = append(m, err.Error())
+		}
 	}
+
 	if len(m) > 0 {
 		return m
 	}
 	return nil
 }
+
+func (s SessionRequest) validate(prefix string) []string {
+	m := []string{}
+
+	// sessions that already exist may have started, only new ones must start later
+	if s.ID == 0 && s.StartsAt.Before(time.Now().Add(time.Hour*2)) {
+		m = append(m, prefix+"event start time mast be at least 2 hour before creations")
+	}
+
+	if s.EndsAt.Before(time.Time(s.StartsAt)) {
+		m = append(m, prefix+"improper ending time")
+	}
+
+	if s.Seats != nil && *s.Seats < 0 {
+		m = append(m, prefix+"seats cannot be negative")
+	}
+
+	return m
+}
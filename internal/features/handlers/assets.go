@@ -3,7 +3,9 @@ package handlers
 import "github.com/gofiber/fiber/v2"
 
 func (h Handler) SetUpAssetsRoutes(router *fiber.App) {
-	//router.Get("/get/:namespace/:key/:filename", h.Assets.GetFile())
-	router.Static("/static/", "./static")
+	router.Get("/get/:namespace/:key/:filename", h.Assets.GetFile())
 
+	if dir := h.Assets.LocalDir(); dir != "" {
+		router.Static("/static/", dir)
+	}
 }
@@ -16,11 +16,15 @@ func (h *Handler) SetUpAuthRoutes(app *fiber.App) {
 
 	apiV1.Post("reset/password", h.Auth.ResetPasswordHandler())
 
-	apiV1.Get("logout", MustAuth(h.JwtSecret), h.Auth.LogoutHandler())
+	apiV1.Get("logout", MustAuth(h.Tokens, h.Auth), h.Auth.LogoutHandler())
 
 	apiV1.Post("refresh", h.Auth.RefreshTokenHandler())
 
-	apiV1.Get("test-login", MustAuth(h.JwtSecret), func(ctx *fiber.Ctx) error {
+	apiV1.Get("sessions", MustAuth(h.Tokens, h.Auth), h.Auth.ListSessionsHandler())
+
+	apiV1.Delete("sessions/:id", MustAuth(h.Tokens, h.Auth), h.Auth.RevokeSessionHandler())
+
+	apiV1.Get("test-login", MustAuth(h.Tokens, h.Auth), func(ctx *fiber.Ctx) error {
 		return ctx.SendStatus(fiber.StatusOK)
 	})
 }
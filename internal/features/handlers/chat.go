@@ -8,8 +8,8 @@ import (
 func (h *Handler) SetUpChatRoutes(router *fiber.App) {
 	apiV1 := router.Group("/api/v1")
 
-	apiV1.Get("/event/chat/preview", MustAuth(h.JwtSecret), chat_features.GetChats(h.DB))
+	apiV1.Get("/event/chat/preview", MustAuth(h.Tokens, h.Auth), chat_features.GetChats(h.DB))
 
-	apiV1.Get("/event/chat/messages/:eventId", MustAuth(h.JwtSecret), chat_features.GetChatMessages(h.DB))
+	apiV1.Get("/event/chat/messages/:eventId", MustAuth(h.Tokens, h.Auth), chat_features.GetChatMessages(h.Store))
 
 }
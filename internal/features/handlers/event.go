@@ -14,67 +14,241 @@ func (h *Handler) SetUpEventRoutes(router *fiber.App) {
 	apiV1 := router.Group("/api/v1")
 	apiV1.Get("/categories", h.EventSvc.GetAllCategoriesHandler())
 
-	apiV1.Post("/event/create", MustAuth(h.JwtSecret), h.EventSvc.CreateEventHandler())
+	apiV1.Post("/event/create", MustAuth(h.Tokens, h.Auth), h.EventSvc.CreateEventHandler())
 	apiV1.Get("/event/show/all", h.EventSvc.GetAllEvenst())
 
 	apiV1.Get("/event/show/:eventId",
-		ExtractUserIdFromAuthHeader(h.JwtSecret),
+		ExtractUserIdFromAuthHeader(h.Tokens, h.Auth),
 		h.EventSvc.GetEventByIDHandler(),
 	)
 
 	apiV1.Put("/event/posts/:eventId",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		h.HasPermission(pkg.PermissionUpdate),
 		h.EventSvc.UpdateEventHandler(),
 	)
 
+	apiV1.Post("/event/cancel/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionUpdate),
+		h.EventSvc.CancelEventHandler(),
+	)
+
+	apiV1.Put("/event/occurrences/:eventId/:locationId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionUpdate),
+		h.EventSvc.EditOccurrenceHandler(),
+	)
+
+	apiV1.Get("/event/status/history/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionRead),
+		h.EventSvc.StatusHistoryHandler(),
+	)
+
 	apiV1.Put("/event/image/:eventId",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		h.HasPermission(pkg.PermissionUpdate),
 		h.EventSvc.AddImage(),
 	)
 
+	apiV1.Get("/event/tickets/types/:eventId",
+		h.EventSvc.ListTicketTypesHandler(),
+	)
+
+	apiV1.Post("/event/tickets/types/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionUpdate),
+		h.EventSvc.CreateTicketTypeHandler(),
+	)
+
+	apiV1.Put("/event/tickets/types/:eventId/:typeId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionUpdate),
+		h.EventSvc.UpdateTicketTypeHandler(),
+	)
+
+	apiV1.Delete("/event/tickets/types/:eventId/:typeId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionUpdate),
+		h.EventSvc.DeleteTicketTypeHandler(),
+	)
+
+	apiV1.Get("/event/tickets/promo/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionUpdate),
+		h.EventSvc.ListPromoCodesHandler(),
+	)
+
+	apiV1.Post("/event/tickets/promo/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionUpdate),
+		h.EventSvc.CreatePromoCodeHandler(),
+	)
+
+	apiV1.Delete("/event/tickets/promo/:eventId/:promoId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionUpdate),
+		h.EventSvc.DeletePromoCodeHandler(),
+	)
+
+	apiV1.Get("/event/team/roles/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionManageTeam),
+		h.EventSvc.ListRolesHandler(),
+	)
+
+	apiV1.Post("/event/team/roles/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionManageTeam),
+		h.EventSvc.CreateRoleHandler(),
+	)
+
+	apiV1.Put("/event/team/roles/:eventId/:roleId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionManageTeam),
+		h.EventSvc.UpdateRoleHandler(),
+	)
+
+	apiV1.Get("/event/team/managers/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionManageTeam),
+		h.EventSvc.ListManagersHandler(),
+	)
+
+	apiV1.Get("/event/team/invitations/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionManageTeam),
+		h.EventSvc.ListInvitationsHandler(),
+	)
+
+	apiV1.Post("/event/team/invitations/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionManageTeam),
+		h.EventSvc.InviteManagerHandler(),
+	)
+
+	apiV1.Delete("/event/team/invitations/:eventId/:invitationId",
+		MustAuth(h.Tokens, h.Auth),
+		h.EventSvc.RevokeInvitationHandler(),
+	)
+
+	apiV1.Post("/event/team/invitations/accept/:invitationId",
+		MustAuth(h.Tokens, h.Auth),
+		h.EventSvc.AcceptInvitationHandler(),
+	)
+
+	apiV1.Post("/event/team/invitations/decline/:invitationId",
+		MustAuth(h.Tokens, h.Auth),
+		h.EventSvc.DeclineInvitationHandler(),
+	)
+
+	apiV1.Put("/event/team/managers/:eventId/:userId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionManageTeam),
+		h.EventSvc.UpdateManagerHandler(),
+	)
+
+	apiV1.Delete("/event/team/managers/:eventId/:userId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionManageTeam),
+		h.EventSvc.RemoveManagerHandler(),
+	)
+
 	apiV1.Post("/event/fellowship/follow/:eventId",
-		MustAuth(h.JwtSecret),
-		followers.FollowEvent(h.DB))
+		MustAuth(h.Tokens, h.Auth),
+		followers.FollowEvent(h.Store))
 
 	apiV1.Post("/event/fellowship/unfollow/:eventId",
-		MustAuth(h.JwtSecret),
-		followers.Unfollow(h.DB))
+		MustAuth(h.Tokens, h.Auth),
+		followers.Unfollow(h.Store))
+
+	apiV1.Get("/event/fellowship/waitlist/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		followers.WaitlistPosition(h.Store))
 
 	apiV1.Post("/event/fellowship/list/:eventId",
-		MustAuth(h.JwtSecret),
-		followers.ListFollowers(h.DB))
+		MustAuth(h.Tokens, h.Auth),
+		followers.ListFollowers(h.Store))
+
+	apiV1.Get("/event/fellowship/export/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionRead),
+		followers.ExportFollowers(h.Store))
 
 	apiV1.Post("/event/fellowship/exist/:eventId",
-		MustAuth(h.JwtSecret),
-		followers.CheckIfFollowed(h.DB))
+		MustAuth(h.Tokens, h.Auth),
+		followers.CheckIfFollowed(h.Store))
 
 	apiV1.Post("/event/fellowship/search/:eventId",
-		MustAuth(h.JwtSecret),
-		followers.ListFollowers(h.DB))
+		MustAuth(h.Tokens, h.Auth),
+		followers.ListFollowers(h.Store))
+
+	apiV1.Post("/event/orders/create/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.Orders.CreateOrderHandler())
+
+	apiV1.Get("/event/orders/show/:orderId",
+		MustAuth(h.Tokens, h.Auth),
+		h.Orders.GetOrderHandler())
+
+	apiV1.Post("/event/orders/cancel/:orderId",
+		MustAuth(h.Tokens, h.Auth),
+		h.Orders.CancelOrderHandler())
+
+	apiV1.Post("/payments/webhook",
+		h.Orders.WebhookHandler())
+
+	if h.Orders.FakePayments() {
+		apiV1.Post("/payments/fake/pay/:paymentId",
+			h.Orders.FakePayHandler())
+	}
 
 	apiV1.Post("/event/comment/add",
-		MustAuth(h.JwtSecret),
-		comments.AddCommentHandler(h.DB))
+		MustAuth(h.Tokens, h.Auth),
+		comments.AddCommentHandler(h.Store))
 
 	apiV1.Post("/event/comment/fetch",
-		comments.FetchCommentHandler(h.DB))
+		comments.FetchCommentHandler(h.Store))
 
 	apiV1.Post("/event/like/:eventId",
-		MustAuth(h.JwtSecret),
-		like.LikeEvent(h.DB))
+		MustAuth(h.Tokens, h.Auth),
+		like.LikeEvent(h.Store))
 
 	apiV1.Post("/event/ticket/get/:eventId",
-		MustAuth(h.JwtSecret),
-		ticket.GenerateTicket(h.DB, h.Cache, h.JwtSecret))
+		MustAuth(h.Tokens, h.Auth),
+		ticket.GenerateTicket(h.DB, h.Tokens, h.Tickets))
+
+	apiV1.Get("/event/ticket/qr/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		ticket.TicketQR(h.DB, h.Tokens, h.Tickets))
+
+	apiV1.Get("/event/ticket/wallet/apple/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		ticket.ApplePass(h.DB, h.Tokens, h.Tickets, h.Wallet))
+
+	apiV1.Get("/event/ticket/wallet/google/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		ticket.GooglePass(h.DB, h.Tokens, h.Tickets, h.Wallet))
+
+	apiV1.Get("/tickets/keys",
+		ticket.PublicKeys(h.Tickets))
+
+	apiV1.Get("/tickets/revocations",
+		ticket.Revocations(h.DB))
 
 	apiV1.Post("/event/ticket/verify/:eventId",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		h.HasPermission(pkg.PermissionVerify),
-		ticket.VerifyTicket(h.DB, h.Cache, h.JwtSecret))
+		ticket.VerifyTicket(h.DB, h.Cache, h.Tokens, h.Tickets))
+
+	apiV1.Get("/event/ticket/checkins/:eventId",
+		MustAuth(h.Tokens, h.Auth),
+		h.HasPermission(pkg.PermissionRead),
+		ticket.CheckInLog(h.DB))
 
 	apiV1.Post("/event/search/",
+		ExtractUserIdFromAuthHeader(h.Tokens, h.Auth),
 		search.SearchEvents(h.DB))
 
 }
@@ -4,29 +4,41 @@ import (
 	"github.com/NuEventTeam/events/internal/features/assets"
 	"github.com/NuEventTeam/events/internal/features/auth"
 	"github.com/NuEventTeam/events/internal/features/event"
+	"github.com/NuEventTeam/events/internal/features/orders"
+	"github.com/NuEventTeam/events/internal/features/token"
 	"github.com/NuEventTeam/events/internal/features/user"
+	"github.com/NuEventTeam/events/internal/features/wallet"
 	"github.com/NuEventTeam/events/internal/storage/database"
 	"github.com/NuEventTeam/events/internal/storage/keydb"
+	"github.com/NuEventTeam/events/internal/storage/repository"
 )
 
 type Handler struct {
-	EventSvc  *event.Event
-	Cache     *keydb.Cache
-	DB        *database.Database
-	UserSvc   *user.User
-	Assets    *assets.Assets
-	Auth      *auth.Auth
-	JwtSecret string
+	EventSvc *event.Event
+	Cache    *keydb.Cache
+	DB       *database.Database
+	Store    repository.Store
+	UserSvc  *user.User
+	Assets   *assets.Assets
+	Auth     *auth.Auth
+	Tokens   *token.Service
+	Orders   *orders.Orders
+	Tickets  *token.TicketSigner
+	Wallet   *wallet.Wallet
 }
 
-func New(event *event.Event, cache *keydb.Cache, user *user.User, assets *assets.Assets, auth *auth.Auth, jwt string, db *database.Database) *Handler {
+func New(event *event.Event, cache *keydb.Cache, user *user.User, assets *assets.Assets, auth *auth.Auth, tokens *token.Service, db *database.Database, store repository.Store, orders *orders.Orders, tickets *token.TicketSigner, wallet *wallet.Wallet) *Handler {
 	return &Handler{
-		EventSvc:  event,
-		Cache:     cache,
-		UserSvc:   user,
-		Assets:    assets,
-		Auth:      auth,
-		JwtSecret: jwt,
-		DB:        db,
+		EventSvc: event,
+		Cache:    cache,
+		UserSvc:  user,
+		Assets:   assets,
+		Auth:     auth,
+		Tokens:   tokens,
+		DB:       db,
+		Store:    store,
+		Orders:   orders,
+		Tickets:  tickets,
+		Wallet:   wallet,
 	}
 }
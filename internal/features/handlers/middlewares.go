@@ -3,7 +3,9 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"github.com/NuEventTeam/events/internal/features/auth"
 	"github.com/NuEventTeam/events/internal/features/event"
+	"github.com/NuEventTeam/events/internal/features/token"
 	"github.com/NuEventTeam/events/pkg"
 	"github.com/gofiber/fiber/v2"
 	"net/http"
@@ -11,7 +13,8 @@ import (
 	"strings"
 )
 
-func MustAuth(jwtSecret string) fiber.Handler {
+// MustAuth lets in access tokens whose session was not revoked.
+func MustAuth(tokens *token.Service, sessions *auth.Auth) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		tokenString := ctx.Get("Authorization")
 		if tokenString == "" {
@@ -21,33 +24,46 @@ func MustAuth(jwtSecret string) fiber.Handler {
 		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
 			return pkg.Error(ctx, http.StatusUnauthorized, "unauthorized", fmt.Errorf("invalid token"))
 		}
-		userID, userAgent, err := pkg.ParseJWT(headerParts[1], jwtSecret)
+		claims, err := tokens.ParseAccess(headerParts[1])
 		if err != nil {
 			return pkg.Error(ctx, http.StatusBadRequest, "invalid token", err)
 		}
-		ctx.Locals("userId", userID)
-		ctx.Locals("userAgent", userAgent)
+		ok, err := sessions.SessionActive(ctx.Context(), claims.UserID, claims.SessionID)
+		if err != nil {
+			return pkg.Error(ctx, http.StatusInternalServerError, "something went wrong", err)
+		}
+		if !ok {
+			return pkg.Error(ctx, http.StatusUnauthorized, "session revoked")
+		}
+		ctx.Locals("userId", claims.UserID)
+		ctx.Locals("userAgent", claims.UserAgent)
+		ctx.Locals("sessionId", claims.SessionID)
 		return ctx.Next()
 	}
 }
 
-func ExtractUserIdFromAuthHeader(jwtSecret string) fiber.Handler {
+func ExtractUserIdFromAuthHeader(tokens *token.Service, sessions *auth.Auth) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
+		ctx.Locals("userId", int64(0))
+
 		tokenString := ctx.Get("Authorization")
 		if tokenString == "" {
-			ctx.Locals("userId", int64(0))
 			return ctx.Next()
 		}
 		headerParts := strings.Split(tokenString, " ")
 		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-			ctx.Locals("userId", int64(0))
+			return ctx.Next()
 		}
-		userID, userAgent, err := pkg.ParseJWT(headerParts[1], jwtSecret)
+		claims, err := tokens.ParseAccess(headerParts[1])
 		if err != nil {
-			ctx.Locals("userId", int64(0))
+			return ctx.Next()
+		}
+		if ok, _ := sessions.SessionActive(ctx.Context(), claims.UserID, claims.SessionID); !ok {
+			return ctx.Next()
 		}
-		ctx.Locals("userId", userID)
-		ctx.Locals("userAgent", userAgent)
+		ctx.Locals("userId", claims.UserID)
+		ctx.Locals("userAgent", claims.UserAgent)
+		ctx.Locals("sessionId", claims.SessionID)
 		return ctx.Next()
 	}
 }
@@ -11,65 +11,80 @@ func (h *Handler) SetUpUserRoutes(router *fiber.App) {
 	apiV1 := router.Group("/api/v1")
 
 	apiV1.Post("/create/user",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		h.UserSvc.CreateUserHandler(),
 	)
 
 	apiV1.Get("/check-username/:username",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		h.UserSvc.GetByUsername(),
 	)
 
 	apiV1.Post("/create/mobile/user",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		h.UserSvc.CreateMobileUserHandler(),
 	)
 
-	apiV1.Get("/user/:username", MustAuth(h.JwtSecret), h.UserSvc.GetByUsername())
+	apiV1.Get("/user/:username", MustAuth(h.Tokens, h.Auth), h.UserSvc.GetByUsername())
 
 	apiV1.Post("users/friendship/check/:userId",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		user_follow.CheckFollowed(h.DB),
 	)
 
 	apiV1.Post("/users/friendship/follow/:userId",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		user_follow.FollowUser(h.DB),
 	)
 
 	apiV1.Post("/users/friendship/unfollow/:userId",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		user_follow.UnfollowUser(h.DB),
 	)
 	apiV1.Post("/users/friendship/followed",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		user_follow.ListFollowed(h.DB),
 	)
 	apiV1.Post("/users/friendship/follower",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		user_follow.ListFollowers(h.DB),
 	)
 
 	apiV1.Post("/users/profile/events/followed",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		user_profile.GetFollowedEventsHandler(h.DB),
 	)
 
 	apiV1.Get("/users/profile/",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		h.UserSvc.GetOwnUserProfile(),
 	)
 
 	apiV1.Post("/users/profile/events/history",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		user_profile.GetOldEventsHandler(h.DB),
 	)
 
 	apiV1.Post("/users/profile/events/favorite",
-		MustAuth(h.JwtSecret),
+		MustAuth(h.Tokens, h.Auth),
 		user_profile.GetLikedEventsHandler(h.DB),
 	)
 
+	apiV1.Post("/users/devices",
+		MustAuth(h.Tokens, h.Auth),
+		h.UserSvc.RegisterDeviceHandler(),
+	)
+
+	apiV1.Delete("/users/devices",
+		MustAuth(h.Tokens, h.Auth),
+		h.UserSvc.UnregisterDeviceHandler(),
+	)
+
+	apiV1.Get("/users/profile/invitations",
+		MustAuth(h.Tokens, h.Auth),
+		user_profile.GetManagerInvitationsHandler(h.DB),
+	)
+
 	apiV1.Post("/users/profile/search/", search.SearchUser(h.DB))
 
 }
@@ -2,6 +2,7 @@ package notification
 
 import (
 	"context"
+	"errors"
 	firebase "firebase.google.com/go"
 	"firebase.google.com/go/messaging"
 	"github.com/NuEventTeam/events/pkg"
@@ -43,3 +44,29 @@ func Send(tokens []string, data map[string]string) error {
 	_, err := notify.SendMulticast(context.Background(), message)
 	return err
 }
+
+// maxMulticastTokens is the most tokens FCM accepts in one multicast message.
+const maxMulticastTokens = 500
+
+// Notify sends the notification to every token, in batches FCM accepts.
+func Notify(ctx context.Context, tokens []string, title, body string, data map[string]string) error {
+	if notify == nil || len(tokens) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for start := 0; start < len(tokens); start += maxMulticastTokens {
+		end := min(start+maxMulticastTokens, len(tokens))
+
+		_, err := notify.SendMulticast(ctx, &messaging.MulticastMessage{
+			Notification: &messaging.Notification{Title: title, Body: body},
+			Data:         data,
+			Tokens:       tokens[start:end],
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
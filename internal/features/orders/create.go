@@ -0,0 +1,249 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/features/event/followers"
+	"github.com/NuEventTeam/events/internal/features/payment"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"log"
+	"strconv"
+	"time"
+)
+
+type OrderRequest struct {
+	EventID    int64
+	LocationID int64
+	UserID     int64
+	// TicketTypeID may be left out for events with a single ticket type.
+	TicketTypeID int64
+	PromoCode    string
+}
+
+func (o *Orders) CreateOrderHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userId := ctx.Locals("userId").(int64)
+
+		eventId, err := strconv.ParseInt(ctx.Params("eventId"), 10, 64)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid event id", err)
+		}
+
+		order, checkout, err := o.CreateOrder(ctx.Context(), OrderRequest{
+			EventID:      eventId,
+			LocationID:   int64(ctx.QueryInt("locationId", 0)),
+			UserID:       userId,
+			TicketTypeID: int64(ctx.QueryInt("ticketTypeId", 0)),
+			PromoCode:    ctx.Query("promoCode"),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, pkg.ErrAgeRestricted):
+				return pkg.Error(ctx, fiber.StatusForbidden, err.Error(), err)
+			case errors.Is(err, ErrTicketTypeNotFound):
+				return pkg.Error(ctx, fiber.StatusNotFound, err.Error(), err)
+			case errors.Is(err, ErrAlreadyFollows), errors.Is(err, ErrSessionFull), errors.Is(err, ErrOrderExists),
+				errors.Is(err, ErrSoldOut), errors.Is(err, ErrPromoUsedUp):
+				return pkg.Error(ctx, fiber.StatusConflict, err.Error(), err)
+			case errors.Is(err, errRegistration), errors.Is(err, ErrFreeEvent), errors.Is(err, ErrTicketTypeRequired),
+				errors.Is(err, ErrNotOnSale), errors.Is(err, ErrInvalidPromo):
+				return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"order": order, "paymentUrl": checkout.URL})
+	}
+}
+
+var errRegistration = errors.New("cannot register to the event")
+
+// CreateOrder holds a seat of the session and a ticket of the type for the
+// user and starts its payment. Orders which cost nothing after the promo code
+// register the user right away.
+func (o *Orders) CreateOrder(ctx context.Context, r OrderRequest) (models.Order, payment.Checkout, error) {
+	event, location, err := followers.CheckRegistration(ctx, o.store, r.EventID, r.LocationID, r.UserID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrAgeRestricted) {
+			return models.Order{}, payment.Checkout{}, err
+		}
+		return models.Order{}, payment.Checkout{}, fmt.Errorf("%w: %w", errRegistration, err)
+	}
+
+	ticketTypes, err := o.store.Tickets().ListTypes(ctx, r.EventID)
+	if err != nil {
+		return models.Order{}, payment.Checkout{}, err
+	}
+
+	now := time.Now()
+
+	ticketType, amount, err := price(event, ticketTypes, r.TicketTypeID, now)
+	if err != nil {
+		return models.Order{}, payment.Checkout{}, err
+	}
+
+	follows, err := o.store.Followers().Exists(ctx, r.EventID, r.UserID)
+	if err != nil {
+		return models.Order{}, payment.Checkout{}, err
+	}
+	if follows {
+		return models.Order{}, payment.Checkout{}, ErrAlreadyFollows
+	}
+
+	order := models.Order{
+		EventID:    r.EventID,
+		LocationID: location.ID,
+		UserID:     r.UserID,
+		Amount:     amount,
+		Currency:   o.cfg.Currency,
+		Status:     pkg.OrderStatusPending,
+		ExpiresAt:  now.Add(o.cfg.HoldDuration),
+	}
+	if ticketType != nil {
+		order.TicketTypeID = &ticketType.ID
+	}
+
+	if r.PromoCode != "" {
+		promo, err := o.store.Tickets().GetPromo(ctx, r.EventID, r.PromoCode)
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.Order{}, payment.Checkout{}, ErrInvalidPromo
+		}
+		if err != nil {
+			return models.Order{}, payment.Checkout{}, err
+		}
+
+		order.Discount, err = discount(amount, promo, now)
+		if err != nil {
+			return models.Order{}, payment.Checkout{}, err
+		}
+		order.Amount -= order.Discount
+		order.PromoCodeID = &promo.ID
+	}
+
+	err = o.store.WithTx(ctx, func(tx repository.Store) error {
+		ok, err := tx.Events().ReserveSeat(ctx, order.LocationID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrSessionFull
+		}
+
+		if err := reserve(ctx, tx, order); err != nil {
+			return err
+		}
+
+		order.ID, err = tx.Orders().Create(ctx, order)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return ErrOrderExists
+		}
+		if err != nil || order.Amount > 0 {
+			return err
+		}
+
+		_, err = tx.Orders().SetStatus(ctx, order.ID, pkg.OrderStatusPaid, pkg.OrderStatusPending)
+		if err != nil {
+			return err
+		}
+		return followers.Register(ctx, tx, order.EventID, order.LocationID, order.UserID)
+	})
+	if err != nil {
+		return models.Order{}, payment.Checkout{}, err
+	}
+
+	if order.Amount == 0 {
+		order, err = o.store.Orders().Get(ctx, order.ID)
+		return order, payment.Checkout{}, err
+	}
+
+	var title string
+	if event.Title != nil {
+		title = *event.Title
+	}
+	if ticketType != nil {
+		title += ", " + ticketType.Name
+	}
+
+	checkout, err := o.provider.CreatePayment(ctx, payment.Payment{
+		OrderID:     order.ID,
+		Amount:      order.Amount,
+		Currency:    order.Currency,
+		Description: title,
+	})
+	if err != nil {
+		if _, rerr := o.release(ctx, order, pkg.OrderStatusCanceled); rerr != nil {
+			log.Println("could not release order", order.ID, rerr)
+		}
+		return models.Order{}, payment.Checkout{}, err
+	}
+
+	err = o.store.Orders().SetPayment(ctx, order.ID, o.provider.Name(), checkout.PaymentID)
+	if err != nil {
+		return models.Order{}, payment.Checkout{}, err
+	}
+
+	order, err = o.store.Orders().Get(ctx, order.ID)
+	return order, checkout, err
+}
+
+// reserve takes a ticket of the type and a use of the promo code of the order.
+func reserve(ctx context.Context, tx repository.Store, order models.Order) error {
+	if order.TicketTypeID != nil {
+		ok, err := tx.Tickets().ReserveType(ctx, *order.TicketTypeID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrSoldOut
+		}
+	}
+
+	if order.PromoCodeID != nil {
+		ok, err := tx.Tickets().RedeemPromo(ctx, *order.PromoCodeID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrPromoUsedUp
+		}
+	}
+
+	return nil
+}
+
+// release ends a pending order with status and frees its seat, ticket and
+// promo code use. It reports false when the order was no longer pending.
+func (o *Orders) release(ctx context.Context, order models.Order, status int) (bool, error) {
+	var released bool
+
+	err := o.store.WithTx(ctx, func(tx repository.Store) error {
+		ok, err := tx.Orders().SetStatus(ctx, order.ID, status, pkg.OrderStatusPending)
+		if err != nil || !ok {
+			return err
+		}
+		released = true
+
+		err = tx.Events().ChangeAttendeesCount(ctx, order.LocationID, -1)
+		if err != nil {
+			return err
+		}
+
+		if order.TicketTypeID != nil {
+			err = tx.Tickets().ReleaseType(ctx, *order.TicketTypeID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if order.PromoCodeID != nil {
+			return tx.Tickets().ReleasePromo(ctx, *order.PromoCodeID)
+		}
+		return nil
+	})
+
+	return released, err
+}
@@ -0,0 +1,63 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"github.com/NuEventTeam/events/pkg"
+	"log"
+	"time"
+)
+
+// RunHoldExpiry releases the seats of orders which were not paid in time,
+// until ctx is done.
+func (o *Orders) RunHoldExpiry(ctx context.Context) {
+	ticker := time.NewTicker(o.cfg.ExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		o.expire(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Orders) expire(ctx context.Context) {
+	expired, err := o.store.Orders().ListExpired(ctx, time.Now())
+	if err != nil {
+		log.Println("could not get expired orders", err)
+		return
+	}
+
+	for _, order := range expired {
+		if _, err := o.release(ctx, order, pkg.OrderStatusExpired); err != nil {
+			log.Println("could not expire order", order.ID, err)
+		}
+	}
+}
+
+// RefundEvent cancels the pending orders of a canceled event and refunds its
+// paid ones.
+func (o *Orders) RefundEvent(ctx context.Context, eventId int64) error {
+	orders, err := o.store.Orders().ListByEvent(ctx, eventId, pkg.OrderStatusPending, pkg.OrderStatusPaid)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, order := range orders {
+		if order.Status == pkg.OrderStatusPending {
+			_, err = o.release(ctx, order, pkg.OrderStatusCanceled)
+		} else {
+			err = o.refund(ctx, order, pkg.OrderStatusPaid)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,50 @@
+package orders
+
+import (
+	"errors"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/NuEventTeam/events/internal/features/payment"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"time"
+)
+
+var (
+	ErrFreeEvent       = errors.New("event is free, follow it instead")
+	ErrAlreadyFollows  = errors.New("user already follows the event")
+	ErrSessionFull     = errors.New("session is full")
+	ErrOrderExists     = errors.New("user already has an order for the event")
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrOrderNotPending = errors.New("order is not pending")
+)
+
+// Orders sells the seats of paid events. A seat is held for the order until
+// the provider reports its payment, and the user becomes a follower only then.
+type Orders struct {
+	store    repository.Store
+	provider payment.Provider
+	cfg      config.Payments
+	// dev lets the fake provider complete its payments through the api.
+	dev bool
+}
+
+func New(store repository.Store, provider payment.Provider, cfg config.Payments, dev bool) *Orders {
+	if cfg.HoldDuration <= 0 {
+		cfg.HoldDuration = 15 * time.Minute
+	}
+	if cfg.ExpiryInterval <= 0 {
+		cfg.ExpiryInterval = time.Minute
+	}
+
+	return &Orders{
+		store:    store,
+		provider: provider,
+		cfg:      cfg,
+		dev:      dev,
+	}
+}
+
+// FakePayments reports whether payments are completed through FakePayHandler.
+func (o *Orders) FakePayments() bool {
+	_, ok := o.provider.(*payment.Fake)
+	return o.dev && ok
+}
@@ -0,0 +1,71 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+func (o *Orders) GetOrderHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userId := ctx.Locals("userId").(int64)
+
+		orderId, err := strconv.ParseInt(ctx.Params("orderId"), 10, 64)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid order id", err)
+		}
+
+		order, err := o.getOrder(ctx.Context(), orderId, userId)
+		if err != nil {
+			if errors.Is(err, ErrOrderNotFound) {
+				return pkg.Error(ctx, fiber.StatusNotFound, err.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"order": order})
+	}
+}
+
+// CancelOrderHandler lets the user give up a pending order and its seat.
+func (o *Orders) CancelOrderHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userId := ctx.Locals("userId").(int64)
+
+		orderId, err := strconv.ParseInt(ctx.Params("orderId"), 10, 64)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid order id", err)
+		}
+
+		order, err := o.getOrder(ctx.Context(), orderId, userId)
+		if err != nil {
+			if errors.Is(err, ErrOrderNotFound) {
+				return pkg.Error(ctx, fiber.StatusNotFound, err.Error(), err)
+			}
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		ok, err := o.release(ctx.Context(), order, pkg.OrderStatusCanceled)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+		if !ok {
+			return pkg.Error(ctx, fiber.StatusConflict, ErrOrderNotPending.Error())
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+// getOrder returns ErrOrderNotFound for orders of other users too.
+func (o *Orders) getOrder(ctx context.Context, orderId, userId int64) (models.Order, error) {
+	order, err := o.store.Orders().Get(ctx, orderId)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && order.UserID != userId) {
+		return models.Order{}, ErrOrderNotFound
+	}
+	return order, err
+}
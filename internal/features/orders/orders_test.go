@@ -0,0 +1,226 @@
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/NuEventTeam/events/internal/features/payment"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository/memory"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/NuEventTeam/events/pkg/types"
+	"github.com/gofiber/fiber/v2"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type response struct {
+	Ok   bool `json:"ok"`
+	Data struct {
+		Order      models.Order `json:"order"`
+		PaymentURL string       `json:"paymentUrl"`
+	} `json:"data"`
+}
+
+type fixture struct {
+	store   *memory.Store
+	orders  *Orders
+	app     *fiber.App
+	eventId int64
+}
+
+// newFixture seeds a paid event with a single session of the given seats and
+// a ticket type.
+func newFixture(t *testing.T, seats int64) *fixture {
+	t.Helper()
+
+	store := memory.New()
+	startsAt := time.Now().Add(24 * time.Hour)
+	eventId := store.AddEvent(models.Event{
+		Locations:   []models.Location{{StartsAt: new(types.DateTime).FromTime(&startsAt), Seats: &seats}},
+		TicketTypes: []models.TicketType{{Name: "standard", Price: 1000}},
+	})
+	orders := New(store, payment.NewFake("secret"), config.Payments{Currency: "KZT"}, true)
+	app := fiber.New()
+	// stands in for MustAuth
+	app.Use(func(ctx *fiber.Ctx) error {
+		userId, _ := strconv.ParseInt(ctx.Get("X-User-Id"), 10, 64)
+		ctx.Locals("userId", userId)
+		return ctx.Next()
+	})
+	app.Post("/orders/create/:eventId", orders.CreateOrderHandler())
+	app.Post("/orders/cancel/:orderId", orders.CancelOrderHandler())
+	app.Post("/payments/fake/pay/:paymentId", orders.FakePayHandler())
+
+	return &fixture{
+		store:   store,
+		orders:  orders,
+		app:     app,
+		eventId: eventId,
+	}
+}
+
+func (f *fixture) call(t *testing.T, path string, userId int64) (int, response) {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", path, nil)
+	req.Header.Set("X-User-Id", strconv.FormatInt(userId, 10))
+	resp, err := f.app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
+
+func (f *fixture) order(t *testing.T, userId int64) response {
+	t.Helper()
+
+	status, body := f.call(t, fmt.Sprint("/orders/create/", f.eventId), userId)
+	if status != fiber.StatusOK {
+		t.Fatalf("create order = %d", status)
+	}
+	return body
+}
+
+// pay completes the payment of the order through the url of its checkout.
+func (f *fixture) pay(t *testing.T, order response) {
+	t.Helper()
+
+	status, _ := f.call(t, strings.TrimPrefix(order.Data.PaymentURL, "/api/v1"), 0)
+	if status != fiber.StatusOK {
+		t.Fatalf("pay = %d", status)
+	}
+}
+
+func (f *fixture) get(t *testing.T, orderId int64) models.Order {
+	t.Helper()
+
+	order, err := f.store.Orders().Get(context.Background(), orderId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+// seats returns the taken seats of the session and the sold tickets of the type.
+func (f *fixture) seats() (int64, int64) {
+	event, _ := f.store.Events().GetByID(context.Background(), f.eventId)
+	ticketTypes, _ := f.store.Tickets().ListTypes(context.Background(), f.eventId)
+	return *event.Locations[0].AttendeesCount, ticketTypes[0].SoldCount
+}
+
+func TestOrderPaid(t *testing.T) {
+	f := newFixture(t, 10)
+	userId := f.store.AddUser(models.User{})
+
+	order := f.order(t, userId)
+	if order.Data.Order.Status != pkg.OrderStatusPending || order.Data.Order.Amount != 100000 || order.Data.PaymentURL == "" {
+		t.Fatalf("order = %+v, want a pending order of 100000 with a payment url", order.Data)
+	}
+
+	f.pay(t, order)
+
+	if got := f.get(t, order.Data.Order.ID); got.Status != pkg.OrderStatusPaid {
+		t.Errorf("order status = %d, want paid", got.Status)
+	}
+	if ok, _ := f.store.Followers().Exists(context.Background(), f.eventId, userId); !ok {
+		t.Error("user does not follow the event after paying")
+	}
+	if seats, sold := f.seats(); seats != 1 || sold != 1 {
+		t.Errorf("seats %d, sold %d, want 1 and 1", seats, sold)
+	}
+
+	status, _ := f.call(t, fmt.Sprint("/orders/create/", f.eventId), userId)
+	if status != fiber.StatusConflict {
+		t.Errorf("second order = %d, want %d", status, fiber.StatusConflict)
+	}
+}
+
+func TestOrderCanceled(t *testing.T) {
+	f := newFixture(t, 1)
+	userId, otherId := f.store.AddUser(models.User{}), f.store.AddUser(models.User{})
+
+	order := f.order(t, userId)
+
+	status, _ := f.call(t, fmt.Sprint("/orders/create/", f.eventId), otherId)
+	if status != fiber.StatusConflict {
+		t.Errorf("order of a held seat = %d, want %d", status, fiber.StatusConflict)
+	}
+
+	status, _ = f.call(t, fmt.Sprint("/orders/cancel/", order.Data.Order.ID), otherId)
+	if status != fiber.StatusNotFound {
+		t.Errorf("cancel of an order of another user = %d, want %d", status, fiber.StatusNotFound)
+	}
+
+	status, _ = f.call(t, fmt.Sprint("/orders/cancel/", order.Data.Order.ID), userId)
+	if status != fiber.StatusOK {
+		t.Fatalf("cancel = %d", status)
+	}
+	if seats, sold := f.seats(); seats != 0 || sold != 0 {
+		t.Errorf("seats %d, sold %d, want the hold released", seats, sold)
+	}
+
+	status, _ = f.call(t, fmt.Sprint("/orders/cancel/", order.Data.Order.ID), userId)
+	if status != fiber.StatusConflict {
+		t.Errorf("second cancel = %d, want %d", status, fiber.StatusConflict)
+	}
+
+	f.order(t, otherId)
+}
+
+func TestLatePayment(t *testing.T) {
+	f := newFixture(t, 10)
+	f.orders.cfg.HoldDuration = time.Nanosecond
+	userId := f.store.AddUser(models.User{})
+
+	order := f.order(t, userId)
+	f.orders.expire(context.Background())
+	if got := f.get(t, order.Data.Order.ID); got.Status != pkg.OrderStatusExpired {
+		t.Fatalf("order status = %d, want expired", got.Status)
+	}
+
+	f.pay(t, order)
+
+	if got := f.get(t, order.Data.Order.ID); got.Status != pkg.OrderStatusPaid {
+		t.Errorf("order status = %d, want paid", got.Status)
+	}
+	if ok, _ := f.store.Followers().Exists(context.Background(), f.eventId, userId); !ok {
+		t.Error("user does not follow the event after paying late")
+	}
+	if seats, sold := f.seats(); seats != 1 || sold != 1 {
+		t.Errorf("seats %d, sold %d, want the seat taken again", seats, sold)
+	}
+}
+
+func TestLatePaymentWithLiveOrder(t *testing.T) {
+	f := newFixture(t, 10)
+	f.orders.cfg.HoldDuration = time.Nanosecond
+	userId := f.store.AddUser(models.User{})
+
+	late := f.order(t, userId)
+	f.orders.expire(context.Background())
+	f.orders.cfg.HoldDuration = time.Hour
+	live := f.order(t, userId)
+
+	f.pay(t, late)
+
+	if got := f.get(t, late.Data.Order.ID); got.Status != pkg.OrderStatusRefunded {
+		t.Errorf("late order status = %d, want refunded", got.Status)
+	}
+	if got := f.get(t, live.Data.Order.ID); got.Status != pkg.OrderStatusPending {
+		t.Errorf("live order status = %d, want pending", got.Status)
+	}
+	if seats, sold := f.seats(); seats != 1 || sold != 1 {
+		t.Errorf("seats %d, sold %d, want only the live hold", seats, sold)
+	}
+}
@@ -0,0 +1,86 @@
+package orders
+
+import (
+	"errors"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/pkg"
+	"math"
+	"time"
+)
+
+var (
+	ErrTicketTypeRequired = errors.New("event has several ticket types, choose one")
+	ErrTicketTypeNotFound = errors.New("ticket type not found")
+	ErrNotOnSale          = errors.New("ticket type is not on sale")
+	ErrSoldOut            = errors.New("ticket type is sold out")
+	ErrInvalidPromo       = errors.New("promo code is not valid")
+	ErrPromoUsedUp        = errors.New("promo code has no uses left")
+)
+
+// price returns the ticket type the user orders and its price in minor units.
+// Events without ticket types are sold at their own price.
+func price(event *models.Event, ticketTypes []models.TicketType, typeId int64, now time.Time) (*models.TicketType, int64, error) {
+	if len(ticketTypes) == 0 {
+		if typeId != 0 {
+			return nil, 0, ErrTicketTypeNotFound
+		}
+		if event.Price <= 0 {
+			return nil, 0, ErrFreeEvent
+		}
+		return nil, minorUnits(event.Price), nil
+	}
+
+	var ticketType *models.TicketType
+	if typeId == 0 {
+		if len(ticketTypes) > 1 {
+			return nil, 0, ErrTicketTypeRequired
+		}
+		ticketType = &ticketTypes[0]
+	} else {
+		for i := range ticketTypes {
+			if ticketTypes[i].ID == typeId {
+				ticketType = &ticketTypes[i]
+			}
+		}
+		if ticketType == nil {
+			return nil, 0, ErrTicketTypeNotFound
+		}
+	}
+
+	if ticketType.SalesStart != nil && now.Before(*ticketType.SalesStart) {
+		return nil, 0, ErrNotOnSale
+	}
+	if ticketType.SalesEnd != nil && !now.Before(*ticketType.SalesEnd) {
+		return nil, 0, ErrNotOnSale
+	}
+	if ticketType.Quota != nil && ticketType.SoldCount >= *ticketType.Quota {
+		return nil, 0, ErrSoldOut
+	}
+
+	return ticketType, minorUnits(ticketType.Price), nil
+}
+
+// discount returns how much the promo code takes off amount, never more than
+// amount itself.
+func discount(amount int64, promo models.PromoCode, now time.Time) (int64, error) {
+	if promo.ExpiresAt != nil && !now.Before(*promo.ExpiresAt) {
+		return 0, ErrInvalidPromo
+	}
+	if promo.MaxUses != nil && promo.UsedCount >= *promo.MaxUses {
+		return 0, ErrPromoUsedUp
+	}
+
+	var d int64
+	switch promo.Kind {
+	case pkg.PromoKindPercent:
+		d = amount * int64(promo.Value) / 100
+	case pkg.PromoKindFixed:
+		d = minorUnits(promo.Value)
+	}
+
+	return min(d, amount), nil
+}
+
+func minorUnits(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
@@ -0,0 +1,183 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"github.com/NuEventTeam/events/internal/features/event/followers"
+	"github.com/NuEventTeam/events/internal/features/payment"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+	"slices"
+	"time"
+)
+
+// WebhookHandler receives the payment notifications of the provider.
+func (o *Orders) WebhookHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		return o.webhook(ctx, ctx.Body(), func(key string) string { return ctx.Get(key) })
+	}
+}
+
+// FakePayHandler completes a payment of the fake provider, with the failed
+// status query it fails it instead.
+func (o *Orders) FakePayHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		fake, ok := o.provider.(*payment.Fake)
+		if !ok || !o.dev {
+			return pkg.Error(ctx, fiber.StatusNotFound, "fake payments are disabled")
+		}
+
+		status := ctx.Query("status", payment.StatusPaid)
+		if status != payment.StatusPaid && status != payment.StatusFailed {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "status must be paid or failed")
+		}
+
+		body, signature, err := fake.Webhook(ctx.Params("paymentId"), status)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return o.webhook(ctx, body, func(string) string { return signature })
+	}
+}
+
+func (o *Orders) webhook(ctx *fiber.Ctx, body []byte, header func(string) string) error {
+	n, err := o.provider.ParseWebhook(body, header)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return pkg.Error(ctx, fiber.StatusUnauthorized, err.Error(), err)
+		}
+		return pkg.Error(ctx, fiber.StatusBadRequest, "invalid notification", err)
+	}
+
+	err = o.HandleNotification(ctx.Context(), n)
+	if err != nil {
+		if errors.Is(err, ErrOrderNotFound) {
+			return pkg.Error(ctx, fiber.StatusNotFound, err.Error(), err)
+		}
+		return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+	}
+
+	return pkg.Success(ctx, nil)
+}
+
+var errLatePayment = errors.New("payment arrived after the seat was released")
+
+// HandleNotification applies the outcome of a payment to its order. Providers
+// may repeat notifications, so an order is only changed once.
+func (o *Orders) HandleNotification(ctx context.Context, n payment.Notification) error {
+	order, err := o.store.Orders().GetByPayment(ctx, n.PaymentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrOrderNotFound
+		}
+		return err
+	}
+
+	switch n.Status {
+	case payment.StatusPaid:
+		err = o.paid(ctx, order)
+		if errors.Is(err, errLatePayment) {
+			return o.refund(ctx, order, pkg.OrderStatusExpired, pkg.OrderStatusCanceled)
+		}
+		return err
+	case payment.StatusFailed:
+		_, err = o.release(ctx, order, pkg.OrderStatusCanceled)
+		return err
+	}
+
+	return nil
+}
+
+// paid makes the user of the order a follower. An order whose hold already
+// ended needs a seat and a ticket of its type again, errLatePayment is
+// returned when there is none or the user placed another order meanwhile.
+func (o *Orders) paid(ctx context.Context, order models.Order) error {
+	return o.store.WithTx(ctx, func(tx repository.Store) error {
+		ok, err := tx.Orders().SetStatus(ctx, order.ID, pkg.OrderStatusPaid, pkg.OrderStatusPending)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			// only one order of the user for the event can be live
+			live, err := tx.Orders().GetByUser(ctx, order.EventID, order.UserID, pkg.OrderStatusPending, pkg.OrderStatusPaid)
+			if err == nil && live.ID != order.ID {
+				return errLatePayment
+			}
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+
+			ok, err = tx.Orders().SetStatus(ctx, order.ID, pkg.OrderStatusPaid, pkg.OrderStatusExpired, pkg.OrderStatusCanceled)
+			if err != nil || !ok {
+				return err
+			}
+
+			event, err := tx.Events().GetByID(ctx, order.EventID)
+			if err != nil {
+				return err
+			}
+			locations, err := tx.Events().GetLocations(ctx, order.EventID)
+			if err != nil {
+				return err
+			}
+			i := slices.IndexFunc(locations, func(l models.Location) bool { return l.ID == order.LocationID })
+			if i < 0 || !followers.SessionOpen(event, locations[i], time.Now()) {
+				return errLatePayment
+			}
+
+			ok, err = tx.Events().ReserveSeat(ctx, order.LocationID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errLatePayment
+			}
+
+			if order.TicketTypeID != nil {
+				ok, err = tx.Tickets().ReserveType(ctx, *order.TicketTypeID)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return errLatePayment
+				}
+			}
+
+			// the discount was paid already, so the limit of the code is not
+			// checked again
+			if order.PromoCodeID != nil {
+				_, err = tx.Tickets().RedeemPromo(ctx, *order.PromoCodeID)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err = followers.Register(ctx, tx, order.EventID, order.LocationID, order.UserID)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return tx.Events().ChangeAttendeesCount(ctx, order.LocationID, -1)
+		}
+		return err
+	})
+}
+
+// refund gives the money of the order back when it is in one of the from
+// statuses. The order is marked first, so a refund is never sent twice.
+func (o *Orders) refund(ctx context.Context, order models.Order, from ...int) error {
+	if order.PaymentID == nil {
+		return nil
+	}
+
+	return o.store.WithTx(ctx, func(tx repository.Store) error {
+		ok, err := tx.Orders().SetStatus(ctx, order.ID, pkg.OrderStatusRefunded, from...)
+		if err != nil || !ok {
+			return err
+		}
+
+		return o.provider.Refund(ctx, *order.PaymentID, order.Amount, order.Currency)
+	})
+}
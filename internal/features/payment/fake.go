@@ -0,0 +1,78 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/bytedance/sonic"
+	"github.com/oklog/ulid/v2"
+	"log"
+)
+
+const SignatureHeader = "X-Signature"
+
+// Fake is a provider for local runs. Its payments are completed by calling
+// the url of the checkout, which sends the webhook a provider would.
+type Fake struct {
+	secret []byte
+}
+
+func NewFake(secret string) *Fake {
+	return &Fake{secret: []byte(secret)}
+}
+
+func (f *Fake) Name() string {
+	return ProviderFake
+}
+
+func (f *Fake) CreatePayment(ctx context.Context, p Payment) (Checkout, error) {
+	id := "fake_" + ulid.Make().String()
+	return Checkout{
+		PaymentID: id,
+		URL:       "/api/v1/payments/fake/pay/" + id,
+	}, nil
+}
+
+// Webhook returns the body and signature of the webhook for the payment.
+func (f *Fake) Webhook(paymentId, status string) ([]byte, string, error) {
+	body, err := sonic.ConfigFastest.Marshal(fakeNotification{PaymentID: paymentId, Status: status})
+	if err != nil {
+		return nil, "", err
+	}
+	return body, f.sign(body), nil
+}
+
+type fakeNotification struct {
+	PaymentID string `json:"paymentId"`
+	Status    string `json:"status"`
+}
+
+func (f *Fake) ParseWebhook(body []byte, header func(string) string) (Notification, error) {
+	signature, err := hex.DecodeString(header(SignatureHeader))
+	if err != nil || !hmac.Equal(signature, f.mac(body)) {
+		return Notification{}, ErrInvalidSignature
+	}
+
+	var n fakeNotification
+	if err := sonic.ConfigFastest.Unmarshal(body, &n); err != nil {
+		return Notification{}, err
+	}
+
+	return Notification{PaymentID: n.PaymentID, Status: n.Status}, nil
+}
+
+func (f *Fake) Refund(ctx context.Context, paymentId string, amount int64, currency string) error {
+	log.Println("fake refund", paymentId, amount, currency)
+	return nil
+}
+
+func (f *Fake) sign(body []byte) string {
+	return hex.EncodeToString(f.mac(body))
+}
+
+func (f *Fake) mac(body []byte) []byte {
+	h := hmac.New(sha256.New, f.secret)
+	h.Write(body)
+	return h.Sum(nil)
+}
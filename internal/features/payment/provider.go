@@ -0,0 +1,59 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"github.com/NuEventTeam/events/internal/config"
+	"log"
+)
+
+const (
+	ProviderFake = "fake"
+)
+
+const (
+	StatusPaid   = "paid"
+	StatusFailed = "failed"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+type Payment struct {
+	OrderID     int64
+	Amount      int64
+	Currency    string
+	Description string
+}
+
+// Checkout is a payment started at the provider, the user pays it at URL.
+type Checkout struct {
+	PaymentID string
+	URL       string
+}
+
+// Notification is the outcome of a payment reported by a webhook.
+type Notification struct {
+	PaymentID string
+	Status    string
+}
+
+// Provider takes the payments of orders. Amounts are in minor units of the
+// currency.
+type Provider interface {
+	Name() string
+	CreatePayment(ctx context.Context, p Payment) (Checkout, error)
+	// ParseWebhook verifies and reads a webhook request of the provider, it
+	// returns ErrInvalidSignature for requests the provider did not send.
+	ParseWebhook(body []byte, header func(string) string) (Notification, error)
+	Refund(ctx context.Context, paymentId string, amount int64, currency string) error
+}
+
+func New(cfg config.Payments) Provider {
+	switch cfg.Provider {
+	case ProviderFake, "":
+		return NewFake(cfg.WebhookSecret)
+	default:
+		log.Fatalf("unknown payment provider %q", cfg.Provider)
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5"
 	"log"
+	"slices"
 	"time"
 )
 
@@ -37,6 +38,10 @@ type SearchArgs struct {
 	MinAge     int64       `json:"minAge"`
 	Sort       []Sort      `json:"sort"`
 	LastId     int64       `json:"lastId"`
+	// EligibleOnly hides the events whose age range excludes the caller, or
+	// Age for anonymous callers.
+	EligibleOnly bool   `json:"eligibleOnly"`
+	Age          *int64 `json:"age"`
 }
 
 func SearchEvents(db *database.Database) fiber.Handler {
@@ -47,6 +52,23 @@ func SearchEvents(db *database.Database) fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 		}
 
+		if args.EligibleOnly {
+			userId, _ := ctx.Locals("userId").(int64)
+			if userId != 0 {
+				user, err := database.GetUser(ctx.Context(), db.GetDb(), database.GetUserArgss{UserID: &userId})
+				if err != nil {
+					return pkg.Error(ctx, fiber.StatusInternalServerError, "oops something went wrong", err)
+				}
+				if !user.BirthDate.IsZero() {
+					age := pkg.Age(user.BirthDate, time.Now())
+					args.Age = &age
+				}
+			}
+			if args.Age == nil {
+				return pkg.Error(ctx, fiber.StatusBadRequest, "age is needed to hide events you are not eligible for")
+			}
+		}
+
 		eventsMap, eventIds, err := searchForEvent(ctx.Context(), db.GetDb(), args)
 		if err != nil {
 			return pkg.Error(ctx, fiber.StatusInternalServerError, "oops something went wrong", err)
@@ -74,6 +96,7 @@ func SearchEvents(db *database.Database) fiber.Handler {
 }
 
 type Location struct {
+	ID             int64  `json:"locationId"`
 	Address        string `json:"address"`
 	Log            string `json:"lon"`
 	Lat            string `json:"lat"`
@@ -81,11 +104,18 @@ type Location struct {
 	Seats          *int64 `json:"seats"`
 }
 
+type Session struct {
+	Location
+	StartsAt *types.DateTime `json:"startsAt"`
+	EndsAt   *types.DateTime `json:"endsAt"`
+}
+
 type Event struct {
 	Id            int64           `json:"eventId"`
 	Title         string          `json:"title"`
 	Description   string          `json:"description"`
 	Location      Location        `json:"location"`
+	Sessions      []Session       `json:"sessions"`
 	Images        []string        `json:"images"`
 	Categories    []Categories    `json:"categories"`
 	Author        User            `json:"author"`
@@ -131,26 +161,33 @@ func getImages(ctx context.Context, db database.DBTX, events map[int64]Event, ev
 
 func searchForEvent(ctx context.Context, db database.DBTX, params SearchArgs) (map[int64]Event, []int64, error) {
 	query := qb.Select("distinct events.id,title,description,age_min, like_count, events.follower_count, username,firstname, lastname,user_id,profile_image," +
-		"address, longitude, latitude, seats, attendees_count, starts_at, ends_at").
+		"event_locations.id, address, longitude, latitude, seats, attendees_count, starts_at, ends_at").
 		From("events").
 		InnerJoin("event_locations on events.id = event_locations.event_id").
 		InnerJoin("event_managers on events.id = event_managers.event_id").
 		InnerJoin("event_role_permissions on event_managers.role_id = event_role_permissions.role_id").
 		InnerJoin("event_categories on events.id = event_categories.event_id").
-		InnerJoin("users on event_managers.user_id = users.id")
+		InnerJoin("users on event_managers.user_id = users.id").
+		Where(sq.Eq{"events.deleted_at": nil}).
+		Where(sq.Eq{"event_locations.deleted_at": nil})
 	if params.MinAge != 0 {
 		query = query.Where(sq.GtOrEq{"age_min": params.MinAge})
 	}
 
+	if params.EligibleOnly && params.Age != nil {
+		query = query.Where(sq.Or{sq.Eq{"age_min": nil}, sq.LtOrEq{"age_min": *params.Age}}).
+			Where(sq.Or{sq.Eq{"age_max": nil}, sq.Eq{"age_max": 0}, sq.GtOrEq{"age_max": *params.Age}})
+	}
+
 	if len(params.Categories) > 0 {
 		query = query.Where(sq.Eq{"event_categories.event_id": params.Categories})
 	}
 
 	if params.From != nil {
-		query = query.Where(sq.GtOrEq{"event_locations.start_at": time.Time(*params.From)})
+		query = query.Where(sq.GtOrEq{"event_locations.starts_at": time.Time(*params.From)})
 	}
 	if params.To != nil {
-		query = query.Where(sq.LtOrEq{"event_locations.start_at": time.Time(*params.To)})
+		query = query.Where(sq.LtOrEq{"event_locations.starts_at": time.Time(*params.To)})
 	}
 
 	if params.Text == "" {
@@ -200,7 +237,7 @@ func searchForEvent(ctx context.Context, db database.DBTX, params SearchArgs) (m
 		)
 
 		err := rows.Scan(&e.Id, &e.Title, &e.Description, &e.AgeMin, &e.LikeCount, &e.FollowerCount, &e.Author.Username, &e.Author.Firstname, &e.Author.Lastname, &e.Author.ID, &e.Author.ProfileImage,
-			&e.Location.Address, &e.Location.Log, &e.Location.Lat, &e.Location.Seats, &e.Location.AttendeesCount, &startsAt, &endsAt,
+			&e.Location.ID, &e.Location.Address, &e.Location.Log, &e.Location.Lat, &e.Location.Seats, &e.Location.AttendeesCount, &startsAt, &endsAt,
 			&e.Distance)
 		if err != nil {
 			return nil, nil, err
@@ -212,7 +249,23 @@ func searchForEvent(ctx context.Context, db database.DBTX, params SearchArgs) (m
 		if !endsAt.IsZero() {
 			e.EndsAt = e.EndsAt.FromTime(endsAt)
 		}
+		session := Session{Location: e.Location, StartsAt: e.StartsAt, EndsAt: e.EndsAt}
+
+		// an event has a row per session, the earliest one is shown as its location
+		if prev, ok := events[e.Id]; ok {
+			if slices.ContainsFunc(prev.Sessions, func(s Session) bool { return s.ID == session.ID }) {
+				continue
+			}
+			prev.Sessions = append(prev.Sessions, session)
+			if startsAt != nil && (prev.StartsAt == nil || startsAt.Before(time.Time(*prev.StartsAt))) {
+				prev.Location, prev.StartsAt, prev.EndsAt = e.Location, e.StartsAt, e.EndsAt
+				prev.Distance = e.Distance
+			}
+			events[e.Id] = prev
+			continue
+		}
 
+		e.Sessions = []Session{session}
 		events[e.Id] = e
 		eventIds = append(eventIds, e.Id)
 
@@ -0,0 +1,99 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// legacyKeyID is the key of tokens issued without a kid header.
+const legacyKeyID = ""
+
+type key struct {
+	id     string
+	method jwt.SigningMethod
+	sign   interface{}
+	verify interface{}
+}
+
+func loadKey(cfg config.JWTKey) (key, error) {
+	k := key{id: cfg.ID}
+
+	switch cfg.Algorithm {
+	case AlgHS256, "":
+		if cfg.Secret == "" {
+			return k, fmt.Errorf("jwt key %q: HS256 needs a secret", cfg.ID)
+		}
+		k.method = jwt.SigningMethodHS256
+		k.sign = []byte(cfg.Secret)
+		k.verify = []byte(cfg.Secret)
+		return k, nil
+	case AlgRS256:
+		k.method = jwt.SigningMethodRS256
+	case AlgEdDSA:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return k, fmt.Errorf("jwt key %q: unsupported algorithm %s", cfg.ID, cfg.Algorithm)
+	}
+
+	if cfg.PrivateKeyFile != "" {
+		pem, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return k, fmt.Errorf("jwt key %q: %w", cfg.ID, err)
+		}
+
+		var private crypto.Signer
+		if k.method == jwt.SigningMethodRS256 {
+			private, err = jwt.ParseRSAPrivateKeyFromPEM(pem)
+		} else {
+			var parsed crypto.PrivateKey
+			parsed, err = jwt.ParseEdPrivateKeyFromPEM(pem)
+			if err == nil {
+				private, _ = parsed.(ed25519.PrivateKey)
+			}
+		}
+		if err != nil {
+			return k, fmt.Errorf("jwt key %q: %w", cfg.ID, err)
+		}
+		if private == nil {
+			return k, fmt.Errorf("jwt key %q: not an ed25519 private key", cfg.ID)
+		}
+
+		k.sign = private
+		k.verify = private.Public()
+	}
+
+	if cfg.PublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return k, fmt.Errorf("jwt key %q: %w", cfg.ID, err)
+		}
+
+		var public interface{}
+		if k.method == jwt.SigningMethodRS256 {
+			public, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		} else {
+			public, err = jwt.ParseEdPublicKeyFromPEM(pem)
+		}
+		if err != nil {
+			return k, fmt.Errorf("jwt key %q: %w", cfg.ID, err)
+		}
+
+		k.verify = public
+	}
+
+	if k.verify == nil {
+		return k, fmt.Errorf("jwt key %q: needs a private or public key file", cfg.ID)
+	}
+
+	return k, nil
+}
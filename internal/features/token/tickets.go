@@ -0,0 +1,147 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/NuEventTeam/events/pkg/ticketcode"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/oklog/ulid/v2"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TicketSigner issues the signed ticket codes, which door staff can verify
+// offline with the keys published by PublicKeys.
+type TicketSigner struct {
+	kid     string
+	private ed25519.PrivateKey
+	keys    map[string]ed25519.PublicKey
+	grace   time.Duration
+}
+
+// NewTicketSigner only generates a key without a private key file in dev,
+// elsewhere tickets would stop verifying on every restart.
+func NewTicketSigner(cfg config.Tickets, dev bool) (*TicketSigner, error) {
+	s := &TicketSigner{
+		kid:   cfg.KeyID,
+		keys:  map[string]ed25519.PublicKey{},
+		grace: cfg.Grace,
+	}
+	if s.kid == "" || strings.Contains(s.kid, ".") {
+		return nil, fmt.Errorf("ticket key id %q must be set and have no dots", s.kid)
+	}
+
+	if cfg.PrivateKeyFile == "" {
+		if !dev {
+			return nil, fmt.Errorf("ticket key %q: private key file is required", s.kid)
+		}
+		log.Println("tickets: no private key file, tickets are signed with a generated key")
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		s.private = private
+	} else {
+		pem, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("ticket key %q: %w", s.kid, err)
+		}
+		parsed, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("ticket key %q: %w", s.kid, err)
+		}
+		private, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("ticket key %q: not an ed25519 private key", s.kid)
+		}
+		s.private = private
+	}
+	s.keys[s.kid] = s.private.Public().(ed25519.PublicKey)
+
+	for kid, file := range cfg.RetiredKeys {
+		if _, ok := s.keys[kid]; ok {
+			return nil, fmt.Errorf("duplicate ticket key %q", kid)
+		}
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("ticket key %q: %w", kid, err)
+		}
+		parsed, err := jwt.ParseEdPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("ticket key %q: %w", kid, err)
+		}
+		public, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("ticket key %q: not an ed25519 public key", kid)
+		}
+		s.keys[kid] = public
+	}
+
+	return s, nil
+}
+
+func MustNewTicketSigner(cfg config.Tickets, dev bool) *TicketSigner {
+	s, err := NewTicketSigner(cfg, dev)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return s
+}
+
+// Grace is how long tickets stay valid after their session ends.
+func (s *TicketSigner) Grace() time.Duration {
+	return s.grace
+}
+
+// Sign returns the code of the ticket, its id and issue time are set here.
+func (s *TicketSigner) Sign(t ticketcode.Ticket) string {
+	t.ID = ulid.Make()
+	t.IssuedAt = time.Now()
+	return ticketcode.Encode(t, s.kid, s.private)
+}
+
+func (s *TicketSigner) Verify(code string) (ticketcode.Ticket, error) {
+	return ticketcode.Verify(code, func(kid string) (ed25519.PublicKey, bool) {
+		key, ok := s.keys[kid]
+		return key, ok
+	}, time.Now())
+}
+
+// JWK is a public key in the JSON Web Key format, x is the raw key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	X         string `json:"x"`
+}
+
+// PublicKeys returns every key tickets may be signed with, the active one
+// first.
+func (s *TicketSigner) PublicKeys() []JWK {
+	keys := make([]JWK, 0, len(s.keys))
+	for kid, key := range s.keys {
+		keys = append(keys, JWK{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			KeyID:     kid,
+			Use:       "sig",
+			Algorithm: AlgEdDSA,
+			X:         base64.RawURLEncoding.EncodeToString(key),
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i].KeyID == s.kid) != (keys[j].KeyID == s.kid) {
+			return keys[i].KeyID == s.kid
+		}
+		return keys[i].KeyID < keys[j].KeyID
+	})
+	return keys
+}
@@ -0,0 +1,25 @@
+package token
+
+import (
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/NuEventTeam/events/pkg/ticketcode"
+	"testing"
+	"time"
+)
+
+func TestNewTicketSignerWithoutKeyFile(t *testing.T) {
+	cfg := config.Tickets{KeyID: "t1"}
+
+	if _, err := NewTicketSigner(cfg, false); err == nil {
+		t.Error("NewTicketSigner() outside dev without a key file succeeded")
+	}
+
+	s, err := NewTicketSigner(cfg, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := s.Sign(ticketcode.Ticket{EventID: 1, ExpiresAt: time.Now().Add(time.Hour)})
+	if _, err := s.Verify(code); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/oklog/ulid/v2"
+	"log"
+	"time"
+)
+
+const (
+	TypeAccess = "access"
+	TypeTicket = "ticket"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+	ErrWrongType  = errors.New("unexpected token type")
+)
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Type      string `json:"typ"`
+	UserID    int64  `json:"userId"`
+	UserAgent string `json:"userAgent,omitempty"`
+	SessionID string `json:"sessionId,omitempty"`
+	EventID   int64  `json:"eventId,omitempty"`
+	// LocationID is the session the ticket admits to.
+	LocationID int64 `json:"locationId,omitempty"`
+	// TicketTypeID and TicketType are the tier the attendee bought.
+	TicketTypeID int64  `json:"ticketTypeId,omitempty"`
+	TicketType   string `json:"ticketType,omitempty"`
+}
+
+// Service issues and verifies every JWT of the application: access tokens
+// for the http api and chat, and event tickets.
+type Service struct {
+	keys       map[string]key
+	active     key
+	issuer     string
+	audience   string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	ticketTTL  time.Duration
+	leeway     time.Duration
+	// legacyUntil ends the window of tokens without type, issuer and audience.
+	legacyUntil time.Time
+}
+
+func New(cfg config.JWT) (*Service, error) {
+	s := &Service{
+		keys:        map[string]key{},
+		issuer:      cfg.Issuer,
+		audience:    cfg.Audience,
+		accessTTL:   orDefault(cfg.Expiry, 24*time.Hour),
+		refreshTTL:  orDefault(cfg.RefreshExpiry, 7*24*time.Hour),
+		ticketTTL:   orDefault(cfg.TicketExpiry, 24*time.Hour),
+		leeway:      cfg.Leeway,
+		legacyUntil: cfg.LegacyUntil,
+	}
+
+	if cfg.Secret != "" {
+		s.keys[legacyKeyID] = key{
+			id:     legacyKeyID,
+			method: jwt.SigningMethodHS256,
+			sign:   []byte(cfg.Secret),
+			verify: []byte(cfg.Secret),
+		}
+	}
+
+	for _, kc := range cfg.Keys {
+		if kc.ID == legacyKeyID {
+			return nil, fmt.Errorf("jwt keys need an id")
+		}
+		if _, ok := s.keys[kc.ID]; ok {
+			return nil, fmt.Errorf("duplicate jwt key %q", kc.ID)
+		}
+
+		k, err := loadKey(kc)
+		if err != nil {
+			return nil, err
+		}
+		s.keys[kc.ID] = k
+	}
+
+	active, ok := s.keys[cfg.ActiveKey]
+	if !ok {
+		return nil, fmt.Errorf("%w: active key %q", ErrUnknownKey, cfg.ActiveKey)
+	}
+	if active.sign == nil {
+		return nil, fmt.Errorf("active jwt key %q has no private key", cfg.ActiveKey)
+	}
+	s.active = active
+
+	return s, nil
+}
+
+func MustNew(cfg config.JWT) *Service {
+	s, err := New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return s
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+func (s *Service) RefreshTTL() time.Duration {
+	return s.refreshTTL
+}
+
+func (s *Service) TicketTTL() time.Duration {
+	return s.ticketTTL
+}
+
+func (s *Service) IssueAccess(userId int64, userAgent, sessionId *string) (string, error) {
+	claims := s.claims(TypeAccess, userId, s.accessTTL)
+	if userAgent != nil {
+		claims.UserAgent = *userAgent
+	}
+	if sessionId != nil {
+		claims.SessionID = *sessionId
+	}
+	return s.sign(claims)
+}
+
+func (s *Service) claims(typ string, userId int64, ttl time.Duration) Claims {
+	now := time.Now()
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   fmt.Sprint(userId),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			ID:        ulid.Make().String(),
+		},
+		Type:   typ,
+		UserID: userId,
+	}
+	if s.audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.audience}
+	}
+	return claims
+}
+
+func (s *Service) sign(claims Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.method, claims)
+	if s.active.id != legacyKeyID {
+		token.Header["kid"] = s.active.id
+	}
+	return token.SignedString(s.active.sign)
+}
+
+func (s *Service) ParseAccess(tokenString string) (*Claims, error) {
+	return s.parse(tokenString, TypeAccess)
+}
+
+func (s *Service) ParseTicket(tokenString string) (*Claims, error) {
+	return s.parse(tokenString, TypeTicket)
+}
+
+func (s *Service) parse(tokenString, typ string) (*Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(s.leeway),
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}),
+	}
+	if s.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.issuer))
+	}
+	if s.audience != "" {
+		opts = append(opts, jwt.WithAudience(s.audience))
+	}
+
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, s.keyFunc, opts...)
+	if err != nil {
+		if legacy, lerr := s.parseLegacy(tokenString, typ); lerr == nil {
+			return legacy, nil
+		}
+		return nil, err
+	}
+
+	if claims.Type != typ {
+		return nil, ErrWrongType
+	}
+
+	return &claims, nil
+}
+
+// parseLegacy accepts the tokens issued before they carried their type,
+// issuer and audience until the legacy window ends. They were signed with the
+// secret and had no kid, tickets are told apart by their event.
+func (s *Service) parseLegacy(tokenString, typ string) (*Claims, error) {
+	if !time.Now().Before(s.legacyUntil) {
+		return nil, ErrWrongType
+	}
+
+	var claims Claims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, s.keyFunc,
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(s.leeway),
+		jwt.WithValidMethods([]string{AlgHS256}))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := token.Header["kid"]; ok || claims.Type != "" || claims.Issuer != "" || len(claims.Audience) > 0 {
+		return nil, ErrWrongType
+	}
+
+	claims.Type = TypeAccess
+	if claims.EventID != 0 {
+		claims.Type = TypeTicket
+	}
+	if claims.Type != typ {
+		return nil, ErrWrongType
+	}
+
+	return &claims, nil
+}
+
+// keyFunc picks the verification key by kid and rejects tokens whose alg
+// does not match the key, so a public key is never used as an HMAC secret.
+func (s *Service) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	k, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("%w: %s", jwt.ErrTokenSignatureInvalid, token.Method.Alg())
+	}
+
+	return k.verify, nil
+}
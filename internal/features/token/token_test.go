@@ -0,0 +1,76 @@
+package token
+
+import (
+	"errors"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"testing"
+	"time"
+)
+
+// legacyToken signs claims the way tokens were issued before they carried
+// their type, issuer and audience.
+func legacyToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestLegacyTokens(t *testing.T) {
+	cfg := config.JWT{Secret: "secret", Issuer: "nuevent", Audience: "nuevent-api", LegacyUntil: time.Now().Add(time.Hour)}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	access := legacyToken(t, "secret", jwt.MapClaims{"userId": 7, "userAgent": "app", "exp": exp})
+	ticket := legacyToken(t, "secret", jwt.MapClaims{"userId": 7, "eventId": 3, "exp": exp})
+
+	claims, err := s.ParseAccess(access)
+	if err != nil {
+		t.Fatalf("ParseAccess() of a legacy token: %v", err)
+	}
+	if claims.UserID != 7 || claims.UserAgent != "app" || claims.SessionID != "" {
+		t.Errorf("claims = %+v, want user 7 with the user agent and no session", claims)
+	}
+
+	if claims, err := s.ParseTicket(ticket); err != nil || claims.EventID != 3 {
+		t.Errorf("ParseTicket() of a legacy ticket = %+v, %v", claims, err)
+	}
+	if _, err := s.ParseAccess(ticket); err == nil {
+		t.Error("ParseAccess() accepted a legacy ticket")
+	}
+	if _, err := s.ParseTicket(access); err == nil {
+		t.Error("ParseTicket() accepted a legacy access token")
+	}
+
+	expired := legacyToken(t, "secret", jwt.MapClaims{"userId": 7, "exp": time.Now().Add(-time.Hour).Unix()})
+	if _, err := s.ParseAccess(expired); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Errorf("ParseAccess() of an expired legacy token error = %v, want expired", err)
+	}
+	if _, err := s.ParseAccess(legacyToken(t, "other", jwt.MapClaims{"userId": 7, "exp": exp})); err == nil {
+		t.Error("ParseAccess() accepted a legacy token with another secret")
+	}
+
+	current, err := s.IssueAccess(7, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ParseAccess(current); err != nil {
+		t.Errorf("ParseAccess() of a current token: %v", err)
+	}
+
+	cfg.LegacyUntil = time.Now().Add(-time.Hour)
+	s, err = New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ParseAccess(access); err == nil {
+		t.Error("ParseAccess() accepted a legacy token after the window")
+	}
+}
@@ -64,22 +64,25 @@ func (u User) CreateMobileUserHandler() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "user does not exists")
 		}
 
+		if verr := u.assets.ValidateImages(form.File["images"], 1); verr != nil {
+			return pkg.Error(ctx, verr.Status, verr.Violations, verr)
+		}
+
 		var image assets.Image
 
 		for _, f := range form.File["images"] {
 
 			file, err := f.Open()
 			if err != nil {
-				log.Println("cannot open the image file")
-				break
+				return pkg.Error(ctx, fiber.StatusBadRequest, "cannot open the image file", err)
 			}
+			defer file.Close()
 
 			filename := pkg.UserNamespace + "/" + fmt.Sprintf("%d", userId) + "/" + ulid.Make().String() + path.Ext(f.Filename)
 
-			img, err := assets.NewImage(filename, file, assets.WithWidthAndHeight(500, 500))
+			img, err := assets.NewImage(filename, file, assets.WithRenditions())
 			if err != nil {
-				log.Println("while uploading image")
-				break
+				return pkg.Error(ctx, fiber.StatusBadRequest, "could not read image", err)
 			}
 			image = img
 
@@ -177,22 +180,25 @@ func (u User) CreateUserHandler() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, err.Error(), err)
 		}
 
+		if verr := u.assets.ValidateImages(form.File["images"], 1); verr != nil {
+			return pkg.Error(ctx, verr.Status, verr.Violations, verr)
+		}
+
 		var image assets.Image
 
 		for _, f := range form.File["images"] {
 
 			file, err := f.Open()
 			if err != nil {
-				log.Println("cannot open the image file")
-				break
+				return pkg.Error(ctx, fiber.StatusBadRequest, "cannot open the image file", err)
 			}
+			defer file.Close()
 
 			filename := pkg.UserNamespace + "/" + fmt.Sprintf("%d", userId) + "/" + ulid.Make().String() + path.Ext(f.Filename)
 
-			img, err := assets.NewImage(filename, file, assets.WithWidthAndHeight(500, 500))
+			img, err := assets.NewImage(filename, file, assets.WithRenditions())
 			if err != nil {
-				log.Println("while uploading image")
-				break
+				return pkg.Error(ctx, fiber.StatusBadRequest, "could not read image", err)
 			}
 			image = img
 			break
@@ -261,14 +267,19 @@ func (u User) CreateUser(ctx context.Context, user models.User) error {
 		return err
 	}
 
-	u.assets.Upload(ctx, user.Image)
-
 	err = database.AddUserPreference(ctx, tx, user.ID, user.Preferences...)
 	if err != nil {
 		return err
 	}
 
+	err = u.assets.Upload(ctx, user.Image)
+	if err != nil {
+		u.assets.Discard(ctx, user.Image)
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
+		u.assets.Discard(ctx, user.Image)
 		return err
 	}
 
@@ -0,0 +1,51 @@
+package user
+
+import (
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DeviceRequest struct {
+	Token    string  `json:"token"`
+	Platform *string `json:"platform"`
+}
+
+func (u *User) RegisterDeviceHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request DeviceRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+		if request.Token == "" {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "token is missing")
+		}
+
+		userId := ctx.Locals("userId").(int64)
+
+		err := database.SaveDevice(ctx.Context(), u.db.GetDb(), userId, request.Token, request.Platform)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
+
+func (u *User) UnregisterDeviceHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var request DeviceRequest
+		if err := ctx.BodyParser(&request); err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, "invalid json", err)
+		}
+
+		userId := ctx.Locals("userId").(int64)
+
+		err := database.DeleteDevice(ctx.Context(), u.db.GetDb(), userId, request.Token)
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusInternalServerError, "something went wrong", err)
+		}
+
+		return pkg.Success(ctx, nil)
+	}
+}
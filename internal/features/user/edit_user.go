@@ -9,7 +9,6 @@ import (
 	"github.com/bytedance/sonic"
 	"github.com/gofiber/fiber/v2"
 	"github.com/oklog/ulid/v2"
-	"log"
 	"path"
 	"time"
 )
@@ -49,22 +48,25 @@ func (u User) UpdateUserHandler() fiber.Handler {
 			return pkg.Error(ctx, fiber.StatusBadRequest, "user does not exists")
 		}
 
+		if verr := u.assets.ValidateImages(form.File["images"], 1); verr != nil {
+			return pkg.Error(ctx, verr.Status, verr.Violations, verr)
+		}
+
 		var image assets.Image
 
 		for _, f := range form.File["images"] {
 
 			file, err := f.Open()
 			if err != nil {
-				log.Println("cannot open the image file")
-				break
+				return pkg.Error(ctx, fiber.StatusBadRequest, "cannot open the image file", err)
 			}
+			defer file.Close()
 
 			filename := pkg.UserNamespace + "/" + fmt.Sprintf("%d", userId) + "/" + ulid.Make().String() + path.Ext(f.Filename)
 
-			img, err := assets.NewImage(filename, file, assets.WithWidthAndHeight(500, 500))
+			img, err := assets.NewImage(filename, file, assets.WithRenditions())
 			if err != nil {
-				log.Println("while uploading image")
-				break
+				return pkg.Error(ctx, fiber.StatusBadRequest, "could not read image", err)
 			}
 			image = img
 
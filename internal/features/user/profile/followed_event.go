@@ -49,6 +49,9 @@ type FollowedEvent struct {
 	Price          *float64        `json:"price"`
 	AttendeesCount int64           `json:"attendeesCount"`
 	LikesCount     int64           `json:"likesCount"`
+	Status         *int            `json:"status,omitempty"`
+	// LocationId is the session the user registered for.
+	LocationId int64 `json:"locationId,omitempty"`
 }
 
 func GetFollowedEvents(ctx context.Context, db database.DBTX, userId, lastEventId int64) (map[int64]FollowedEvent, error) {
@@ -61,11 +64,13 @@ events.description,
 					event_locations.address,
 					event_locations.starts_at,
 					event_locations.ends_at,
-					event_locations.attendees_count`).
+					event_locations.attendees_count,
+					event_locations.id`).
 		From("events").
-		InnerJoin("event_locations on event_locations.event_id = events.id").
 		InnerJoin("event_followers on event_followers.event_id = events.id").
+		InnerJoin("event_locations on event_locations.id = event_followers.location_id").
 		Where(sq.GtOrEq{"event_locations.starts_at": time.Now()}).
+		Where(sq.NotEq{"events.status": pkg.EventStatusCanceled}).
 		Where(sq.Eq{"event_followers.user_id": userId})
 
 	if lastEventId != 0 {
@@ -97,7 +102,7 @@ events.description,
 			e time.Time
 		)
 
-		err := rows.Scan(&f.ID, &f.Title, &f.Description, &f.LikesCount, &f.Price, &f.Address, &s, &e, &f.AttendeesCount)
+		err := rows.Scan(&f.ID, &f.Title, &f.Description, &f.LikesCount, &f.Price, &f.Address, &s, &e, &f.AttendeesCount, &f.LocationId)
 		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
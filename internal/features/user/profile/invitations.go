@@ -0,0 +1,20 @@
+package user_profile
+
+import (
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetManagerInvitationsHandler(db *database.Database) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userId := ctx.Locals("userId").(int64)
+
+		invitations, err := database.GetPendingInvitations(ctx.Context(), db.GetDb(), database.GetInvitationsArgs{UserID: &userId})
+		if err != nil {
+			return pkg.Error(ctx, fiber.StatusBadRequest, SomethingWentWrongMsg, err)
+		}
+
+		return pkg.Success(ctx, fiber.Map{"invitations": invitations})
+	}
+}
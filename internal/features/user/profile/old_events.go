@@ -44,11 +44,17 @@ events.description,
 					event_locations.address,
 					event_locations.starts_at,
 					event_locations.ends_at,
-					event_locations.attendees_count`).
+					event_locations.attendees_count,
+					events.status,
+					event_locations.id`).
 		From("events").
-		InnerJoin("event_locations on event_locations.event_id = events.id").
 		InnerJoin("event_followers on event_followers.event_id = events.id").
-		Where(sq.LtOrEq{"event_locations.starts_at": time.Now()}).Where(sq.Eq{"event_followers.user_id": userId})
+		InnerJoin("event_locations on event_locations.id = event_followers.location_id").
+		Where(sq.Or{
+			sq.LtOrEq{"event_locations.starts_at": time.Now()},
+			sq.Eq{"events.status": pkg.EventStatusCanceled},
+		}).
+		Where(sq.Eq{"event_followers.user_id": userId})
 
 	if lastEventId != 0 {
 		query = query.Where(sq.Lt{"events.id": lastEventId})
@@ -78,7 +84,7 @@ events.description,
 			e time.Time
 		)
 
-		err := rows.Scan(&f.ID, &f.Title, &f.Description, &f.LikesCount, &f.Price, &f.Address, &s, &e, &f.AttendeesCount)
+		err := rows.Scan(&f.ID, &f.Title, &f.Description, &f.LikesCount, &f.Price, &f.Address, &s, &e, &f.AttendeesCount, &f.Status, &f.LocationId)
 		if err != nil {
 			return nil, err
 		}
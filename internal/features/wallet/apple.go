@@ -0,0 +1,227 @@
+package wallet
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/smallstep/pkcs7"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"sort"
+	"time"
+)
+
+type apple struct {
+	cfg     config.AppleWallet
+	cert    *x509.Certificate
+	key     crypto.PrivateKey
+	parents []*x509.Certificate
+	icon    []byte
+}
+
+func newApple(cfg config.AppleWallet) (*apple, error) {
+	if cfg.PassTypeID == "" || cfg.TeamID == "" {
+		return nil, errors.New("pass type id and team id must be set")
+	}
+
+	a := &apple{cfg: cfg}
+
+	var err error
+	a.cert, err = readCertificate(cfg.CertFile)
+	if err != nil {
+		return nil, err
+	}
+	a.key, err = readPrivateKey(cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.WWDRFile != "" {
+		wwdr, err := readCertificate(cfg.WWDRFile)
+		if err != nil {
+			return nil, err
+		}
+		a.parents = []*x509.Certificate{wwdr}
+	}
+
+	if cfg.IconFile != "" {
+		a.icon, err = os.ReadFile(cfg.IconFile)
+	} else {
+		a.icon, err = plainIcon()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+type appleField struct {
+	Key       string `json:"key"`
+	Label     string `json:"label"`
+	Value     string `json:"value"`
+	DateStyle string `json:"dateStyle,omitempty"`
+	TimeStyle string `json:"timeStyle,omitempty"`
+}
+
+type appleBarcode struct {
+	Format          string `json:"format"`
+	Message         string `json:"message"`
+	MessageEncoding string `json:"messageEncoding"`
+}
+
+type appleLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type applePass struct {
+	FormatVersion      int             `json:"formatVersion"`
+	PassTypeIdentifier string          `json:"passTypeIdentifier"`
+	SerialNumber       string          `json:"serialNumber"`
+	TeamIdentifier     string          `json:"teamIdentifier"`
+	OrganizationName   string          `json:"organizationName"`
+	Description        string          `json:"description"`
+	RelevantDate       string          `json:"relevantDate,omitempty"`
+	ExpirationDate     string          `json:"expirationDate"`
+	Barcodes           []appleBarcode  `json:"barcodes"`
+	Locations          []appleLocation `json:"locations,omitempty"`
+	EventTicket        struct {
+		PrimaryFields   []appleField `json:"primaryFields"`
+		SecondaryFields []appleField `json:"secondaryFields,omitempty"`
+		AuxiliaryFields []appleField `json:"auxiliaryFields,omitempty"`
+		BackFields      []appleField `json:"backFields,omitempty"`
+	} `json:"eventTicket"`
+}
+
+// ApplePass packages the ticket as a signed .pkpass bundle.
+func (w *Wallet) ApplePass(p Pass) ([]byte, error) {
+	if w.apple == nil {
+		return nil, ErrAppleDisabled
+	}
+
+	passJSON, err := json.Marshal(w.applePass(p))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{
+		"pass.json":   passJSON,
+		"icon.png":    w.apple.icon,
+		"icon@2x.png": w.apple.icon,
+	}
+
+	manifest := make(map[string]string, len(files))
+	for name, data := range files {
+		sum := sha1.Sum(data)
+		manifest[name] = hex.EncodeToString(sum[:])
+	}
+	files["manifest.json"], err = json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	files["signature"], err = w.apple.sign(files["manifest.json"])
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (w *Wallet) applePass(p Pass) applePass {
+	pass := applePass{
+		FormatVersion:      1,
+		PassTypeIdentifier: w.apple.cfg.PassTypeID,
+		SerialNumber:       p.Serial,
+		TeamIdentifier:     w.apple.cfg.TeamID,
+		OrganizationName:   w.organization,
+		Description:        "Ticket to " + p.EventTitle,
+		ExpirationDate:     p.ExpiresAt.Format(time.RFC3339),
+		Barcodes: []appleBarcode{{
+			Format:          "PKBarcodeFormatQR",
+			Message:         p.Code,
+			MessageEncoding: "iso-8859-1",
+		}},
+	}
+
+	pass.EventTicket.PrimaryFields = []appleField{{Key: "event", Label: "EVENT", Value: p.EventTitle}}
+	if p.StartsAt != nil {
+		pass.RelevantDate = p.StartsAt.Format(time.RFC3339)
+		pass.EventTicket.SecondaryFields = append(pass.EventTicket.SecondaryFields, appleField{
+			Key:       "starts",
+			Label:     "STARTS",
+			Value:     p.StartsAt.Format(time.RFC3339),
+			DateStyle: "PKDateStyleMedium",
+			TimeStyle: "PKDateStyleShort",
+		})
+	}
+	if p.Address != "" {
+		pass.EventTicket.SecondaryFields = append(pass.EventTicket.SecondaryFields, appleField{Key: "location", Label: "LOCATION", Value: p.Address})
+	}
+	if p.Latitude != nil && p.Longitude != nil {
+		pass.Locations = []appleLocation{{Latitude: *p.Latitude, Longitude: *p.Longitude}}
+	}
+	if p.TicketType != "" {
+		pass.EventTicket.AuxiliaryFields = []appleField{{Key: "type", Label: "TICKET", Value: p.TicketType}}
+	}
+	pass.EventTicket.BackFields = []appleField{{Key: "holder", Label: "HOLDER", Value: p.Holder}}
+
+	return pass
+}
+
+// sign returns the detached PKCS#7 signature of the manifest.
+func (a *apple) sign(manifest []byte) ([]byte, error) {
+	sd, err := pkcs7.NewSignedData(manifest)
+	if err != nil {
+		return nil, err
+	}
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+
+	err = sd.AddSignerChain(a.cert, a.key, a.parents, pkcs7.SignerInfoConfig{})
+	if err != nil {
+		return nil, err
+	}
+	sd.Detach()
+
+	return sd.Finish()
+}
+
+// plainIcon is the icon of passes when no icon file is configured.
+func plainIcon() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 58, 58))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 0x3b, G: 0x5b, B: 0xdb, A: 0xff}), image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	return buf.Bytes(), err
+}
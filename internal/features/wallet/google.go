@@ -0,0 +1,170 @@
+package wallet
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"regexp"
+	"time"
+)
+
+const googleSaveURL = "https://pay.google.com/gp/v/save/"
+
+type google struct {
+	cfg   config.GoogleWallet
+	email string
+	key   *rsa.PrivateKey
+}
+
+func newGoogle(cfg config.GoogleWallet) (*google, error) {
+	if cfg.IssuerID == "" {
+		return nil, errors.New("issuer id must be set")
+	}
+
+	data, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.CredentialsFile, err)
+	}
+	if credentials.ClientEmail == "" {
+		return nil, fmt.Errorf("%s: no client_email", cfg.CredentialsFile)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(credentials.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.CredentialsFile, err)
+	}
+
+	return &google{cfg: cfg, email: credentials.ClientEmail, key: key}, nil
+}
+
+type googleString struct {
+	DefaultValue struct {
+		Language string `json:"language"`
+		Value    string `json:"value"`
+	} `json:"defaultValue"`
+}
+
+func localized(value string) *googleString {
+	s := &googleString{}
+	s.DefaultValue.Language = "en-US"
+	s.DefaultValue.Value = value
+	return s
+}
+
+type googleInterval struct {
+	Start *googleDate `json:"start,omitempty"`
+	End   *googleDate `json:"end,omitempty"`
+}
+
+type googleDate struct {
+	Date string `json:"date"`
+}
+
+func date(t *time.Time) *googleDate {
+	if t == nil {
+		return nil
+	}
+	return &googleDate{Date: t.Format(time.RFC3339)}
+}
+
+type googleClass struct {
+	ID           string          `json:"id"`
+	IssuerName   string          `json:"issuerName"`
+	ReviewStatus string          `json:"reviewStatus"`
+	EventName    *googleString   `json:"eventName"`
+	Venue        *googleVenue    `json:"venue,omitempty"`
+	DateTime     *googleInterval `json:"dateTime,omitempty"`
+}
+
+type googleVenue struct {
+	Name    *googleString `json:"name"`
+	Address *googleString `json:"address"`
+}
+
+type googleObject struct {
+	ID               string        `json:"id"`
+	ClassID          string        `json:"classId"`
+	State            string        `json:"state"`
+	TicketHolderName string        `json:"ticketHolderName,omitempty"`
+	TicketType       *googleString `json:"ticketType,omitempty"`
+	Barcode          struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"barcode"`
+	ValidTimeInterval *googleInterval `json:"validTimeInterval"`
+}
+
+var googleIDUnsafe = regexp.MustCompile(`[^\w.-]`)
+
+// GoogleSaveURL returns the link adding the ticket to Google Wallet. The
+// class of the event is created along with the first pass saved.
+func (w *Wallet) GoogleSaveURL(p Pass) (string, error) {
+	if w.google == nil {
+		return "", ErrGoogleDisabled
+	}
+
+	issuer := w.google.cfg.IssuerID
+
+	class := googleClass{
+		ID:           fmt.Sprintf("%s.event-%d", issuer, p.EventID),
+		IssuerName:   w.organization,
+		ReviewStatus: "UNDER_REVIEW",
+		EventName:    localized(p.EventTitle),
+	}
+	if p.Address != "" {
+		class.Venue = &googleVenue{Name: localized(p.Address), Address: localized(p.Address)}
+	}
+	if p.StartsAt != nil {
+		class.DateTime = &googleInterval{Start: date(p.StartsAt), End: date(p.EndsAt)}
+	}
+
+	object := googleObject{
+		ID:                fmt.Sprintf("%s.%s", issuer, googleIDUnsafe.ReplaceAllString(p.Serial, "_")),
+		ClassID:           class.ID,
+		State:             "ACTIVE",
+		TicketHolderName:  p.Holder,
+		ValidTimeInterval: &googleInterval{Start: date(p.StartsAt), End: date(&p.ExpiresAt)},
+	}
+	object.Barcode.Type = "QR_CODE"
+	object.Barcode.Value = p.Code
+	if p.TicketType != "" {
+		object.TicketType = localized(p.TicketType)
+	}
+
+	origins := w.google.cfg.Origins
+	if origins == nil {
+		origins = []string{}
+	}
+
+	// the audience of save links is the plain string google
+	claims := jwt.MapClaims{
+		"iss":     w.google.email,
+		"aud":     "google",
+		"typ":     "savetowallet",
+		"iat":     time.Now().Unix(),
+		"origins": origins,
+		"payload": map[string]any{
+			"eventTicketClasses": []googleClass{class},
+			"eventTicketObjects": []googleObject{object},
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(w.google.key)
+	if err != nil {
+		return "", err
+	}
+
+	return googleSaveURL + signed, nil
+}
@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/NuEventTeam/events/internal/config"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	ErrAppleDisabled  = errors.New("apple wallet is not configured")
+	ErrGoogleDisabled = errors.New("google wallet is not configured")
+)
+
+// Pass is what a wallet pass shows about a ticket. Serial stays the same for
+// every pass of a registration, so adding it again updates the pass.
+type Pass struct {
+	Serial     string
+	Code       string
+	EventID    int64
+	EventTitle string
+	Holder     string
+	TicketType string
+	Address    string
+	Latitude   *float64
+	Longitude  *float64
+	StartsAt   *time.Time
+	EndsAt     *time.Time
+	ExpiresAt  time.Time
+}
+
+type Wallet struct {
+	organization string
+	apple        *apple
+	google       *google
+}
+
+func New(cfg config.Wallet) (*Wallet, error) {
+	w := &Wallet{organization: cfg.Organization}
+
+	var err error
+	if cfg.Apple.CertFile != "" {
+		w.apple, err = newApple(cfg.Apple)
+		if err != nil {
+			return nil, fmt.Errorf("apple wallet: %w", err)
+		}
+	}
+	if cfg.Google.CredentialsFile != "" {
+		w.google, err = newGoogle(cfg.Google)
+		if err != nil {
+			return nil, fmt.Errorf("google wallet: %w", err)
+		}
+	}
+
+	return w, nil
+}
+
+func MustNew(cfg config.Wallet) *Wallet {
+	w, err := New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return w
+}
+
+func readCertificate(file string) (*x509.Certificate, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// readPrivateKey reads PKCS#8, PKCS#1 and EC keys.
+func readPrivateKey(file string) (crypto.PrivateKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported private key", file)
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", file)
+	}
+	return block, nil
+}
@@ -45,6 +45,29 @@ type Manager struct {
 	Role    Role  `json:"role"`
 }
 
+type ManagerInvitation struct {
+	ID         int64     `json:"id"`
+	EventID    int64     `json:"eventId"`
+	EventTitle *string   `json:"eventTitle,omitempty"`
+	UserID     int64     `json:"userId"`
+	Username   string    `json:"username"`
+	InvitedBy  int64     `json:"invitedBy"`
+	Role       Role      `json:"role"`
+	Status     int       `json:"status"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type StatusChange struct {
+	ID        int64     `json:"id"`
+	EventID   int64     `json:"eventId"`
+	From      int       `json:"from"`
+	To        int       `json:"to"`
+	ChangedBy *int64    `json:"changedBy"`
+	Reason    *string   `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type Location struct {
 	ID             int64           `json:"id"`
 	EventID        int64           `json:"eventID"`
@@ -56,6 +79,87 @@ type Location struct {
 	Seats          *int64          `json:"seats"`
 	AttendeesCount *int64          `json:"attendeesCount"`
 	Archived       bool            `json:"archived"`
+	// RecurrenceID and OccurrenceAt are set for occurrences of a recurring
+	// event, Detached once the occurrence was edited on its own.
+	RecurrenceID *int64     `json:"recurrenceId,omitempty"`
+	OccurrenceAt *time.Time `json:"occurrenceAt,omitempty"`
+	Detached     bool       `json:"detached,omitempty"`
+}
+
+// Recurrence is the template the occurrences of a recurring event are
+// materialized from.
+type Recurrence struct {
+	ID                int64       `json:"id"`
+	EventID           int64       `json:"eventId"`
+	Rule              string      `json:"rule"`
+	StartsAt          time.Time   `json:"startsAt"`
+	EndsAt            time.Time   `json:"endsAt"`
+	Address           *string     `json:"address"`
+	Longitude         *float64    `json:"longitude"`
+	Latitude          *float64    `json:"latitude"`
+	Seats             *int64      `json:"seats"`
+	Exceptions        []time.Time `json:"exceptions"`
+	MaterializedUntil time.Time   `json:"materializedUntil"`
+}
+
+// Order is a seat bought for a paid event, its TicketTypeID is nil for events
+// sold at their single price. Amounts are in minor units of the currency.
+type Order struct {
+	ID           int64      `json:"id"`
+	EventID      int64      `json:"eventId"`
+	LocationID   int64      `json:"locationId"`
+	UserID       int64      `json:"userId"`
+	Amount       int64      `json:"amount"`
+	Currency     string     `json:"currency"`
+	Status       int        `json:"status"`
+	TicketTypeID *int64     `json:"ticketTypeId"`
+	PromoCodeID  *int64     `json:"promoCodeId"`
+	Discount     int64      `json:"discount"`
+	Provider     *string    `json:"provider"`
+	PaymentID    *string    `json:"paymentId"`
+	ExpiresAt    time.Time  `json:"expiresAt"`
+	PaidAt       *time.Time `json:"paidAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// TicketType is a tier of the tickets of an event. Tiers without a quota are
+// not limited, and a tier is on sale between its optional sales start and end.
+type TicketType struct {
+	ID         int64      `json:"id"`
+	EventID    int64      `json:"eventId"`
+	Name       string     `json:"name"`
+	Price      float64    `json:"price"`
+	Quota      *int64     `json:"quota"`
+	SoldCount  int64      `json:"soldCount"`
+	SalesStart *time.Time `json:"salesStart"`
+	SalesEnd   *time.Time `json:"salesEnd"`
+}
+
+// PromoCode lowers the price of an order by Value percent or, for fixed
+// codes, by Value of the currency.
+type PromoCode struct {
+	ID        int64      `json:"id"`
+	EventID   int64      `json:"eventId"`
+	Code      string     `json:"code"`
+	Kind      int        `json:"kind"`
+	Value     float64    `json:"value"`
+	MaxUses   *int64     `json:"maxUses"`
+	UsedCount int64      `json:"usedCount"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CheckIn is a scan of a ticket at the door, UserID and LocationID are nil
+// when the ticket could not be read.
+type CheckIn struct {
+	ID         int64     `json:"id"`
+	EventID    int64     `json:"eventId"`
+	LocationID *int64    `json:"locationId"`
+	UserID     *int64    `json:"userId"`
+	ScannedBy  int64     `json:"scannedBy"`
+	Scanner    *string   `json:"scanner"`
+	Result     int       `json:"result"`
+	Reason     *string   `json:"reason"`
+	CreatedAt  time.Time `json:"createdAt"`
 }
 
 type Image struct {
@@ -66,26 +170,29 @@ type Image struct {
 }
 
 type Event struct {
-	ID               int64          `json:"id"`
-	Title            *string        `json:"title"`
-	Description      *string        `json:"description"`
-	Status           *int           `json:"status"`
-	MaxAge           *int64         `json:"maxAge"`
-	MinAge           *int64         `json:"minAge"`
-	RemoveImagesIds  []int64        `json:"-,omitempty"`
-	LocalPrice       *int64         `json:"-"`
-	Price            float64        `json:"price"`
-	Images           []assets.Image `json:"images"`
-	ImageIds         []int64        `json:"imageIds"`
-	CreatedAt        time.Time      `json:"created_at"`
-	IsMy             bool           `json:"isMy"`
-	Categories       []Category     `json:"categories"`
-	CategoryIds      []int64        `json:"-"`
-	RemoveCategories []int64        `json:"removeCategories"`
-	Locations        []Location     `json:"locations"`
-	Managers         []Manager      `json:"managers"`
-	Attendees        []User         `json:"-"`
-	FollowerCount    int64          `json:"followerCount"`
+	ID                int64          `json:"id"`
+	Title             *string        `json:"title"`
+	Description       *string        `json:"description"`
+	Status            *int           `json:"status"`
+	MaxAge            *int64         `json:"maxAge"`
+	MinAge            *int64         `json:"minAge"`
+	RemoveImagesIds   []int64        `json:"-,omitempty"`
+	RemoveLocationIds []int64        `json:"-"`
+	LocalPrice        *int64         `json:"-"`
+	Price             float64        `json:"price"`
+	Images            []assets.Image `json:"images"`
+	ImageIds          []int64        `json:"imageIds"`
+	CreatedAt         time.Time      `json:"created_at"`
+	IsMy              bool           `json:"isMy"`
+	Categories        []Category     `json:"categories"`
+	CategoryIds       []int64        `json:"-"`
+	RemoveCategories  []int64        `json:"removeCategories"`
+	Locations         []Location     `json:"locations"`
+	Recurrence        *Recurrence    `json:"recurrence,omitempty"`
+	TicketTypes       []TicketType   `json:"ticketTypes"`
+	Managers          []Manager      `json:"managers"`
+	Attendees         []User         `json:"-"`
+	FollowerCount     int64          `json:"followerCount"`
 }
 
 type Otp struct {
@@ -96,10 +203,74 @@ type Otp struct {
 }
 
 type Token struct {
-	UserAgent *string
-	Phone     *string
-	UserId    *int64
-	Token     string `json:"token" msgpack:"token"`
-	Type      int32
-	Duration  time.Duration
+	UserAgent  *string
+	Phone      *string
+	UserId     *int64
+	SessionId  *string
+	DeviceName *string
+	IP         *string
+	Token      string `json:"token" msgpack:"token"`
+	Type       int32
+	Duration   time.Duration
+	// SessionStartedAt is carried over when a refresh token is rotated.
+	SessionStartedAt *time.Time
+	RotatedAt        *time.Time
+}
+
+type Session struct {
+	ID         string    `json:"id"`
+	DeviceName *string   `json:"deviceName"`
+	UserAgent  *string   `json:"userAgent"`
+	IP         *string   `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Current    bool      `json:"current"`
+}
+
+type Follower struct {
+	UserId       int64   `json:"userId"`
+	Username     string  `json:"username"`
+	ProfileImage *string `json:"profileImage"`
+}
+
+// Attendee is a follower of an event as organizers export them.
+type Attendee struct {
+	UserID       int64
+	Username     string
+	Firstname    string
+	Lastname     *string
+	Phone        string
+	RegisteredAt time.Time
+	Attended     bool
+	CheckedInAt  *time.Time
+}
+
+type CommentAuthor struct {
+	ID            int64   `json:"id"`
+	Username      string  `json:"username"`
+	ProfileImage  *string `json:"profileImage"`
+	IsEventAuthor bool    `json:"isEventAuthor"`
+}
+
+type Comment struct {
+	CommentId int64         `json:"commentId"`
+	ParentId  *int64        `json:"parentId"`
+	EventId   int64         `json:"-"`
+	Text      string        `json:"text"`
+	Author    CommentAuthor `json:"author"`
+	Children  []Comment     `json:"children"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+type ChatMessage struct {
+	ID           int64     `json:"id"`
+	EventId      int64     `json:"eventId"`
+	UserId       int64     `json:"userId"`
+	Username     string    `json:"username"`
+	ProfileImage *string   `json:"profileImage"`
+	Message      string    `json:"message"`
+	CreatedAt    time.Time `json:"createdAt"`
+	IsMy         bool      `json:"isMy"`
+	System       bool      `json:"system"`
 }
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/pkg"
+)
+
+func AddChatMember(ctx context.Context, db DBTX, eventId, userId, roleId int64) error {
+	query := qb.Insert("chat_members").
+		Columns("event_id", "user_id", "role_id").
+		Values(eventId, userId, roleId).
+		Suffix("on conflict do nothing")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, args...)
+	return err
+}
+
+func UpdateChatMemberRole(ctx context.Context, db DBTX, eventId, userId, roleId int64) error {
+	query := qb.Update("chat_members").
+		Set("role_id", roleId).
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, args...)
+	return err
+}
+
+func CreateChatMessage(ctx context.Context, db DBTX, eventId, userId int64, message string) (models.ChatMessage, error) {
+	return createChatMessage(ctx, db, eventId, userId, message, pkg.ChatMessageKindUser)
+}
+
+// CreateSystemChatMessage saves an announcement about the event, userId is
+// the user whose action it announces.
+func CreateSystemChatMessage(ctx context.Context, db DBTX, eventId, userId int64, message string) (models.ChatMessage, error) {
+	return createChatMessage(ctx, db, eventId, userId, message, pkg.ChatMessageKindSystem)
+}
+
+func createChatMessage(ctx context.Context, db DBTX, eventId, userId int64, message string, kind int) (models.ChatMessage, error) {
+	query := qb.Insert("chat_messages").
+		Columns("event_id", "user_id", "messages", "kind").
+		Values(eventId, userId, message, kind).
+		Suffix("returning id, created_at")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return models.ChatMessage{}, err
+	}
+
+	msg := models.ChatMessage{
+		EventId: eventId,
+		UserId:  userId,
+		Message: message,
+		System:  kind == pkg.ChatMessageKindSystem,
+	}
+
+	err = db.QueryRow(ctx, stmt, args...).Scan(&msg.ID, &msg.CreatedAt)
+	if err != nil {
+		return models.ChatMessage{}, err
+	}
+
+	userQuery := qb.Select("username", "profile_image").
+		From("users").
+		Where(sq.Eq{"id": userId})
+
+	stmt, args, err = userQuery.ToSql()
+	if err != nil {
+		return models.ChatMessage{}, err
+	}
+
+	err = db.QueryRow(ctx, stmt, args...).Scan(&msg.Username, &msg.ProfileImage)
+	if err != nil {
+		return models.ChatMessage{}, err
+	}
+
+	if msg.ProfileImage != nil {
+		*msg.ProfileImage = pkg.CDNBaseUrl + *msg.ProfileImage
+	}
+
+	return msg, nil
+}
+
+func GetChatMessages(ctx context.Context, db DBTX, eventId, lastId int64) ([]models.ChatMessage, error) {
+	query := qb.Select("chat_messages.id", "chat_messages.event_id", "user_id", "username", "profile_image", "chat_messages.created_at", "messages", "chat_messages.kind").
+		From("chat_messages").
+		InnerJoin("users on users.id = chat_messages.user_id").
+		Where(sq.Eq{"chat_messages.event_id": eventId}).
+		Where(sq.Gt{"chat_messages.id": lastId}).
+		OrderBy("chat_messages.id desc")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.ChatMessage
+	for rows.Next() {
+		var m models.ChatMessage
+		var kind int
+		err := rows.Scan(&m.ID, &m.EventId, &m.UserId, &m.Username, &m.ProfileImage, &m.CreatedAt, &m.Message, &kind)
+		if err != nil {
+			return nil, err
+		}
+		m.System = kind == pkg.ChatMessageKindSystem
+		if m.ProfileImage != nil {
+			*m.ProfileImage = pkg.CDNBaseUrl + *m.ProfileImage
+		}
+
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
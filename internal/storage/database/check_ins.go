@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const CheckInsTable = "check_ins"
+
+// CheckInFollower marks the follower as attended by the scanner. It reports
+// false when the follower was already checked in, so a ticket is let in once.
+func CheckInFollower(ctx context.Context, db DBTX, eventId, userId, scannedBy int64) (bool, error) {
+	now := time.Now()
+
+	return execAffected(ctx, db, qb.Update("event_followers").
+		Set("attended", true).
+		Set("checked_in_at", now).
+		Set("checked_in_by", scannedBy).
+		Set("updated_at", now).
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId}).
+		Where(sq.Eq{"checked_in_at": nil}))
+}
+
+// GetFollowerCheckIn returns the time of day, in the time zone of the
+// database, the follower was checked in at and the username of the scanner.
+// The time is empty when the follower was not checked in.
+func GetFollowerCheckIn(ctx context.Context, db DBTX, eventId, userId int64) (string, string, error) {
+	query := qb.Select("to_char(event_followers.checked_in_at, 'HH24:MI')", "coalesce(users.username, '')").
+		From("event_followers").
+		LeftJoin("users on users.id = event_followers.checked_in_by").
+		Where(sq.Eq{"event_followers.event_id": eventId}).
+		Where(sq.Eq{"event_followers.user_id": userId}).
+		Where(sq.NotEq{"event_followers.checked_in_at": nil})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return "", "", err
+	}
+
+	var at, scanner string
+	err = db.QueryRow(ctx, stmt, args...).Scan(&at, &scanner)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+
+	return at, scanner, nil
+}
+
+func AddCheckIn(ctx context.Context, db DBTX, c models.CheckIn) error {
+	query := qb.Insert(CheckInsTable).
+		Columns("event_id", "location_id", "user_id", "scanned_by", "result", "reason").
+		Values(c.EventID, c.LocationID, c.UserID, c.ScannedBy, c.Result, c.Reason)
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, args...)
+	return err
+}
+
+// GetCheckIns returns the scans of the event newest first, those before
+// lastId when it is set.
+func GetCheckIns(ctx context.Context, db DBTX, eventId, lastId int64) ([]models.CheckIn, error) {
+	query := qb.Select("check_ins.id", "check_ins.event_id", "check_ins.location_id", "check_ins.user_id",
+		"check_ins.scanned_by", "users.username", "check_ins.result", "check_ins.reason", "check_ins.created_at").
+		From(CheckInsTable).
+		LeftJoin("users on users.id = check_ins.scanned_by").
+		Where(sq.Eq{"check_ins.event_id": eventId}).
+		OrderBy("check_ins.id desc").
+		Limit(50)
+	if lastId > 0 {
+		query = query.Where(sq.Lt{"check_ins.id": lastId})
+	}
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checkIns []models.CheckIn
+	for rows.Next() {
+		var c models.CheckIn
+		err := rows.Scan(&c.ID, &c.EventID, &c.LocationID, &c.UserID, &c.ScannedBy, &c.Scanner, &c.Result, &c.Reason, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		checkIns = append(checkIns, c)
+	}
+
+	return checkIns, rows.Err()
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/pkg"
+)
+
+func CreateComment(ctx context.Context, db DBTX, comment models.Comment) (models.Comment, error) {
+	query := qb.Insert("comments").
+		Columns("parent_id", "text", "author_id", "event_id").
+		Values(comment.ParentId, comment.Text, comment.Author.ID, comment.EventId).
+		Suffix("returning id, created_at")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	err = db.QueryRow(ctx, stmt, args...).Scan(&comment.CommentId, &comment.CreatedAt)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	return comment, nil
+}
+
+func GetCommentAuthor(ctx context.Context, db DBTX, userId int64) (models.CommentAuthor, error) {
+	query := qb.Select("id", "username", "profile_image").
+		From("users").
+		Where(sq.Eq{"id": userId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return models.CommentAuthor{}, err
+	}
+
+	var author models.CommentAuthor
+	err = db.QueryRow(ctx, stmt, args...).Scan(&author.ID, &author.Username, &author.ProfileImage)
+	if err != nil {
+		return models.CommentAuthor{}, err
+	}
+	if author.ProfileImage != nil {
+		*author.ProfileImage = pkg.CDNBaseUrl + *author.ProfileImage
+	}
+
+	return author, nil
+}
+
+func GetEventAuthorUsername(ctx context.Context, db DBTX, eventId int64) (string, error) {
+	query := qb.Select("users.username").
+		From("users").
+		InnerJoin("event_managers on users.id = event_managers.user_id").
+		InnerJoin("event_role_permissions on event_managers.role_id = event_role_permissions.role_id").
+		Where(sq.Eq{"event_managers.event_id": eventId}).
+		Where(sq.Eq{"event_role_permissions.permission_id": pkg.PermissionUpdate}).
+		Limit(1)
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	var username string
+	err = db.QueryRow(ctx, stmt, args...).Scan(&username)
+
+	return username, err
+}
+
+func GetParentComments(ctx context.Context, db DBTX, eventId, lastParentId int64) ([]models.Comment, error) {
+	query := qb.Select("comments.id", "comments.text", "comments.parent_id",
+		"users.id", "users.profile_image", "users.username", "comments.created_at").
+		From("comments").
+		InnerJoin("users on users.id = comments.author_id")
+
+	if lastParentId != 0 {
+		query = query.Where(sq.Lt{"comments.id": lastParentId})
+	}
+
+	query = query.Where(sq.Eq{"comments.event_id": eventId}).
+		Where(sq.Eq{"parent_id": nil}).
+		OrderBy("comments.id desc", "comments.created_at desc")
+
+	return getComments(ctx, db, query)
+}
+
+func GetChildComments(ctx context.Context, db DBTX, parentIds ...int64) ([]models.Comment, error) {
+	if len(parentIds) == 0 {
+		return nil, nil
+	}
+
+	query := qb.Select("comments.id", "comments.text", "comments.parent_id",
+		"users.id", "users.profile_image", "users.username", "comments.created_at").
+		From("comments").
+		InnerJoin("users on users.id = comments.author_id").
+		Where(sq.Eq{"parent_id": parentIds}).
+		OrderBy("parent_id desc", "comments.created_at desc")
+
+	return getComments(ctx, db, query)
+}
+
+func getComments(ctx context.Context, db DBTX, query sq.SelectBuilder) ([]models.Comment, error) {
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var c models.Comment
+		err := rows.Scan(&c.CommentId, &c.Text, &c.ParentId, &c.Author.ID, &c.Author.ProfileImage, &c.Author.Username, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Author.ProfileImage != nil {
+			*c.Author.ProfileImage = pkg.CDNBaseUrl + *c.Author.ProfileImage
+		}
+
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+)
+
+const DevicesTable = "user_devices"
+
+// SaveDevice registers the push token for the user, a token moves to the last
+// user that registered it.
+func SaveDevice(ctx context.Context, db DBTX, userId int64, token string, platform *string) error {
+	query := qb.Insert(DevicesTable).
+		Columns("token", "user_id", "platform").
+		Values(token, userId, platform).
+		Suffix("on conflict (token) do update set user_id = excluded.user_id, platform = excluded.platform, updated_at = now()")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, args...)
+	return err
+}
+
+func DeleteDevice(ctx context.Context, db DBTX, userId int64, token string) error {
+	query := qb.Delete(DevicesTable).
+		Where(sq.Eq{"user_id": userId}).
+		Where(sq.Eq{"token": token})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, args...)
+	return err
+}
+
+func GetFollowerDevices(ctx context.Context, db DBTX, eventId int64) ([]string, error) {
+	query := qb.Select("user_devices.token").
+		From(DevicesTable).
+		InnerJoin("event_followers on event_followers.user_id = user_devices.user_id").
+		Where(sq.Eq{"event_followers.event_id": eventId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+func GetUserDevices(ctx context.Context, db DBTX, userIds ...int64) ([]string, error) {
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+
+	query := qb.Select("token").
+		From(DevicesTable).
+		Where(sq.Eq{"user_id": userIds})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
@@ -3,7 +3,6 @@ package database
 import (
 	"context"
 	"errors"
-	"fmt"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/NuEventTeam/events/internal/features/assets"
 	"github.com/NuEventTeam/events/internal/models"
@@ -152,10 +151,12 @@ func GetEventByID(ctx context.Context, db DBTX, eventID int64) (*models.Event, e
 }
 
 func GetEventLocations(ctx context.Context, db DBTX, eventID int64) ([]models.Location, error) {
-	query := qb.Select("id", "event_id", "address", "longitude", "latitude", "seats", "starts_at", "ends_at", "attendees_count").
+	query := qb.Select("id", "event_id", "address", "longitude", "latitude", "seats", "starts_at", "ends_at", "attendees_count",
+		"recurrence_id", "occurrence_at", "detached").
 		From("event_locations").
 		Where(sq.Eq{"deleted_at": nil}).
-		Where(sq.Eq{"event_id": eventID})
+		Where(sq.Eq{"event_id": eventID}).
+		OrderBy("starts_at", "id")
 
 	stmt, params, err := query.ToSql()
 	if err != nil {
@@ -178,7 +179,8 @@ func GetEventLocations(ctx context.Context, db DBTX, eventID int64) ([]models.Lo
 			e time.Time
 		)
 
-		err := rows.Scan(&l.ID, &l.EventID, &l.Address, &l.Longitude, &l.Latitude, &l.Seats, &s, &e, &l.AttendeesCount)
+		err := rows.Scan(&l.ID, &l.EventID, &l.Address, &l.Longitude, &l.Latitude, &l.Seats, &s, &e, &l.AttendeesCount,
+			&l.RecurrenceID, &l.OccurrenceAt, &l.Detached)
 		if err != nil {
 			return nil, err
 		}
@@ -225,6 +227,7 @@ func GetEventImages(ctx context.Context, db DBTX, eventId int64, imgIds ...int64
 		if err != nil {
 			return nil, err
 		}
+		i.Renditions = assets.RenditionURLs(i.Url)
 		i.Url = pkg.CDNBaseUrl + i.Url
 		imgs = append(imgs, i)
 	}
@@ -291,8 +294,6 @@ func GetEventManagers(ctx context.Context, db DBTX, eventId int64) ([]models.Man
 		return nil, err
 	}
 
-	defer rows.Close()
-
 	var mans []models.Manager
 
 	for rows.Next() {
@@ -300,20 +301,29 @@ func GetEventManagers(ctx context.Context, db DBTX, eventId int64) ([]models.Man
 
 		err := rows.Scan(&m.User.Username, &m.User.Firstname, &m.User.Lastname, &m.User.ProfileImage, &m.User.UserID, &m.Role.ID, &m.Role.Name, &m.User.Phone)
 		if err != nil {
+			rows.Close()
 			return nil, err
 		}
 		if m.User.ProfileImage != nil {
 			*m.User.ProfileImage = pkg.CDNBaseUrl + *m.User.ProfileImage
 		}
 
-		m.Role.Permissions, err = GetRolePermissions(ctx, db, m.Role.ID)
-		if err != nil {
-			return nil, err
-		}
 		m.Role.EventID = eventId
 		m.EventId = eventId
 		mans = append(mans, m)
 	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// a transaction cannot run a query while rows are still being read
+	for i := range mans {
+		mans[i].Role.Permissions, err = GetRolePermissions(ctx, db, mans[i].Role.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return mans, nil
 }
@@ -330,15 +340,16 @@ func RemoveEventCategories(ctx context.Context, db DBTX, eventId int64) error {
 	return err
 }
 
-func RemoveManagers(ctx context.Context, db DBTX, eventId int64, managerIds ...int64) error {
-	if len(managerIds) == 0 {
+func RemoveManagers(ctx context.Context, db DBTX, eventId int64, userIds ...int64) error {
+	if len(userIds) == 0 {
 		return nil
 	}
 	query := qb.Update("event_managers").
 		Set("deleted_at", time.Now()).
 		Set("updated_at", time.Now()).
 		Where(sq.Eq{"event_id": eventId}).
-		Where(sq.Eq{"id": managerIds})
+		Where(sq.Eq{"user_id": userIds}).
+		Where(sq.Eq{"deleted_at": nil})
 
 	stmt, params, err := query.ToSql()
 	if err != nil {
@@ -358,7 +369,8 @@ func RemoveLocations(ctx context.Context, db DBTX, eventId int64, locationIds ..
 		Set("deleted_at", time.Now()).
 		Set("updated_at", time.Now()).
 		Where(sq.Eq{"event_id": eventId}).
-		Where(sq.Eq{"id": locationIds})
+		Where(sq.Eq{"id": locationIds}).
+		Where(sq.Eq{"deleted_at": nil})
 
 	stmt, params, err := query.ToSql()
 	if err != nil {
@@ -387,7 +399,8 @@ func RemoveImages(ctx context.Context, db DBTX, eventId int64, imgIds ...int64)
 	return err
 }
 
-func UpdateLocation(ctx context.Context, db DBTX, eventId, locationId int64, location models.Location) error {
+// locationFields returns the columns to update for the fields set on location.
+func locationFields(location models.Location) map[string]interface{} {
 	m := map[string]interface{}{}
 
 	if location.Address != nil {
@@ -398,7 +411,7 @@ func UpdateLocation(ctx context.Context, db DBTX, eventId, locationId int64, loc
 		m["longitude"] = *location.Longitude
 	}
 
-	if location.Longitude != nil {
+	if location.Latitude != nil {
 		m["latitude"] = *location.Latitude
 	}
 
@@ -414,8 +427,19 @@ func UpdateLocation(ctx context.Context, db DBTX, eventId, locationId int64, loc
 		m["ends_at"] = time.Time(*location.EndsAt)
 	}
 
+	return m
+}
+
+func UpdateLocation(ctx context.Context, db DBTX, eventId, locationId int64, location models.Location) error {
+	m := locationFields(location)
+
+	if len(m) == 0 {
+		return nil
+	}
+	m["updated_at"] = time.Now()
+
 	query := qb.Update("event_locations").SetMap(m).
-		Where(sq.Eq{"id": location.ID}).
+		Where(sq.Eq{"id": locationId}).
 		Where(sq.Eq{"event_id": eventId}).
 		Where(sq.Eq{"deleted_at": nil})
 
@@ -443,10 +467,6 @@ func UpdateMainEvent(ctx context.Context, db DBTX, event models.Event) error {
 	if event.MinAge != nil {
 		m["age_min"] = *event.MinAge
 	}
-
-	if event.Status != nil {
-		m["status"] = *event.Status
-	}
 	if len(m) == 0 {
 		return nil
 	}
@@ -464,34 +484,59 @@ func UpdateMainEvent(ctx context.Context, db DBTX, event models.Event) error {
 	return err
 }
 
-func UpdateManager(ctx context.Context, db DBTX, eventId, managerId int, role models.Role) error {
+// UpdateManager changes the role of an active manager, it reports false when
+// the user does not manage the event.
+func UpdateManager(ctx context.Context, db DBTX, eventId, userId, roleId int64) (bool, error) {
 
 	query := qb.Update("event_managers").
-		Set("role_id", role.ID).
-		Where(sq.Eq{"eventId": eventId}).
-		Where(sq.Eq{"id": managerId})
+		Set("role_id", roleId).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId}).
+		Where(sq.Eq{"deleted_at": nil})
 
 	stmt, params, err := query.ToSql()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	_, err = db.Exec(ctx, stmt, params...)
-	return err
+	tag, err := db.Exec(ctx, stmt, params...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
 
 }
 
+func IsEventManager(ctx context.Context, db DBTX, eventId, userId int64) (bool, error) {
+	query := qb.Select("count(*)").
+		From("event_managers").
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId}).
+		Where(sq.Eq{"deleted_at": nil})
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	err = db.QueryRow(ctx, stmt, params...).Scan(&count)
+	return count > 0, err
+}
+
 func CheckPermission(ctx context.Context, db DBTX, eventID, userID int64, permissionIds ...int64) (bool, error) {
 
 	if len(permissionIds) == 0 {
 		return false, nil
 	}
 
-	query := qb.Select("count(*)").
+	query := qb.Select("count(distinct erp.permission_id)").
 		From("event_managers").
 		InnerJoin("event_roles er on er.id = event_managers.role_id").
 		InnerJoin("event_role_permissions erp on er.id = erp.role_id").
 		Where(sq.Eq{"event_managers.user_id": userID}).
+		Where(sq.Eq{"event_managers.deleted_at": nil}).
 		Where(sq.Eq{"er.event_id": eventID}).
 		Where(sq.Eq{"erp.permission_id": permissionIds})
 
@@ -516,39 +561,54 @@ func CheckPermission(ctx context.Context, db DBTX, eventID, userID int64, permis
 	return len(permissionIds) == count, nil
 }
 
-func AddEventFollower(ctx context.Context, db DBTX, eventId, followerId int64) error {
+func AddEventFollower(ctx context.Context, db DBTX, eventId, locationId, followerId int64) (bool, error) {
 	query := qb.Insert("event_followers").
-		Columns("event_id", "user_id").
-		Values(eventId, followerId)
+		Columns("event_id", "location_id", "user_id").
+		Values(eventId, locationId, followerId).
+		Suffix("on conflict do nothing")
 
 	stmt, args, err := query.ToSql()
 	if err != nil {
-		return err
+		return false, err
 	}
-	log.Println(stmt, args)
 
-	_, err = db.Exec(ctx, stmt, args...)
-	return err
+	res, err := db.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
 }
 
-func RemoveEventFollower(ctx context.Context, db DBTX, eventId, followerId int64) error {
+// RemoveEventFollower deletes the follower and returns the session it was
+// registered to, false when the user did not follow the event.
+func RemoveEventFollower(ctx context.Context, db DBTX, eventId, followerId int64) (*int64, bool, error) {
 	query := qb.Delete("event_followers").
 		Where(sq.Eq{"event_id": eventId}).
-		Where(sq.Eq{"user_id": followerId})
+		Where(sq.Eq{"user_id": followerId}).
+		Suffix("returning location_id")
 
 	stmt, args, err := query.ToSql()
 	if err != nil {
-		return err
+		return nil, false, err
 	}
 
-	_, err = db.Exec(ctx, stmt, args...)
-	return err
+	var locationId *int64
+	err = db.QueryRow(ctx, stmt, args...).Scan(&locationId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return locationId, true, nil
 }
 
-func BanEventFollower(ctx context.Context, db DBTX, eventId, followerId int64) error {
-	query := qb.Insert("banned_event_followers").
-		Columns("event_id", "follower_id").
-		Values(eventId, followerId)
+func ChangeLocationAttendeesCount(ctx context.Context, db DBTX, locationId, by int64) error {
+	query := qb.Update("event_locations").
+		Set("attendees_count", sq.Expr("attendees_count + ?", by)).
+		Where(sq.Eq{"id": locationId})
 
 	stmt, args, err := query.ToSql()
 	if err != nil {
@@ -559,10 +619,62 @@ func BanEventFollower(ctx context.Context, db DBTX, eventId, followerId int64) e
 	return err
 }
 
-func UpdateEventFollowerCount(ctx context.Context, db DBTX, userId, by int64) error {
+// ReserveLocationSeat takes a seat of the session, false when it is full.
+// Sessions without seats have no limit. The update locks the session row, so
+// concurrent reservations cannot overbook it.
+func ReserveLocationSeat(ctx context.Context, db DBTX, locationId int64) (bool, error) {
 	query := qb.Update("event_locations").
-		Set("attendees_count", fmt.Sprintf("attendees_count %d", by)).
-		Where(sq.Eq{"user_id": userId}).Limit(1)
+		Set("attendees_count", sq.Expr("attendees_count + 1")).
+		Where(sq.Eq{"id": locationId}).
+		Where(sq.Eq{"deleted_at": nil}).
+		Where(sq.Or{
+			sq.Eq{"seats": nil},
+			sq.Eq{"seats": 0},
+			sq.Expr("attendees_count < seats"),
+		})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := db.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// GetFollowerLocation returns the session the user registered to, false when
+// the user does not follow the event.
+func GetFollowerLocation(ctx context.Context, db DBTX, eventId, userId int64) (*int64, bool, error) {
+	query := qb.Select("location_id").
+		From("event_followers").
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var locationId *int64
+	err = db.QueryRow(ctx, stmt, args...).Scan(&locationId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return locationId, true, nil
+}
+
+func BanEventFollower(ctx context.Context, db DBTX, eventId, followerId int64) error {
+	query := qb.Insert("banned_event_followers").
+		Columns("event_id", "follower_id").
+		Values(eventId, followerId)
 
 	stmt, args, err := query.ToSql()
 	if err != nil {
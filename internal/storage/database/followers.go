@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/pkg"
+)
+
+func CheckEventFollower(ctx context.Context, db DBTX, eventId, userId int64) (bool, error) {
+	query := qb.Select("count(*)").
+		From("event_followers").
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = db.QueryRow(ctx, stmt, args...).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count != 0, nil
+}
+
+func GetEventFollowers(ctx context.Context, db DBTX, eventId int64, username string, lastId int64) ([]models.Follower, error) {
+	query := qb.Select("users.id", "users.username", "users.profile_image").
+		From("users").
+		InnerJoin("event_followers on users.id = event_followers.user_id").
+		Where(sq.Eq{"event_followers.event_id": eventId}).
+		Where(sq.Like{"users.username": "%" + username + "%"}).
+		Where(sq.Gt{"event_followers.user_id": lastId}).
+		OrderBy("event_followers.user_id").
+		Limit(20)
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var followers []models.Follower
+	for rows.Next() {
+		var f models.Follower
+		err := rows.Scan(&f.UserId, &f.Username, &f.ProfileImage)
+		if err != nil {
+			return nil, err
+		}
+		if f.ProfileImage != nil {
+			*f.ProfileImage = pkg.CDNBaseUrl + *f.ProfileImage
+		}
+		followers = append(followers, f)
+	}
+
+	return followers, rows.Err()
+}
+
+// EachAttendee calls fn with every follower of the event in the order they
+// registered, rows are read as fn goes so large events are not loaded at once.
+func EachAttendee(ctx context.Context, db DBTX, eventId int64, fn func(models.Attendee) error) error {
+	query := qb.Select("users.id", "users.username", "users.firstname", "users.lastname", "users.phone",
+		"event_followers.created_at", "event_followers.attended", "event_followers.checked_in_at").
+		From("event_followers").
+		InnerJoin("users on users.id = event_followers.user_id").
+		Where(sq.Eq{"event_followers.event_id": eventId}).
+		OrderBy("event_followers.created_at", "event_followers.user_id")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.Attendee
+		err := rows.Scan(&a.UserID, &a.Username, &a.Firstname, &a.Lastname, &a.Phone, &a.RegisteredAt, &a.Attended, &a.CheckedInAt)
+		if err != nil {
+			return err
+		}
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func ChangeEventFollowerCount(ctx context.Context, db DBTX, eventId, by int64) error {
+	query := qb.Update("events").
+		Set("follower_count", sq.Expr("follower_count + ?", by)).
+		Where(sq.Eq{"id": eventId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, args...)
+	return err
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const InvitationsTable = "event_manager_invitations"
+
+func CreateManagerInvitation(ctx context.Context, db DBTX, inv models.ManagerInvitation) (models.ManagerInvitation, error) {
+	query := qb.Insert(InvitationsTable).
+		Columns("event_id", "user_id", "role_id", "invited_by", "status", "expires_at").
+		Values(inv.EventID, inv.UserID, inv.Role.ID, inv.InvitedBy, pkg.InvitationStatusPending, inv.ExpiresAt).
+		Suffix("returning id, created_at")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return models.ManagerInvitation{}, err
+	}
+
+	inv.Status = pkg.InvitationStatusPending
+	err = db.QueryRow(ctx, stmt, args...).Scan(&inv.ID, &inv.CreatedAt)
+	return inv, err
+}
+
+func HasPendingInvitation(ctx context.Context, db DBTX, eventId, userId int64) (bool, error) {
+	query := qb.Select("count(*)").
+		From(InvitationsTable).
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId}).
+		Where(sq.Eq{"status": pkg.InvitationStatusPending}).
+		Where(sq.Gt{"expires_at": time.Now()})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	err = db.QueryRow(ctx, stmt, args...).Scan(&count)
+	return count > 0, err
+}
+
+type CloseInvitationArgs struct {
+	ID     int64
+	Status int
+	// UserID restricts closing to the invitee, InvitedBy to the inviter.
+	UserID    *int64
+	InvitedBy *int64
+	EventID   *int64
+}
+
+// CloseInvitation moves a pending, unexpired invitation to the given status.
+// It returns nil when there is no such invitation.
+func CloseInvitation(ctx context.Context, db DBTX, args CloseInvitationArgs) (*models.ManagerInvitation, error) {
+	query := qb.Update(InvitationsTable).
+		Set("status", args.Status).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": args.ID}).
+		Where(sq.Eq{"status": pkg.InvitationStatusPending}).
+		Where(sq.Gt{"expires_at": time.Now()}).
+		Suffix("returning id, event_id, user_id, role_id, invited_by, status, expires_at, created_at")
+
+	if args.UserID != nil {
+		query = query.Where(sq.Eq{"user_id": *args.UserID})
+	}
+	if args.InvitedBy != nil {
+		query = query.Where(sq.Eq{"invited_by": *args.InvitedBy})
+	}
+	if args.EventID != nil {
+		query = query.Where(sq.Eq{"event_id": *args.EventID})
+	}
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var inv models.ManagerInvitation
+	err = db.QueryRow(ctx, stmt, params...).Scan(&inv.ID, &inv.EventID, &inv.UserID, &inv.Role.ID, &inv.InvitedBy, &inv.Status, &inv.ExpiresAt, &inv.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	inv.Role.EventID = inv.EventID
+
+	return &inv, nil
+}
+
+type GetInvitationsArgs struct {
+	EventID *int64
+	UserID  *int64
+}
+
+// GetPendingInvitations lists the unexpired pending invitations of an event
+// or of an invitee.
+func GetPendingInvitations(ctx context.Context, db DBTX, args GetInvitationsArgs) ([]models.ManagerInvitation, error) {
+	query := qb.Select(
+		"i.id",
+		"i.event_id",
+		"events.title",
+		"i.user_id",
+		"users.username",
+		"i.invited_by",
+		"i.role_id",
+		"event_roles.name",
+		"i.status",
+		"i.expires_at",
+		"i.created_at",
+	).
+		From(InvitationsTable + " i").
+		InnerJoin("events on events.id = i.event_id").
+		InnerJoin("users on users.id = i.user_id").
+		InnerJoin("event_roles on event_roles.id = i.role_id").
+		Where(sq.Eq{"i.status": pkg.InvitationStatusPending}).
+		Where(sq.Gt{"i.expires_at": time.Now()}).
+		OrderBy("i.id desc")
+
+	if args.EventID != nil {
+		query = query.Where(sq.Eq{"i.event_id": *args.EventID})
+	}
+	if args.UserID != nil {
+		query = query.Where(sq.Eq{"i.user_id": *args.UserID})
+	}
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var invitations []models.ManagerInvitation
+	for rows.Next() {
+		var inv models.ManagerInvitation
+		err := rows.Scan(&inv.ID, &inv.EventID, &inv.EventTitle, &inv.UserID, &inv.Username, &inv.InvitedBy,
+			&inv.Role.ID, &inv.Role.Name, &inv.Status, &inv.ExpiresAt, &inv.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		inv.Role.EventID = inv.EventID
+		invitations = append(invitations, inv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range invitations {
+		invitations[i].Role.Permissions, err = GetRolePermissions(ctx, db, invitations[i].Role.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return invitations, nil
+}
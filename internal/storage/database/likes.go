@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+)
+
+func CheckEventLike(ctx context.Context, db DBTX, eventId, userId int64) (bool, error) {
+	query := qb.Select("count(*)").
+		From("event_like").
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = db.QueryRow(ctx, stmt, args...).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count != 0, nil
+}
+
+func AddEventLike(ctx context.Context, db DBTX, eventId, userId int64) (bool, error) {
+	query := qb.Insert("event_like").
+		Columns("event_id", "user_id").
+		Values(eventId, userId).
+		Suffix("on conflict do nothing")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := db.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+func RemoveEventLike(ctx context.Context, db DBTX, eventId, userId int64) (bool, error) {
+	query := qb.Delete("event_like").
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := db.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+func ChangeEventLikeCount(ctx context.Context, db DBTX, eventId, by int64) error {
+	query := qb.Update("events").
+		Set("like_count", sq.Expr("like_count + ?", by)).
+		Where(sq.Eq{"id": eventId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, args...)
+	return err
+}
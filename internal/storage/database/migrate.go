@@ -0,0 +1,277 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+const (
+	MigrationsTable = "schema_migrations"
+
+	// migrationLockKey is the pg_advisory_xact_lock key, so two replicas starting
+	// at the same time do not apply the same migration twice.
+	migrationLockKey = 7_260_301
+)
+
+var (
+	ErrNoMigrations     = errors.New("no migrations to apply")
+	ErrUnknownMigration = errors.New("database version is not known to this binary")
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// LoadMigrations reads the embedded migrations. Files are named
+// <version>_<name>.up.sql and <version>_<name>.down.sql.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("unexpected migration file %s", name)
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		versionStr, title, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration file %s has no name", name)
+		}
+
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s has invalid version: %w", name, err)
+		}
+
+		body, err := migrationsFS.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		}
+		if m.Name != title {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, title)
+		}
+
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (d *Database) ensureMigrationsTable(ctx context.Context) error {
+	query := `create table if not exists ` + MigrationsTable + ` (
+		version    bigint primary key,
+		name       text        not null,
+		applied_at timestamptz not null default now()
+	)`
+
+	_, err := d.db.Exec(ctx, query)
+	return err
+}
+
+// MigrationVersion returns the latest applied migration version, 0 when the
+// database is empty.
+func (d *Database) MigrationVersion(ctx context.Context) (int64, error) {
+	if err := d.ensureMigrationsTable(ctx); err != nil {
+		return 0, err
+	}
+
+	return currentVersion(ctx, d.db)
+}
+
+func currentVersion(ctx context.Context, db DBTX) (int64, error) {
+	query := `select coalesce(max(version), 0) from ` + MigrationsTable
+
+	var version int64
+	err := db.QueryRow(ctx, query).Scan(&version)
+	return version, err
+}
+
+// MigrateUp applies up to steps pending migrations, all of them when steps <= 0.
+func (d *Database) MigrateUp(ctx context.Context, steps int) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+
+	for _, m := range migrations {
+		if steps > 0 && len(applied) == steps {
+			break
+		}
+
+		ok, err := d.applyMigration(ctx, m, true)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if ok {
+			log.Printf("migrated up %d_%s", m.Version, m.Name)
+			applied = append(applied, m)
+		}
+	}
+
+	if len(applied) == 0 {
+		return nil, ErrNoMigrations
+	}
+
+	return applied, nil
+}
+
+// MigrateDown rolls back steps applied migrations, starting from the latest.
+func (d *Database) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+
+	known := map[int64]Migration{}
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+
+	var reverted []Migration
+
+	for len(reverted) < steps {
+		version, err := currentVersion(ctx, d.db)
+		if err != nil {
+			return reverted, err
+		}
+		if version == 0 {
+			break
+		}
+
+		m, ok := known[version]
+		if !ok {
+			return reverted, fmt.Errorf("%w: %d", ErrUnknownMigration, version)
+		}
+
+		ok, err = d.applyMigration(ctx, m, false)
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if ok {
+			log.Printf("migrated down %d_%s", m.Version, m.Name)
+			reverted = append(reverted, m)
+		}
+	}
+
+	if len(reverted) == 0 {
+		return nil, ErrNoMigrations
+	}
+
+	return reverted, nil
+}
+
+// applyMigration runs a single migration and records it in one transaction.
+// It reports false when another process already applied (or reverted) it.
+func (d *Database) applyMigration(ctx context.Context, m Migration, up bool) (bool, error) {
+	tx, err := d.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `select pg_advisory_xact_lock($1)`, migrationLockKey); err != nil {
+		return false, err
+	}
+
+	var count int
+	err = tx.QueryRow(ctx, `select count(*) from `+MigrationsTable+` where version = $1`, m.Version).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	if up && count > 0 || !up && count == 0 {
+		return false, nil
+	}
+
+	if up {
+		if _, err := tx.Exec(ctx, m.Up); err != nil {
+			return false, err
+		}
+		_, err = tx.Exec(ctx, `insert into `+MigrationsTable+` (version, name) values ($1, $2)`, m.Version, m.Name)
+	} else {
+		if m.Down == "" {
+			return false, fmt.Errorf("migration has no down file")
+		}
+		if _, err := tx.Exec(ctx, m.Down); err != nil {
+			return false, err
+		}
+		_, err = tx.Exec(ctx, `delete from `+MigrationsTable+` where version = $1`, m.Version)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
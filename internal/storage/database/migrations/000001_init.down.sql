@@ -0,0 +1,21 @@
+drop table if exists one_time_passwords;
+drop table if exists tokens;
+drop table if exists chat_messages;
+drop table if exists chat_members;
+drop table if exists comments;
+drop table if exists event_like;
+drop table if exists banned_event_followers;
+drop table if exists event_followers;
+drop table if exists event_managers;
+drop table if exists event_role_permissions;
+drop table if exists event_roles;
+drop table if exists permissions;
+drop table if exists event_categories;
+drop table if exists event_images;
+drop table if exists event_locations;
+drop table if exists events;
+drop table if exists banned_user_followers;
+drop table if exists user_followers;
+drop table if exists user_preferences;
+drop table if exists categories;
+drop table if exists users;
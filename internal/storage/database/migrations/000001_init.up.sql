@@ -0,0 +1,227 @@
+create table if not exists users
+(
+    id             bigserial primary key,
+    phone          varchar(20) not null unique,
+    password       text        not null,
+    username       varchar(64) unique,
+    firstname      text        not null default '',
+    lastname       text,
+    birthdate      date,
+    profile_image  text,
+    follower_count bigint      not null default 0,
+    created_at     timestamptz not null default now(),
+    updated_at     timestamptz not null default now(),
+    deleted_at     timestamptz
+);
+
+create table if not exists categories
+(
+    id         bigserial primary key,
+    name       text        not null,
+    created_at timestamptz not null default now(),
+    deleted_at timestamptz
+);
+
+create table if not exists user_preferences
+(
+    user_id     bigint not null references users (id) on delete cascade,
+    category_id bigint not null references categories (id) on delete cascade,
+    primary key (user_id, category_id)
+);
+
+create table if not exists user_followers
+(
+    user_id     bigint      not null references users (id) on delete cascade,
+    follower_id bigint      not null references users (id) on delete cascade,
+    created_at  timestamptz not null default now(),
+    primary key (user_id, follower_id)
+);
+
+create table if not exists banned_user_followers
+(
+    user_id     bigint      not null references users (id) on delete cascade,
+    follower_id bigint      not null references users (id) on delete cascade,
+    created_at  timestamptz not null default now(),
+    primary key (user_id, follower_id)
+);
+
+create table if not exists events
+(
+    id             bigserial primary key,
+    title          text        not null,
+    description    text        not null default '',
+    age_min        bigint,
+    age_max        bigint,
+    price          bigint,
+    status         int         not null default 1,
+    follower_count bigint      not null default 0,
+    like_count     bigint      not null default 0,
+    created_at     timestamptz not null default now(),
+    updated_at     timestamptz not null default now(),
+    deleted_at     timestamptz
+);
+
+create table if not exists event_locations
+(
+    id              bigserial primary key,
+    event_id        bigint           not null references events (id) on delete cascade,
+    address         text,
+    longitude       double precision,
+    latitude        double precision,
+    seats           bigint,
+    attendees_count bigint           not null default 0,
+    starts_at       timestamptz      not null,
+    ends_at         timestamptz      not null,
+    created_at      timestamptz      not null default now(),
+    updated_at      timestamptz      not null default now(),
+    deleted_at      timestamptz
+);
+
+create index if not exists event_locations_event_id_idx on event_locations (event_id);
+create index if not exists event_locations_starts_at_idx on event_locations (starts_at);
+
+create table if not exists event_images
+(
+    id         bigserial primary key,
+    event_id   bigint      not null references events (id) on delete cascade,
+    url        text        not null,
+    created_at timestamptz not null default now(),
+    deleted_at timestamptz
+);
+
+create index if not exists event_images_event_id_idx on event_images (event_id);
+
+create table if not exists event_categories
+(
+    event_id    bigint not null references events (id) on delete cascade,
+    category_id bigint not null references categories (id) on delete cascade,
+    primary key (event_id, category_id)
+);
+
+create table if not exists permissions
+(
+    id   bigint primary key,
+    name text not null
+);
+
+insert into permissions (id, name)
+values (1, 'read'),
+       (2, 'update'),
+       (3, 'verify')
+on conflict (id) do nothing;
+
+create table if not exists event_roles
+(
+    id         bigserial primary key,
+    event_id   bigint      not null references events (id) on delete cascade,
+    name       text        not null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create table if not exists event_role_permissions
+(
+    role_id       bigint not null references event_roles (id) on delete cascade,
+    permission_id bigint not null references permissions (id),
+    primary key (role_id, permission_id)
+);
+
+create table if not exists event_managers
+(
+    id         bigserial primary key,
+    event_id   bigint      not null references events (id) on delete cascade,
+    user_id    bigint      not null references users (id) on delete cascade,
+    role_id    bigint      not null references event_roles (id),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    deleted_at timestamptz
+);
+
+create index if not exists event_managers_event_id_idx on event_managers (event_id);
+create index if not exists event_managers_user_id_idx on event_managers (user_id);
+
+create table if not exists event_followers
+(
+    event_id   bigint      not null references events (id) on delete cascade,
+    user_id    bigint      not null references users (id) on delete cascade,
+    attended   boolean     not null default false,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    primary key (event_id, user_id)
+);
+
+create index if not exists event_followers_user_id_idx on event_followers (user_id);
+
+create table if not exists banned_event_followers
+(
+    event_id    bigint      not null references events (id) on delete cascade,
+    follower_id bigint      not null references users (id) on delete cascade,
+    created_at  timestamptz not null default now(),
+    primary key (event_id, follower_id)
+);
+
+create table if not exists event_like
+(
+    event_id   bigint      not null references events (id) on delete cascade,
+    user_id    bigint      not null references users (id) on delete cascade,
+    created_at timestamptz not null default now(),
+    primary key (event_id, user_id)
+);
+
+create table if not exists comments
+(
+    id         bigserial primary key,
+    parent_id  bigint references comments (id) on delete cascade,
+    event_id   bigint      not null references events (id) on delete cascade,
+    author_id  bigint      not null references users (id) on delete cascade,
+    text       text        not null,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists comments_event_id_idx on comments (event_id);
+create index if not exists comments_parent_id_idx on comments (parent_id);
+
+create table if not exists chat_members
+(
+    event_id   bigint      not null references events (id) on delete cascade,
+    user_id    bigint      not null references users (id) on delete cascade,
+    role_id    bigint      not null default 0,
+    created_at timestamptz not null default now(),
+    primary key (event_id, user_id)
+);
+
+create table if not exists chat_messages
+(
+    id         bigserial primary key,
+    event_id   bigint      not null references events (id) on delete cascade,
+    user_id    bigint      not null references users (id) on delete cascade,
+    messages   text        not null,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists chat_messages_event_id_idx on chat_messages (event_id, id);
+
+create table if not exists tokens
+(
+    token      text primary key,
+    phone      varchar(20),
+    user_id    bigint references users (id) on delete cascade,
+    token_type int         not null,
+    user_agent text,
+    expires_at timestamptz not null,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists tokens_user_id_idx on tokens (user_id, token_type);
+
+create table if not exists one_time_passwords
+(
+    id         bigserial primary key,
+    phone      varchar(20) not null,
+    code       text        not null,
+    type       int         not null,
+    expires_at timestamptz not null,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists one_time_passwords_phone_idx on one_time_passwords (phone, type);
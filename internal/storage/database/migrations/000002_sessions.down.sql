@@ -0,0 +1,7 @@
+drop index if exists tokens_session_id_idx;
+
+alter table tokens
+    drop column if exists last_used_at,
+    drop column if exists ip,
+    drop column if exists device_name,
+    drop column if exists session_id;
@@ -0,0 +1,13 @@
+alter table tokens
+    add column if not exists session_id   text,
+    add column if not exists device_name  text,
+    add column if not exists ip           text,
+    add column if not exists last_used_at timestamptz;
+
+update tokens
+set session_id   = token,
+    last_used_at = created_at
+where session_id is null
+  and token_type = 1;
+
+create index if not exists tokens_session_id_idx on tokens (user_id, session_id);
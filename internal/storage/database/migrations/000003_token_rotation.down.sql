@@ -0,0 +1,5 @@
+delete from tokens where rotated_at is not null;
+
+alter table tokens
+    drop column if exists session_started_at,
+    drop column if exists rotated_at;
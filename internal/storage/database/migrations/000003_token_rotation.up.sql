@@ -0,0 +1,8 @@
+alter table tokens
+    add column if not exists rotated_at         timestamptz,
+    add column if not exists session_started_at timestamptz;
+
+update tokens
+set session_started_at = created_at
+where session_id is not null
+  and session_started_at is null;
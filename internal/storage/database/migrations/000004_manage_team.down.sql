@@ -0,0 +1,5 @@
+drop index if exists event_managers_event_id_user_id_idx;
+drop index if exists event_roles_event_id_name_idx;
+
+delete from event_role_permissions where permission_id = 4;
+delete from permissions where id = 4;
@@ -0,0 +1,12 @@
+insert into permissions (id, name)
+values (4, 'manage_team')
+on conflict (id) do nothing;
+
+insert into event_role_permissions (role_id, permission_id)
+select id, 4
+from event_roles
+where name = 'Author'
+on conflict do nothing;
+
+create unique index if not exists event_roles_event_id_name_idx on event_roles (event_id, name);
+create unique index if not exists event_managers_event_id_user_id_idx on event_managers (event_id, user_id) where deleted_at is null;
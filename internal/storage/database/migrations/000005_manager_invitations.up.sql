@@ -0,0 +1,15 @@
+create table if not exists event_manager_invitations
+(
+    id         bigserial primary key,
+    event_id   bigint      not null references events (id) on delete cascade,
+    user_id    bigint      not null references users (id) on delete cascade,
+    role_id    bigint      not null references event_roles (id) on delete cascade,
+    invited_by bigint      not null references users (id) on delete cascade,
+    status     smallint    not null default 1,
+    expires_at timestamptz not null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create index if not exists event_manager_invitations_event_id_idx on event_manager_invitations (event_id);
+create index if not exists event_manager_invitations_user_id_idx on event_manager_invitations (user_id);
@@ -0,0 +1,2 @@
+drop index if exists events_status_idx;
+drop table if exists event_status_history;
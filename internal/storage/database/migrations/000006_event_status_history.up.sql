@@ -0,0 +1,13 @@
+create table if not exists event_status_history
+(
+    id          bigserial primary key,
+    event_id    bigint      not null references events (id) on delete cascade,
+    from_status int         not null,
+    to_status   int         not null,
+    changed_by  bigint references users (id) on delete set null,
+    reason      text,
+    created_at  timestamptz not null default now()
+);
+
+create index if not exists event_status_history_event_id_idx on event_status_history (event_id);
+create index if not exists events_status_idx on events (status) where deleted_at is null;
@@ -0,0 +1,4 @@
+drop table if exists user_devices;
+
+alter table chat_messages
+    drop column if exists kind;
@@ -0,0 +1,13 @@
+alter table chat_messages
+    add column if not exists kind smallint not null default 0;
+
+create table if not exists user_devices
+(
+    token      text primary key,
+    user_id    bigint      not null references users (id) on delete cascade,
+    platform   text,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create index if not exists user_devices_user_id_idx on user_devices (user_id);
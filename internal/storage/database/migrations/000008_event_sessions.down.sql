@@ -0,0 +1,4 @@
+drop index if exists event_followers_location_id_idx;
+
+alter table event_followers
+    drop column if exists location_id;
@@ -0,0 +1,16 @@
+alter table event_followers
+    add column if not exists location_id bigint references event_locations (id) on delete set null;
+
+update event_followers f
+set location_id = (select l.id
+                   from event_locations l
+                   where l.event_id = f.event_id
+                     and l.deleted_at is null
+                   order by l.starts_at, l.id
+                   limit 1)
+where f.location_id is null;
+
+update event_locations l
+set attendees_count = (select count(*) from event_followers f where f.location_id = l.id);
+
+create index if not exists event_followers_location_id_idx on event_followers (location_id);
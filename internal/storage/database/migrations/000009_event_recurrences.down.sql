@@ -0,0 +1,8 @@
+drop index if exists event_locations_recurrence_idx;
+
+alter table event_locations
+    drop column if exists detached,
+    drop column if exists occurrence_at,
+    drop column if exists recurrence_id;
+
+drop table if exists event_recurrences;
@@ -0,0 +1,28 @@
+create table if not exists event_recurrences
+(
+    id                 bigserial primary key,
+    event_id           bigint      not null references events (id) on delete cascade,
+    rule               text        not null,
+    starts_at          timestamptz not null,
+    ends_at            timestamptz not null,
+    address            text,
+    longitude          double precision,
+    latitude           double precision,
+    seats              bigint,
+    exceptions         timestamptz[] not null default '{}',
+    materialized_until timestamptz not null,
+    created_at         timestamptz not null default now(),
+    updated_at         timestamptz not null default now(),
+    deleted_at         timestamptz
+);
+
+create index if not exists event_recurrences_event_id_idx on event_recurrences (event_id);
+create index if not exists event_recurrences_materialized_until_idx on event_recurrences (materialized_until)
+    where deleted_at is null;
+
+alter table event_locations
+    add column if not exists recurrence_id bigint references event_recurrences (id) on delete set null,
+    add column if not exists occurrence_at timestamptz,
+    add column if not exists detached      boolean not null default false;
+
+create index if not exists event_locations_recurrence_idx on event_locations (recurrence_id, occurrence_at);
@@ -0,0 +1,11 @@
+create table if not exists event_waitlist
+(
+    id          bigserial primary key,
+    event_id    bigint      not null references events (id) on delete cascade,
+    location_id bigint      not null references event_locations (id) on delete cascade,
+    user_id     bigint      not null references users (id) on delete cascade,
+    created_at  timestamptz not null default now(),
+    unique (event_id, user_id)
+);
+
+create index if not exists event_waitlist_location_id_idx on event_waitlist (location_id, id);
@@ -0,0 +1,21 @@
+create table if not exists orders
+(
+    id          bigserial primary key,
+    event_id    bigint      not null references events (id) on delete cascade,
+    location_id bigint      not null references event_locations (id) on delete cascade,
+    user_id     bigint      not null references users (id) on delete cascade,
+    amount      bigint      not null,
+    currency    text        not null,
+    status      smallint    not null default 1,
+    provider    text,
+    payment_id  text unique,
+    expires_at  timestamptz not null,
+    paid_at     timestamptz,
+    refunded_at timestamptz,
+    created_at  timestamptz not null default now(),
+    updated_at  timestamptz not null default now()
+);
+
+-- a user holds at most one pending or paid order per event
+create unique index if not exists orders_event_user_live_idx on orders (event_id, user_id) where status in (1, 2);
+create index if not exists orders_pending_expires_at_idx on orders (expires_at) where status = 1;
@@ -0,0 +1,7 @@
+alter table orders
+    drop column if exists ticket_type_id,
+    drop column if exists promo_code_id,
+    drop column if exists discount;
+
+drop table if exists promo_codes;
+drop table if exists ticket_types;
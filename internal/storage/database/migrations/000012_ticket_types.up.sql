@@ -0,0 +1,37 @@
+create table if not exists ticket_types
+(
+    id          bigserial primary key,
+    event_id    bigint      not null references events (id) on delete cascade,
+    name        text        not null,
+    price       bigint      not null default 0,
+    quota       bigint,
+    sold_count  bigint      not null default 0,
+    sales_start timestamptz,
+    sales_end   timestamptz,
+    created_at  timestamptz not null default now(),
+    updated_at  timestamptz not null default now(),
+    deleted_at  timestamptz
+);
+
+create index if not exists ticket_types_event_id_idx on ticket_types (event_id) where deleted_at is null;
+
+create table if not exists promo_codes
+(
+    id         bigserial primary key,
+    event_id   bigint      not null references events (id) on delete cascade,
+    code       text        not null,
+    kind       smallint    not null,
+    value      bigint      not null,
+    max_uses   bigint,
+    used_count bigint      not null default 0,
+    expires_at timestamptz,
+    created_at timestamptz not null default now(),
+    deleted_at timestamptz
+);
+
+create unique index if not exists promo_codes_event_code_idx on promo_codes (event_id, lower(code)) where deleted_at is null;
+
+alter table orders
+    add column if not exists ticket_type_id bigint references ticket_types (id),
+    add column if not exists promo_code_id  bigint references promo_codes (id),
+    add column if not exists discount       bigint not null default 0;
@@ -0,0 +1,5 @@
+drop table if exists check_ins;
+
+alter table event_followers
+    drop column if exists checked_in_at,
+    drop column if exists checked_in_by;
@@ -0,0 +1,17 @@
+alter table event_followers
+    add column if not exists checked_in_at timestamptz,
+    add column if not exists checked_in_by bigint references users (id) on delete set null;
+
+create table if not exists check_ins
+(
+    id          bigserial primary key,
+    event_id    bigint      not null references events (id) on delete cascade,
+    location_id bigint,
+    user_id     bigint,
+    scanned_by  bigint      not null,
+    result      smallint    not null,
+    reason      text,
+    created_at  timestamptz not null default now()
+);
+
+create index if not exists check_ins_event_id_idx on check_ins (event_id, id);
@@ -0,0 +1,2 @@
+delete from event_role_permissions where permission_id = 5;
+delete from permissions where id = 5;
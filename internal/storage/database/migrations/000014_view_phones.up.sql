@@ -0,0 +1,9 @@
+insert into permissions (id, name)
+values (5, 'view_phones')
+on conflict (id) do nothing;
+
+insert into event_role_permissions (role_id, permission_id)
+select id, 5
+from event_roles
+where name = 'Author'
+on conflict do nothing;
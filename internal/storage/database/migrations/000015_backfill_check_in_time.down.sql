@@ -0,0 +1 @@
+-- backfilled check-in times cannot be told apart from recorded ones
@@ -0,0 +1,6 @@
+-- followers checked in before check-in times were recorded are not let in a
+-- second time
+update event_followers
+set checked_in_at = updated_at
+where attended
+  and checked_in_at is null;
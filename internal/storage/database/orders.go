@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const OrdersTable = "orders"
+
+// CreateOrder returns the id of the new order, false when the user already
+// has a pending or paid order for the event.
+func CreateOrder(ctx context.Context, db DBTX, order models.Order) (int64, bool, error) {
+	query := qb.Insert(OrdersTable).
+		Columns("event_id", "location_id", "user_id", "amount", "currency", "status", "ticket_type_id", "promo_code_id",
+			"discount", "expires_at").
+		Values(order.EventID, order.LocationID, order.UserID, order.Amount, order.Currency, order.Status, order.TicketTypeID,
+			order.PromoCodeID, order.Discount, order.ExpiresAt).
+		Suffix("on conflict (event_id, user_id) where status in (1, 2) do nothing returning id")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return 0, false, err
+	}
+
+	var id int64
+	err = db.QueryRow(ctx, stmt, args...).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return id, true, nil
+}
+
+func ordersQuery() sq.SelectBuilder {
+	return qb.Select("id", "event_id", "location_id", "user_id", "amount", "currency", "status", "ticket_type_id",
+		"promo_code_id", "discount", "provider", "payment_id", "expires_at", "paid_at", "created_at").
+		From(OrdersTable)
+}
+
+func scanOrder(row pgx.Row) (models.Order, error) {
+	var o models.Order
+	err := row.Scan(&o.ID, &o.EventID, &o.LocationID, &o.UserID, &o.Amount, &o.Currency, &o.Status, &o.TicketTypeID,
+		&o.PromoCodeID, &o.Discount, &o.Provider, &o.PaymentID, &o.ExpiresAt, &o.PaidAt, &o.CreatedAt)
+	return o, err
+}
+
+// GetOrder returns nil when the order does not exist.
+func GetOrder(ctx context.Context, db DBTX, orderId int64) (*models.Order, error) {
+	return getOrder(ctx, db, ordersQuery().Where(sq.Eq{"id": orderId}))
+}
+
+// GetOrderByPayment returns nil when no order has the payment.
+func GetOrderByPayment(ctx context.Context, db DBTX, paymentId string) (*models.Order, error) {
+	return getOrder(ctx, db, ordersQuery().Where(sq.Eq{"payment_id": paymentId}))
+}
+
+func getOrder(ctx context.Context, db DBTX, query sq.SelectBuilder) (*models.Order, error) {
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := scanOrder(db.QueryRow(ctx, stmt, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func SetOrderPayment(ctx context.Context, db DBTX, orderId int64, provider, paymentId string) error {
+	query := qb.Update(OrdersTable).
+		Set("provider", provider).
+		Set("payment_id", paymentId).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": orderId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, args...)
+	return err
+}
+
+// SetOrderStatus moves the order to status when it is in one of the from
+// statuses, false when it is not.
+func SetOrderStatus(ctx context.Context, db DBTX, orderId int64, status int, from ...int) (bool, error) {
+	query := qb.Update(OrdersTable).
+		Set("status", status).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": orderId}).
+		Where(sq.Eq{"status": from})
+
+	switch status {
+	case pkg.OrderStatusPaid:
+		query = query.Set("paid_at", time.Now())
+	case pkg.OrderStatusRefunded:
+		query = query.Set("refunded_at", time.Now())
+	}
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := db.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// GetExpiredOrders returns the pending orders whose hold ended before t.
+func GetExpiredOrders(ctx context.Context, db DBTX, t time.Time) ([]models.Order, error) {
+	return getOrders(ctx, db, ordersQuery().
+		Where(sq.Eq{"status": pkg.OrderStatusPending}).
+		Where(sq.Lt{"expires_at": t}).
+		OrderBy("id"))
+}
+
+func GetEventOrders(ctx context.Context, db DBTX, eventId int64, statuses ...int) ([]models.Order, error) {
+	return getOrders(ctx, db, ordersQuery().
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"status": statuses}).
+		OrderBy("id"))
+}
+
+func getOrders(ctx context.Context, db DBTX, query sq.SelectBuilder) ([]models.Order, error) {
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []models.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+
+	return orders, rows.Err()
+}
+
+// GetUserOrder returns the order of the user for the event in one of the
+// statuses, nil when there is none.
+func GetUserOrder(ctx context.Context, db DBTX, eventId, userId int64, statuses ...int) (*models.Order, error) {
+	return getOrder(ctx, db, ordersQuery().
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId}).
+		Where(sq.Eq{"status": statuses}).
+		OrderBy("id desc").
+		Limit(1))
+}
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"context"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const RecurrencesTable = "event_recurrences"
+
+func CreateRecurrence(ctx context.Context, db DBTX, r models.Recurrence) (int64, error) {
+	query := qb.Insert(RecurrencesTable).
+		Columns("event_id", "rule", "starts_at", "ends_at", "address", "longitude", "latitude", "seats", "exceptions", "materialized_until").
+		Values(r.EventID, r.Rule, r.StartsAt, r.EndsAt, r.Address, r.Longitude, r.Latitude, r.Seats, exceptions(r.Exceptions), r.MaterializedUntil).
+		Suffix("returning id")
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = db.QueryRow(ctx, stmt, params...).Scan(&id)
+	return id, err
+}
+
+// LockRecurrence returns the recurrence and locks it until the transaction
+// ends, nil when it does not exist.
+func LockRecurrence(ctx context.Context, db DBTX, recurrenceId int64) (*models.Recurrence, error) {
+	query := recurrenceQuery().
+		Where(sq.Eq{"id": recurrenceId}).
+		Suffix("for update")
+
+	return getRecurrence(ctx, db, query)
+}
+
+// GetEventRecurrence returns the recurrence of the event, nil for events
+// which do not recur.
+func GetEventRecurrence(ctx context.Context, db DBTX, eventId int64) (*models.Recurrence, error) {
+	query := recurrenceQuery().
+		Where(sq.Eq{"event_id": eventId}).
+		OrderBy("id desc").
+		Limit(1)
+
+	return getRecurrence(ctx, db, query)
+}
+
+func recurrenceQuery() sq.SelectBuilder {
+	return qb.Select("id", "event_id", "rule", "starts_at", "ends_at", "address", "longitude", "latitude", "seats",
+		"exceptions", "materialized_until").
+		From(RecurrencesTable).
+		Where(sq.Eq{"deleted_at": nil})
+}
+
+func getRecurrence(ctx context.Context, db DBTX, query sq.SelectBuilder) (*models.Recurrence, error) {
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var r models.Recurrence
+	err = db.QueryRow(ctx, stmt, params...).Scan(&r.ID, &r.EventID, &r.Rule, &r.StartsAt, &r.EndsAt, &r.Address,
+		&r.Longitude, &r.Latitude, &r.Seats, &r.Exceptions, &r.MaterializedUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func UpdateRecurrence(ctx context.Context, db DBTX, r models.Recurrence) error {
+	query := qb.Update(RecurrencesTable).
+		Set("rule", r.Rule).
+		Set("starts_at", r.StartsAt).
+		Set("ends_at", r.EndsAt).
+		Set("address", r.Address).
+		Set("longitude", r.Longitude).
+		Set("latitude", r.Latitude).
+		Set("seats", r.Seats).
+		Set("exceptions", exceptions(r.Exceptions)).
+		Set("materialized_until", r.MaterializedUntil).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": r.ID})
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, params...)
+	return err
+}
+
+// GetDueRecurrences returns the recurrences of live events which are not
+// materialized up to horizon yet.
+func GetDueRecurrences(ctx context.Context, db DBTX, horizon time.Time) ([]int64, error) {
+	query := qb.Select(RecurrencesTable + ".id").
+		From(RecurrencesTable).
+		InnerJoin("events on events.id = " + RecurrencesTable + ".event_id").
+		Where(sq.Lt{RecurrencesTable + ".materialized_until": horizon}).
+		Where(sq.Eq{RecurrencesTable + ".deleted_at": nil}).
+		Where(sq.Eq{"events.deleted_at": nil}).
+		Where(sq.Eq{"events.status": []int{pkg.EventStatusCreated, pkg.EventStatusOngoing}})
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// AddOccurrences adds a location per start from the recurrence template.
+func AddOccurrences(ctx context.Context, db DBTX, r models.Recurrence, starts ...time.Time) error {
+	if len(starts) == 0 {
+		return nil
+	}
+
+	duration := r.EndsAt.Sub(r.StartsAt)
+
+	query := qb.Insert("event_locations").
+		Columns("event_id", "address", "longitude", "latitude", "seats", "starts_at", "ends_at", "recurrence_id", "occurrence_at")
+
+	for _, s := range starts {
+		query = query.Values(r.EventID, r.Address, r.Longitude, r.Latitude, r.Seats, s, s.Add(duration), r.ID, s)
+	}
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, params...)
+	return err
+}
+
+// UpdateFutureOccurrences applies the fields set on location to the
+// occurrences of the recurrence from the occurrence at from on, leaving the
+// detached ones alone. Their start moves by shift and they last duration.
+func UpdateFutureOccurrences(ctx context.Context, db DBTX, recurrenceId int64, from time.Time, shift, duration time.Duration, location models.Location) error {
+	location.StartsAt, location.EndsAt = nil, nil
+	m := locationFields(location)
+
+	m["starts_at"] = sq.Expr("starts_at + make_interval(secs => ?)", shift.Seconds())
+	m["ends_at"] = sq.Expr("starts_at + make_interval(secs => ?)", (shift + duration).Seconds())
+	m["occurrence_at"] = sq.Expr("occurrence_at + make_interval(secs => ?)", shift.Seconds())
+	m["updated_at"] = time.Now()
+
+	query := qb.Update("event_locations").SetMap(m).
+		Where(sq.Eq{"recurrence_id": recurrenceId}).
+		Where(sq.GtOrEq{"occurrence_at": from}).
+		Where(sq.Eq{"detached": false}).
+		Where(sq.Eq{"deleted_at": nil})
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, params...)
+	return err
+}
+
+// DetachOccurrence keeps later edits of all future occurrences from
+// overwriting the occurrence.
+func DetachOccurrence(ctx context.Context, db DBTX, eventId, locationId int64) error {
+	query := qb.Update("event_locations").
+		Set("detached", true).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": locationId}).
+		Where(sq.Eq{"event_id": eventId})
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, params...)
+	return err
+}
+
+// exceptions keeps a recurrence without exceptions from being stored as null.
+func exceptions(t []time.Time) []time.Time {
+	if t == nil {
+		return []time.Time{}
+	}
+	return t
+}
@@ -2,9 +2,12 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/NuEventTeam/events/internal/models"
+	"github.com/jackc/pgx/v5"
+	"time"
 )
 
 func CreateRole(ctx context.Context, db DBTX, role models.Role) (int64, error) {
@@ -21,8 +24,12 @@ func CreateRole(ctx context.Context, db DBTX, role models.Role) (int64, error) {
 	return id, err
 }
 
-func UpdateRole(ctx context.Context, db DBTX, roleID int64, name string) error {
-	query := qb.Update("event_roles").Set("name", name)
+func UpdateRole(ctx context.Context, db DBTX, eventID, roleID int64, name string) error {
+	query := qb.Update("event_roles").
+		Set("name", name).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": roleID}).
+		Where(sq.Eq{"event_id": eventID})
 
 	stmt, parms, err := query.ToSql()
 	if err != nil {
@@ -41,6 +48,9 @@ func UpdateRole(ctx context.Context, db DBTX, roleID int64, name string) error {
 }
 
 func AddRolePermissions(ctx context.Context, db DBTX, role models.Role) error {
+	if len(role.Permissions) == 0 {
+		return nil
+	}
 
 	query := qb.Insert("event_role_permissions").
 		Columns("role_id", "permission_id")
@@ -59,6 +69,9 @@ func AddRolePermissions(ctx context.Context, db DBTX, role models.Role) error {
 }
 
 func RemoveRolePermissions(ctx context.Context, db DBTX, roleId int64, permissionID ...int64) error {
+	if len(permissionID) == 0 {
+		return nil
+	}
 	query := qb.Delete("event_role_permissions").
 		Where(sq.Eq{"role_id": roleId}).
 		Where(sq.Eq{"permission_id": permissionID[:]})
@@ -104,3 +117,73 @@ func GetRolePermissions(ctx context.Context, db DBTX, roleId int64) ([]int64, er
 
 	return permissions, nil
 }
+
+func GetEventRoles(ctx context.Context, db DBTX, eventId int64) ([]models.Role, error) {
+	query := qb.Select("id", "name").
+		From("event_roles").
+		Where(sq.Eq{"event_id": eventId}).
+		OrderBy("id")
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []models.Role
+	for rows.Next() {
+		r := models.Role{EventID: eventId}
+		err := rows.Scan(&r.ID, &r.Name)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range roles {
+		roles[i].Permissions, err = GetRolePermissions(ctx, db, roles[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return roles, nil
+}
+
+// GetRole returns the role of the event, nil when the event has no such role.
+func GetRole(ctx context.Context, db DBTX, eventId, roleId int64) (*models.Role, error) {
+	query := qb.Select("id", "name").
+		From("event_roles").
+		Where(sq.Eq{"id": roleId}).
+		Where(sq.Eq{"event_id": eventId})
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	role := models.Role{EventID: eventId}
+	err = db.QueryRow(ctx, stmt, params...).Scan(&role.ID, &role.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	role.Permissions, err = GetRolePermissions(ctx, db, role.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
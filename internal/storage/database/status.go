@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/NuEventTeam/events/pkg/ticketcode"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const StatusHistoryTable = "event_status_history"
+
+// LockEventStatus returns the status of the event and locks its row until the
+// transaction ends, nil when the event does not exist.
+func LockEventStatus(ctx context.Context, db DBTX, eventId int64) (*int, error) {
+	return getEventStatus(ctx, db, eventId, "for update")
+}
+
+// GetEventStatus returns the status of the event, nil when it does not exist.
+func GetEventStatus(ctx context.Context, db DBTX, eventId int64) (*int, error) {
+	return getEventStatus(ctx, db, eventId, "")
+}
+
+func getEventStatus(ctx context.Context, db DBTX, eventId int64, suffix string) (*int, error) {
+	query := qb.Select("status").
+		From("events").
+		Where(sq.Eq{"id": eventId}).
+		Where(sq.Eq{"deleted_at": nil}).
+		Suffix(suffix)
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var status int
+	err = db.QueryRow(ctx, stmt, params...).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+func SetEventStatus(ctx context.Context, db DBTX, eventId int64, status int) error {
+	query := qb.Update("events").
+		Set("status", status).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": eventId})
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, params...)
+	return err
+}
+
+func AddStatusHistory(ctx context.Context, db DBTX, change models.StatusChange) error {
+	query := qb.Insert(StatusHistoryTable).
+		Columns("event_id", "from_status", "to_status", "changed_by", "reason").
+		Values(change.EventID, change.From, change.To, change.ChangedBy, change.Reason)
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, params...)
+	return err
+}
+
+func GetStatusHistory(ctx context.Context, db DBTX, eventId int64) ([]models.StatusChange, error) {
+	query := qb.Select("id", "event_id", "from_status", "to_status", "changed_by", "reason", "created_at").
+		From(StatusHistoryTable).
+		Where(sq.Eq{"event_id": eventId}).
+		OrderBy("id")
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.StatusChange
+	for rows.Next() {
+		var c models.StatusChange
+		err := rows.Scan(&c.ID, &c.EventID, &c.From, &c.To, &c.ChangedBy, &c.Reason, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+
+	return history, rows.Err()
+}
+
+// GetTicketRevocations returns the revocations of the tickets of events
+// canceled after since, in the order they were canceled.
+func GetTicketRevocations(ctx context.Context, db DBTX, since time.Time) ([]ticketcode.Revocation, error) {
+	query := qb.Select("event_id", "created_at").
+		From(StatusHistoryTable).
+		Where(sq.Eq{"to_status": pkg.EventStatusCanceled}).
+		Where(sq.Gt{"created_at": since}).
+		OrderBy("created_at", "id")
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revocations := []ticketcode.Revocation{}
+	for rows.Next() {
+		var r ticketcode.Revocation
+		if err := rows.Scan(&r.EventID, &r.IssuedBefore); err != nil {
+			return nil, err
+		}
+		revocations = append(revocations, r)
+	}
+
+	return revocations, rows.Err()
+}
+
+// GetEventsStartedBefore returns the events in the status whose earliest
+// location starts before t.
+func GetEventsStartedBefore(ctx context.Context, db DBTX, status int, t time.Time) ([]int64, error) {
+	return getDueEvents(ctx, db, status, "min(event_locations.starts_at) <= ?", t)
+}
+
+// GetEventsEndedBefore returns the events in the status whose last location
+// ends before t.
+func GetEventsEndedBefore(ctx context.Context, db DBTX, status int, t time.Time) ([]int64, error) {
+	return getDueEvents(ctx, db, status, "max(event_locations.ends_at) <= ?", t)
+}
+
+func getDueEvents(ctx context.Context, db DBTX, status int, having string, t time.Time) ([]int64, error) {
+	query := qb.Select("events.id").
+		From("events").
+		InnerJoin("event_locations on event_locations.event_id = events.id").
+		Where(sq.Eq{"events.status": status}).
+		Where(sq.Eq{"events.deleted_at": nil}).
+		Where(sq.Eq{"event_locations.deleted_at": nil}).
+		GroupBy("events.id").
+		Having(having, t)
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,293 @@
+package database
+
+import (
+	"context"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/pkg"
+	"github.com/jackc/pgx/v5"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	TicketTypesTable = "ticket_types"
+	PromoCodesTable  = "promo_codes"
+)
+
+// minorUnits converts a price for storage, prices are kept in minor units of
+// the currency like the event price.
+func minorUnits(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
+
+func CreateTicketType(ctx context.Context, db DBTX, t models.TicketType) (int64, error) {
+	query := qb.Insert(TicketTypesTable).
+		Columns("event_id", "name", "price", "quota", "sales_start", "sales_end").
+		Values(t.EventID, t.Name, minorUnits(t.Price), t.Quota, t.SalesStart, t.SalesEnd).
+		Suffix("returning id")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = db.QueryRow(ctx, stmt, args...).Scan(&id)
+	return id, err
+}
+
+// UpdateTicketType returns false when the event has no such ticket type.
+func UpdateTicketType(ctx context.Context, db DBTX, t models.TicketType) (bool, error) {
+	query := qb.Update(TicketTypesTable).
+		Set("name", t.Name).
+		Set("price", minorUnits(t.Price)).
+		Set("quota", t.Quota).
+		Set("sales_start", t.SalesStart).
+		Set("sales_end", t.SalesEnd).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": t.ID}).
+		Where(sq.Eq{"event_id": t.EventID}).
+		Where(sq.Eq{"deleted_at": nil})
+
+	return execAffected(ctx, db, query)
+}
+
+func DeleteTicketType(ctx context.Context, db DBTX, eventId, typeId int64) (bool, error) {
+	query := qb.Update(TicketTypesTable).
+		Set("deleted_at", time.Now()).
+		Where(sq.Eq{"id": typeId}).
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"deleted_at": nil})
+
+	return execAffected(ctx, db, query)
+}
+
+func GetTicketTypes(ctx context.Context, db DBTX, eventId int64) ([]models.TicketType, error) {
+	query := qb.Select("id", "event_id", "name", "price", "quota", "sold_count", "sales_start", "sales_end").
+		From(TicketTypesTable).
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"deleted_at": nil}).
+		OrderBy("price", "id")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []models.TicketType
+	for rows.Next() {
+		t, err := scanTicketType(rows)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+
+	return types, rows.Err()
+}
+
+// GetTicketType returns the type even when it was deleted since, nil when it
+// does not exist.
+func GetTicketType(ctx context.Context, db DBTX, typeId int64) (*models.TicketType, error) {
+	query := qb.Select("id", "event_id", "name", "price", "quota", "sold_count", "sales_start", "sales_end").
+		From(TicketTypesTable).
+		Where(sq.Eq{"id": typeId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := scanTicketType(db.QueryRow(ctx, stmt, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func scanTicketType(row pgx.Row) (models.TicketType, error) {
+	var (
+		t     models.TicketType
+		price int64
+	)
+	err := row.Scan(&t.ID, &t.EventID, &t.Name, &price, &t.Quota, &t.SoldCount, &t.SalesStart, &t.SalesEnd)
+	t.Price = float64(price) / 100
+	return t, err
+}
+
+// ReserveTicketType takes one ticket of the quota of the type, false when it
+// is sold out.
+func ReserveTicketType(ctx context.Context, db DBTX, typeId int64) (bool, error) {
+	query := qb.Update(TicketTypesTable).
+		Set("sold_count", sq.Expr("sold_count + 1")).
+		Where(sq.Eq{"id": typeId}).
+		Where(sq.Eq{"deleted_at": nil}).
+		Where(sq.Or{
+			sq.Eq{"quota": nil},
+			sq.Expr("sold_count < quota"),
+		})
+
+	return execAffected(ctx, db, query)
+}
+
+func ReleaseTicketType(ctx context.Context, db DBTX, typeId int64) error {
+	query := qb.Update(TicketTypesTable).
+		Set("sold_count", sq.Expr("greatest(sold_count - 1, 0)")).
+		Where(sq.Eq{"id": typeId})
+
+	_, err := execAffected(ctx, db, query)
+	return err
+}
+
+func CreatePromoCode(ctx context.Context, db DBTX, p models.PromoCode) (int64, error) {
+	query := qb.Insert(PromoCodesTable).
+		Columns("event_id", "code", "kind", "value", "max_uses", "expires_at").
+		Values(p.EventID, p.Code, p.Kind, promoValue(p), p.MaxUses, p.ExpiresAt).
+		Suffix("returning id")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = db.QueryRow(ctx, stmt, args...).Scan(&id)
+	return id, err
+}
+
+// promoValue keeps percents as they are and fixed discounts in minor units.
+func promoValue(p models.PromoCode) int64 {
+	if p.Kind == pkg.PromoKindFixed {
+		return minorUnits(p.Value)
+	}
+	return int64(p.Value)
+}
+
+func DeletePromoCode(ctx context.Context, db DBTX, eventId, promoId int64) (bool, error) {
+	query := qb.Update(PromoCodesTable).
+		Set("deleted_at", time.Now()).
+		Where(sq.Eq{"id": promoId}).
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"deleted_at": nil})
+
+	return execAffected(ctx, db, query)
+}
+
+func promoCodesQuery() sq.SelectBuilder {
+	return qb.Select("id", "event_id", "code", "kind", "value", "max_uses", "used_count", "expires_at").
+		From(PromoCodesTable).
+		Where(sq.Eq{"deleted_at": nil})
+}
+
+func scanPromoCode(row pgx.Row) (models.PromoCode, error) {
+	var (
+		p     models.PromoCode
+		value int64
+	)
+	err := row.Scan(&p.ID, &p.EventID, &p.Code, &p.Kind, &value, &p.MaxUses, &p.UsedCount, &p.ExpiresAt)
+	p.Value = float64(value)
+	if p.Kind == pkg.PromoKindFixed {
+		p.Value /= 100
+	}
+	return p, err
+}
+
+func GetPromoCodes(ctx context.Context, db DBTX, eventId int64) ([]models.PromoCode, error) {
+	query := promoCodesQuery().
+		Where(sq.Eq{"event_id": eventId}).
+		OrderBy("id")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []models.PromoCode
+	for rows.Next() {
+		p, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, p)
+	}
+
+	return codes, rows.Err()
+}
+
+// GetPromoCode finds the code of the event ignoring case, nil when there is
+// no such code.
+func GetPromoCode(ctx context.Context, db DBTX, eventId int64, code string) (*models.PromoCode, error) {
+	query := promoCodesQuery().
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"lower(code)": strings.ToLower(code)})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := scanPromoCode(db.QueryRow(ctx, stmt, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// RedeemPromoCode uses the code once, false when it has no uses left.
+func RedeemPromoCode(ctx context.Context, db DBTX, promoId int64) (bool, error) {
+	query := qb.Update(PromoCodesTable).
+		Set("used_count", sq.Expr("used_count + 1")).
+		Where(sq.Eq{"id": promoId}).
+		Where(sq.Or{
+			sq.Eq{"max_uses": nil},
+			sq.Expr("used_count < max_uses"),
+		})
+
+	return execAffected(ctx, db, query)
+}
+
+func ReleasePromoCode(ctx context.Context, db DBTX, promoId int64) error {
+	query := qb.Update(PromoCodesTable).
+		Set("used_count", sq.Expr("greatest(used_count - 1, 0)")).
+		Where(sq.Eq{"id": promoId})
+
+	_, err := execAffected(ctx, db, query)
+	return err
+}
+
+func execAffected(ctx context.Context, db DBTX, query sq.UpdateBuilder) (bool, error) {
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := db.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
@@ -12,8 +12,9 @@ const TokensTable = "tokens"
 func CreateToken(ctx context.Context, db DBTX, token models.Token) error {
 
 	query := qb.Insert(TokensTable).
-		Columns("token", "phone", "user_id", "token_type", "expires_at", "user_agent").
-		Values(token.Token, token.Phone, token.UserId, token.Type, time.Now().Add(token.Duration), token.UserAgent)
+		Columns("token", "phone", "user_id", "token_type", "expires_at", "user_agent", "session_id", "device_name", "ip", "last_used_at", "session_started_at").
+		Values(token.Token, token.Phone, token.UserId, token.Type, time.Now().Add(token.Duration), token.UserAgent, token.SessionId, token.DeviceName, token.IP, time.Now(),
+			sq.Expr("coalesce(?::timestamptz, now())", token.SessionStartedAt))
 
 	stmt, params, err := query.ToSql()
 	if err != nil {
@@ -28,7 +29,7 @@ func CreateToken(ctx context.Context, db DBTX, token models.Token) error {
 
 func GetToken(ctx context.Context, db DBTX, token models.Token) (models.Token, error) {
 
-	query := qb.Select("token,phone,user_id, token_type, user_agent").From(TokensTable).
+	query := qb.Select("token,phone,user_id, token_type, user_agent, session_id, device_name, ip, session_started_at, rotated_at").From(TokensTable).
 		Where(sq.Gt{"expires_at": time.Now()}).
 		Where(sq.Eq{"token_type": token.Type})
 
@@ -50,7 +51,7 @@ func GetToken(ctx context.Context, db DBTX, token models.Token) (models.Token, e
 	}
 
 	var t models.Token
-	err = db.QueryRow(ctx, stmt, params...).Scan(&t.Token, &t.Phone, &t.UserId, &t.Type, &t.UserAgent)
+	err = db.QueryRow(ctx, stmt, params...).Scan(&t.Token, &t.Phone, &t.UserId, &t.Type, &t.UserAgent, &t.SessionId, &t.DeviceName, &t.IP, &t.SessionStartedAt, &t.RotatedAt)
 	if err != nil {
 		return models.Token{}, err
 	}
@@ -75,6 +76,14 @@ func DeleteToken(ctx context.Context, db DBTX, token models.Token) error {
 		query = query.Where(sq.Eq{"user_agent": token.UserAgent})
 	}
 
+	if token.SessionId != nil {
+		query = query.Where(sq.Eq{"session_id": token.SessionId})
+	}
+
+	if token.Token != "" {
+		query = query.Where(sq.Eq{"token": token.Token})
+	}
+
 	stmt, params, err := query.ToSql()
 
 	if err != nil {
@@ -84,3 +93,98 @@ func DeleteToken(ctx context.Context, db DBTX, token models.Token) error {
 	_, err = db.Exec(ctx, stmt, params...)
 	return err
 }
+
+// DeleteSession removes the refresh token of a session, only refresh tokens
+// belong to sessions.
+func DeleteSession(ctx context.Context, db DBTX, userId int64, sessionId string) (bool, error) {
+	query := qb.Delete(TokensTable).
+		Where(sq.Eq{"user_id": userId}).
+		Where(sq.Eq{"session_id": sessionId})
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := db.Exec(ctx, stmt, params...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// SessionExists reports whether the session of the user still has a live
+// refresh token.
+func SessionExists(ctx context.Context, db DBTX, userId int64, sessionId string) (bool, error) {
+	query := qb.Select("1").
+		From(TokensTable).
+		Where(sq.Eq{"user_id": userId}).
+		Where(sq.Eq{"session_id": sessionId}).
+		Where(sq.Gt{"expires_at": time.Now()}).
+		Prefix("select exists (").
+		Suffix(")")
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = db.QueryRow(ctx, stmt, params...).Scan(&exists)
+	return exists, err
+}
+
+// RotateToken marks the token as rotated, it reports false when the token
+// was already rotated.
+func RotateToken(ctx context.Context, db DBTX, token string) (bool, error) {
+	query := qb.Update(TokensTable).
+		Set("rotated_at", time.Now()).
+		Where(sq.Eq{"token": token}).
+		Where(sq.Eq{"rotated_at": nil})
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := db.Exec(ctx, stmt, params...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func GetSessions(ctx context.Context, db DBTX, userId int64) ([]models.Session, error) {
+	query := qb.Select("session_id", "device_name", "user_agent", "ip", "coalesce(session_started_at, created_at)", "coalesce(last_used_at, created_at)", "expires_at").
+		From(TokensTable).
+		Where(sq.Eq{"user_id": userId}).
+		Where(sq.Gt{"expires_at": time.Now()}).
+		Where(sq.NotEq{"session_id": nil}).
+		Where(sq.Eq{"rotated_at": nil}).
+		OrderBy("last_used_at desc nulls last")
+
+	stmt, params, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, stmt, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var s models.Session
+		err := rows.Scan(&s.ID, &s.DeviceName, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
@@ -6,6 +6,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/NuEventTeam/events/internal/models"
 	"github.com/jackc/pgx/v5"
+	"time"
 )
 
 func CreateUser(ctx context.Context, db DBTX, user models.User) error {
@@ -107,10 +108,16 @@ func GetUser(ctx context.Context, db DBTX, args GetUserArgss) (models.User, erro
 		return models.User{}, nil
 	}
 
-	var user models.User
+	var (
+		user      models.User
+		birthDate *time.Time
+	)
 
 	err = db.QueryRow(ctx, stmt, params...).Scan(&user.UserID, &user.Phone, &user.Username, &user.Lastname,
-		&user.Firstname, &user.BirthDate, &user.ProfileImage)
+		&user.Firstname, &birthDate, &user.ProfileImage)
+	if birthDate != nil {
+		user.BirthDate = *birthDate
+	}
 
 	return user, err
 
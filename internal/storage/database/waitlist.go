@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+const WaitlistTable = "event_waitlist"
+
+// AddToWaitlist puts the user at the end of the waitlist of the session,
+// users already waiting for the event keep their place.
+func AddToWaitlist(ctx context.Context, db DBTX, eventId, locationId, userId int64) error {
+	query := qb.Insert(WaitlistTable).
+		Columns("event_id", "location_id", "user_id").
+		Values(eventId, locationId, userId).
+		Suffix("on conflict (event_id, user_id) do nothing")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, stmt, args...)
+	return err
+}
+
+// GetWaitlistPosition returns the session the user waits for and their place
+// in its line starting at 1, false when the user is not waiting.
+func GetWaitlistPosition(ctx context.Context, db DBTX, eventId, userId int64) (int64, int64, bool, error) {
+	query := qb.Select("w.location_id", "(select count(*) from event_waitlist o where o.location_id = w.location_id and o.id <= w.id)").
+		From(WaitlistTable + " w").
+		Where(sq.Eq{"w.event_id": eventId}).
+		Where(sq.Eq{"w.user_id": userId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	var locationId, position int64
+	err = db.QueryRow(ctx, stmt, args...).Scan(&locationId, &position)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, false, nil
+		}
+		return 0, 0, false, err
+	}
+
+	return locationId, position, true, nil
+}
+
+func RemoveFromWaitlist(ctx context.Context, db DBTX, eventId, userId int64) (bool, error) {
+	query := qb.Delete(WaitlistTable).
+		Where(sq.Eq{"event_id": eventId}).
+		Where(sq.Eq{"user_id": userId})
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := db.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// PopWaitlist removes the user waiting longest for the session and returns
+// them, nil when nobody waits.
+func PopWaitlist(ctx context.Context, db DBTX, locationId int64) (*int64, error) {
+	query := qb.Delete(WaitlistTable).
+		Where("id = (select id from event_waitlist where location_id = ? order by id limit 1 for update skip locked)", locationId).
+		Suffix("returning user_id")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var userId int64
+	err = db.QueryRow(ctx, stmt, args...).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &userId, nil
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/NuEventTeam/events/internal/config"
 	"github.com/redis/go-redis/v9"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -62,13 +64,179 @@ func (c *Cache) Set(ctx context.Context, key string, value interface{}, expiry t
 	return nil
 }
 
-func (c *Cache) Del(ctx context.Context, key string) error {
-	key = fmt.Sprintf("%s:%s", c.prefix, key)
+func (c *Cache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
 
-	err := c.client.Del(ctx, key).Err()
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = fmt.Sprintf("%s:%s", c.prefix, key)
+	}
+
+	err := c.client.Del(ctx, prefixed...).Err()
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// Incr increments the counter at key and returns the new value. The expiry
+// is set when the counter is created, so it counts within a fixed window.
+// The script keeps it to KeyDB 6 commands, EXPIRE NX needs Redis 7.
+func (c *Cache) Incr(ctx context.Context, key string, expiry time.Duration) (int64, error) {
+	key = fmt.Sprintf("%s:%s", c.prefix, key)
+
+	return incrScript.Run(ctx, c.client, []string{key}, expiry.Milliseconds()).Int64()
+}
+
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// SetNX sets the key only when it does not exist and reports whether it did.
+func (c *Cache) SetNX(ctx context.Context, key string, value interface{}, expiry time.Duration) (bool, error) {
+	key = fmt.Sprintf("%s:%s", c.prefix, key)
+
+	return c.client.SetNX(ctx, key, value, expiry).Result()
+}
+
+// TTL returns the time left before the key expires, 0 when it does not exist
+// or has no expiry.
+func (c *Cache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	key = fmt.Sprintf("%s:%s", c.prefix, key)
+
+	ttl, err := c.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (c *Cache) SMembers(ctx context.Context, key string) ([]string, error) {
+	key = fmt.Sprintf("%s:%s", c.prefix, key)
+
+	return c.client.SMembers(ctx, key).Result()
+}
+
+// ZAdd scores the members of the sorted set at key and extends its expiry.
+func (c *Cache) ZAdd(ctx context.Context, key string, expiry time.Duration, score float64, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	key = fmt.Sprintf("%s:%s", c.prefix, key)
+
+	z := make([]redis.Z, len(members))
+	for i, member := range members {
+		z[i] = redis.Z{Score: score, Member: member}
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.ZAdd(ctx, key, z...)
+	pipe.Expire(ctx, key, expiry)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *Cache) ZRem(ctx context.Context, key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	key = fmt.Sprintf("%s:%s", c.prefix, key)
+
+	args := make([]interface{}, len(members))
+	for i, member := range members {
+		args[i] = member
+	}
+
+	return c.client.ZRem(ctx, key, args...).Err()
+}
+
+// ZRangeFrom returns the members of the sorted set at key scored min or more.
+func (c *Cache) ZRangeFrom(ctx context.Context, key string, min float64) ([]string, error) {
+	key = fmt.Sprintf("%s:%s", c.prefix, key)
+
+	return c.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatFloat(min, 'f', -1, 64),
+		Max: "+inf",
+	}).Result()
+}
+
+// ZRemBelow removes the members of the sorted set at key scored below max.
+func (c *Cache) ZRemBelow(ctx context.Context, key string, max float64) error {
+	key = fmt.Sprintf("%s:%s", c.prefix, key)
+
+	return c.client.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatFloat(max, 'f', -1, 64)).Err()
+}
+
+func (c *Cache) Publish(ctx context.Context, channel string, message interface{}) error {
+	channel = fmt.Sprintf("%s:%s", c.prefix, channel)
+
+	return c.client.Publish(ctx, channel, message).Err()
+}
+
+// Message is a message published to a channel, named without the prefix.
+type Message struct {
+	Channel string
+	Payload string
+}
+
+// Subscription receives the messages published to the channels it is
+// subscribed to. It starts with the given channels, which may be none.
+type Subscription struct {
+	pubsub   *redis.PubSub
+	prefix   string
+	messages chan Message
+}
+
+func (c *Cache) Subscribe(ctx context.Context, channels ...string) *Subscription {
+	s := &Subscription{
+		pubsub:   c.client.Subscribe(ctx, prefixed(c.prefix, channels)...),
+		prefix:   c.prefix,
+		messages: make(chan Message, 100),
+	}
+
+	go func() {
+		defer close(s.messages)
+		for m := range s.pubsub.Channel() {
+			s.messages <- Message{Channel: strings.TrimPrefix(m.Channel, s.prefix+":"), Payload: m.Payload}
+		}
+	}()
+
+	return s
+}
+
+func (s *Subscription) Subscribe(ctx context.Context, channels ...string) error {
+	return s.pubsub.Subscribe(ctx, prefixed(s.prefix, channels)...)
+}
+
+func (s *Subscription) Unsubscribe(ctx context.Context, channels ...string) error {
+	return s.pubsub.Unsubscribe(ctx, prefixed(s.prefix, channels)...)
+}
+
+// Messages is closed once the subscription is closed.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+func (s *Subscription) Close() error {
+	return s.pubsub.Close()
+}
+
+func prefixed(prefix string, keys []string) []string {
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		out[i] = fmt.Sprintf("%s:%s", prefix, key)
+	}
+	return out
+}
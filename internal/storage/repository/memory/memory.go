@@ -0,0 +1,1086 @@
+package memory
+
+import (
+	"context"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"github.com/NuEventTeam/events/pkg"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store is an in-memory repository.Store for handler tests. Transactions are
+// serialized and work on a copy of the data that replaces the original on commit.
+type Store struct {
+	mu   *sync.Mutex
+	inTx bool
+	data *data
+}
+
+type tokenRow struct {
+	token      models.Token
+	createdAt  time.Time
+	lastUsedAt time.Time
+	expiresAt  time.Time
+}
+
+type followerRow struct {
+	locationId int64
+	createdAt  time.Time
+}
+
+type waitRow struct {
+	id         int64
+	eventId    int64
+	locationId int64
+	userId     int64
+}
+
+type otpRow struct {
+	otp       models.Otp
+	expiresAt time.Time
+}
+
+type data struct {
+	lastId      int64
+	users       map[int64]models.User
+	events      map[int64]models.Event
+	followers   map[int64]map[int64]followerRow
+	waitlist    []waitRow
+	orders      []models.Order
+	ticketTypes []models.TicketType
+	promoCodes  []models.PromoCode
+	likes       map[int64]map[int64]bool
+	comments    []models.Comment
+	chatMembers map[int64]map[int64]int64
+	messages    []models.ChatMessage
+	tokens      []tokenRow
+	otps        []otpRow
+}
+
+func New() *Store {
+	return &Store{
+		mu: &sync.Mutex{},
+		data: &data{
+			users:       map[int64]models.User{},
+			events:      map[int64]models.Event{},
+			followers:   map[int64]map[int64]followerRow{},
+			likes:       map[int64]map[int64]bool{},
+			chatMembers: map[int64]map[int64]int64{},
+		},
+	}
+}
+
+func (d *data) nextId() int64 {
+	d.lastId++
+	return d.lastId
+}
+
+func (d *data) clone() *data {
+	c := &data{
+		lastId:      d.lastId,
+		users:       make(map[int64]models.User, len(d.users)),
+		events:      make(map[int64]models.Event, len(d.events)),
+		followers:   make(map[int64]map[int64]followerRow, len(d.followers)),
+		waitlist:    append([]waitRow(nil), d.waitlist...),
+		orders:      append([]models.Order(nil), d.orders...),
+		ticketTypes: append([]models.TicketType(nil), d.ticketTypes...),
+		promoCodes:  append([]models.PromoCode(nil), d.promoCodes...),
+		likes:       make(map[int64]map[int64]bool, len(d.likes)),
+		comments:    append([]models.Comment(nil), d.comments...),
+		chatMembers: make(map[int64]map[int64]int64, len(d.chatMembers)),
+		messages:    append([]models.ChatMessage(nil), d.messages...),
+		tokens:      append([]tokenRow(nil), d.tokens...),
+		otps:        append([]otpRow(nil), d.otps...),
+	}
+	for k, v := range d.users {
+		c.users[k] = v
+	}
+	for k, v := range d.events {
+		c.events[k] = v
+	}
+	for k, v := range d.followers {
+		c.followers[k] = make(map[int64]followerRow, len(v))
+		for u, f := range v {
+			c.followers[k][u] = f
+		}
+	}
+	for k, v := range d.likes {
+		c.likes[k] = make(map[int64]bool, len(v))
+		for u, l := range v {
+			c.likes[k][u] = l
+		}
+	}
+	for k, v := range d.chatMembers {
+		c.chatMembers[k] = make(map[int64]int64, len(v))
+		for u, r := range v {
+			c.chatMembers[k][u] = r
+		}
+	}
+	return c
+}
+
+func (s *Store) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *Store) Events() repository.Events       { return events{s} }
+func (s *Store) Users() repository.Users         { return users{s} }
+func (s *Store) Followers() repository.Followers { return followers{s} }
+func (s *Store) Waitlist() repository.Waitlist   { return waitlist{s} }
+func (s *Store) Orders() repository.Orders       { return orders{s} }
+func (s *Store) Tickets() repository.Tickets     { return tickets{s} }
+func (s *Store) Comments() repository.Comments   { return comments{s} }
+func (s *Store) Chat() repository.Chat           { return chat{s} }
+func (s *Store) Tokens() repository.Tokens       { return tokens{s} }
+func (s *Store) Otps() repository.Otps           { return otps{s} }
+
+func (s *Store) WithTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &Store{mu: s.mu, inTx: true, data: s.data.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	*s.data = *tx.data
+	return nil
+}
+
+// AddUser seeds a user and returns its id.
+func (s *Store) AddUser(user models.User) int64 {
+	defer s.lock()()
+
+	if user.ID == 0 {
+		user.ID = s.data.nextId()
+	}
+	user.UserID = user.ID
+	s.data.users[user.ID] = user
+	return user.ID
+}
+
+// AddEvent seeds an event together with its locations and managers and returns its id.
+func (s *Store) AddEvent(event models.Event) int64 {
+	defer s.lock()()
+
+	if event.ID == 0 {
+		event.ID = s.data.nextId()
+	}
+	if event.Status == nil {
+		status := pkg.EventStatusCreated
+		event.Status = &status
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	locations := make([]models.Location, len(event.Locations))
+	for i, l := range event.Locations {
+		if l.ID == 0 {
+			l.ID = s.data.nextId()
+		}
+		if l.AttendeesCount == nil {
+			l.AttendeesCount = new(int64)
+		}
+		l.EventID = event.ID
+		locations[i] = l
+	}
+	event.Locations = locations
+
+	managers := make([]models.Manager, len(event.Managers))
+	for i, m := range event.Managers {
+		if m.Role.ID == 0 {
+			m.Role.ID = s.data.nextId()
+		}
+		m.EventId = event.ID
+		m.Role.EventID = event.ID
+		managers[i] = m
+	}
+	event.Managers = managers
+
+	for _, t := range event.TicketTypes {
+		if t.ID == 0 {
+			t.ID = s.data.nextId()
+		}
+		t.EventID = event.ID
+		s.data.ticketTypes = append(s.data.ticketTypes, t)
+	}
+	event.TicketTypes = nil
+
+	s.data.events[event.ID] = event
+	return event.ID
+}
+
+// AddPromoCode seeds a promo code and returns its id.
+func (s *Store) AddPromoCode(promo models.PromoCode) int64 {
+	defer s.lock()()
+
+	if promo.ID == 0 {
+		promo.ID = s.data.nextId()
+	}
+	s.data.promoCodes = append(s.data.promoCodes, promo)
+	return promo.ID
+}
+
+type events struct {
+	s *Store
+}
+
+func (e events) GetByID(ctx context.Context, eventId int64) (*models.Event, error) {
+	defer e.s.lock()()
+
+	event, ok := e.s.data.events[eventId]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &event, nil
+}
+
+func (e events) GetLocations(ctx context.Context, eventId int64) ([]models.Location, error) {
+	defer e.s.lock()()
+
+	event, ok := e.s.data.events[eventId]
+	if !ok {
+		return nil, nil
+	}
+	return append([]models.Location(nil), event.Locations...), nil
+}
+
+func (e events) CheckPermission(ctx context.Context, eventId, userId int64, permissionIds ...int64) (bool, error) {
+	defer e.s.lock()()
+
+	if len(permissionIds) == 0 {
+		return false, nil
+	}
+
+	granted := map[int64]bool{}
+	for _, m := range e.s.data.events[eventId].Managers {
+		if m.User.UserID != userId {
+			continue
+		}
+		for _, p := range m.Role.Permissions {
+			granted[p] = true
+		}
+	}
+
+	for _, p := range permissionIds {
+		if !granted[p] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (e events) ChangeFollowerCount(ctx context.Context, eventId, by int64) error {
+	defer e.s.lock()()
+
+	event, ok := e.s.data.events[eventId]
+	if !ok {
+		return nil
+	}
+	event.FollowerCount += by
+	e.s.data.events[eventId] = event
+	return nil
+}
+
+func (e events) ChangeAttendeesCount(ctx context.Context, locationId, by int64) error {
+	defer e.s.lock()()
+
+	for id, event := range e.s.data.events {
+		for i, l := range event.Locations {
+			if l.ID != locationId {
+				continue
+			}
+			count := by
+			if l.AttendeesCount != nil {
+				count += *l.AttendeesCount
+			}
+			locations := append([]models.Location(nil), event.Locations...)
+			locations[i].AttendeesCount = &count
+			event.Locations = locations
+			e.s.data.events[id] = event
+			return nil
+		}
+	}
+	return nil
+}
+
+func (e events) ReserveSeat(ctx context.Context, locationId int64) (bool, error) {
+	defer e.s.lock()()
+
+	for id, event := range e.s.data.events {
+		for i, l := range event.Locations {
+			if l.ID != locationId {
+				continue
+			}
+			var count int64
+			if l.AttendeesCount != nil {
+				count = *l.AttendeesCount
+			}
+			if l.Seats != nil && *l.Seats > 0 && count >= *l.Seats {
+				return false, nil
+			}
+			count++
+			locations := append([]models.Location(nil), event.Locations...)
+			locations[i].AttendeesCount = &count
+			event.Locations = locations
+			e.s.data.events[id] = event
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (e events) IsLiked(ctx context.Context, eventId, userId int64) (bool, error) {
+	defer e.s.lock()()
+
+	return e.s.data.likes[eventId][userId], nil
+}
+
+func (e events) AddLike(ctx context.Context, eventId, userId int64) (bool, error) {
+	defer e.s.lock()()
+
+	if e.s.data.likes[eventId][userId] {
+		return false, nil
+	}
+	if e.s.data.likes[eventId] == nil {
+		e.s.data.likes[eventId] = map[int64]bool{}
+	}
+	e.s.data.likes[eventId][userId] = true
+	return true, nil
+}
+
+func (e events) RemoveLike(ctx context.Context, eventId, userId int64) (bool, error) {
+	defer e.s.lock()()
+
+	if !e.s.data.likes[eventId][userId] {
+		return false, nil
+	}
+	delete(e.s.data.likes[eventId], userId)
+	return true, nil
+}
+
+// ChangeLikeCount is a no-op: models.Event does not carry the like counter.
+func (e events) ChangeLikeCount(ctx context.Context, eventId, by int64) error {
+	return nil
+}
+
+type users struct {
+	s *Store
+}
+
+func (u users) GetProfile(ctx context.Context, userId int64) (models.User, error) {
+	defer u.s.lock()()
+
+	user, ok := u.s.data.users[userId]
+	if !ok {
+		return models.User{}, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+func (u users) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	defer u.s.lock()()
+
+	for _, user := range u.s.data.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return models.User{}, repository.ErrNotFound
+}
+
+func (u users) GetCredentials(ctx context.Context, phone *string, userId *int64) (*models.User, error) {
+	defer u.s.lock()()
+
+	for _, user := range u.s.data.users {
+		if phone != nil && user.Phone != *phone {
+			continue
+		}
+		if userId != nil && user.ID != *userId {
+			continue
+		}
+		return &models.User{ID: user.ID, Hash: user.Hash}, nil
+	}
+	return nil, nil
+}
+
+func (u users) PhoneExists(ctx context.Context, phone string) (bool, error) {
+	defer u.s.lock()()
+
+	for _, user := range u.s.data.users {
+		if user.Phone == phone {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (u users) Create(ctx context.Context, user models.User) (int64, error) {
+	defer u.s.lock()()
+
+	id := u.s.data.nextId()
+	u.s.data.users[id] = models.User{ID: id, UserID: id, Phone: user.Phone, Hash: user.Hash}
+	return id, nil
+}
+
+func (u users) UpdateCredentials(ctx context.Context, userId int64, phone, hash *string) error {
+	defer u.s.lock()()
+
+	user, ok := u.s.data.users[userId]
+	if !ok {
+		return nil
+	}
+	if phone != nil {
+		user.Phone = *phone
+	}
+	if hash != nil {
+		user.Hash = *hash
+	}
+	u.s.data.users[userId] = user
+	return nil
+}
+
+// DeviceTokens returns nil: devices are not kept in memory.
+func (u users) DeviceTokens(ctx context.Context, userIds ...int64) ([]string, error) {
+	return nil, nil
+}
+
+type followers struct {
+	s *Store
+}
+
+func (f followers) Add(ctx context.Context, eventId, locationId, userId int64) error {
+	defer f.s.lock()()
+
+	if _, ok := f.s.data.followers[eventId][userId]; ok {
+		return repository.ErrAlreadyExists
+	}
+	if f.s.data.followers[eventId] == nil {
+		f.s.data.followers[eventId] = map[int64]followerRow{}
+	}
+	f.s.data.followers[eventId][userId] = followerRow{locationId: locationId, createdAt: time.Now()}
+	return nil
+}
+
+func (f followers) Remove(ctx context.Context, eventId, userId int64) (int64, error) {
+	defer f.s.lock()()
+
+	row, ok := f.s.data.followers[eventId][userId]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	delete(f.s.data.followers[eventId], userId)
+	return row.locationId, nil
+}
+
+func (f followers) Exists(ctx context.Context, eventId, userId int64) (bool, error) {
+	defer f.s.lock()()
+
+	_, ok := f.s.data.followers[eventId][userId]
+	return ok, nil
+}
+
+func (f followers) List(ctx context.Context, eventId int64, username string, lastId int64) ([]models.Follower, error) {
+	defer f.s.lock()()
+
+	var list []models.Follower
+	for userId := range f.s.data.followers[eventId] {
+		user := f.s.data.users[userId]
+		if userId <= lastId || !strings.Contains(user.Username, username) {
+			continue
+		}
+		list = append(list, models.Follower{
+			UserId:       userId,
+			Username:     user.Username,
+			ProfileImage: user.ProfileImage,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].UserId < list[j].UserId })
+	if len(list) > 20 {
+		list = list[:20]
+	}
+	return list, nil
+}
+
+// Each calls fn outside the lock, fn may use the store.
+func (f followers) Each(ctx context.Context, eventId int64, fn func(models.Attendee) error) error {
+	unlock := f.s.lock()
+	var list []models.Attendee
+	for userId, row := range f.s.data.followers[eventId] {
+		user := f.s.data.users[userId]
+		list = append(list, models.Attendee{
+			UserID:       userId,
+			Username:     user.Username,
+			Firstname:    user.Firstname,
+			Lastname:     user.Lastname,
+			Phone:        user.Phone,
+			RegisteredAt: row.createdAt,
+		})
+	}
+	unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].RegisteredAt.Equal(list[j].RegisteredAt) {
+			return list[i].RegisteredAt.Before(list[j].RegisteredAt)
+		}
+		return list[i].UserID < list[j].UserID
+	})
+	for _, a := range list {
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type waitlist struct {
+	s *Store
+}
+
+func (w waitlist) Join(ctx context.Context, eventId, locationId, userId int64) error {
+	defer w.s.lock()()
+
+	for _, r := range w.s.data.waitlist {
+		if r.eventId == eventId && r.userId == userId {
+			return nil
+		}
+	}
+	w.s.data.waitlist = append(w.s.data.waitlist, waitRow{
+		id:         w.s.data.nextId(),
+		eventId:    eventId,
+		locationId: locationId,
+		userId:     userId,
+	})
+	return nil
+}
+
+func (w waitlist) Position(ctx context.Context, eventId, userId int64) (int64, int64, error) {
+	defer w.s.lock()()
+
+	positions := map[int64]int64{}
+	for _, r := range w.s.data.waitlist {
+		positions[r.locationId]++
+		if r.eventId == eventId && r.userId == userId {
+			return r.locationId, positions[r.locationId], nil
+		}
+	}
+	return 0, 0, repository.ErrNotFound
+}
+
+func (w waitlist) Leave(ctx context.Context, eventId, userId int64) error {
+	defer w.s.lock()()
+
+	for i, r := range w.s.data.waitlist {
+		if r.eventId == eventId && r.userId == userId {
+			w.s.data.waitlist = append(w.s.data.waitlist[:i:i], w.s.data.waitlist[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (w waitlist) Next(ctx context.Context, locationId int64) (int64, error) {
+	defer w.s.lock()()
+
+	for i, r := range w.s.data.waitlist {
+		if r.locationId == locationId {
+			w.s.data.waitlist = append(w.s.data.waitlist[:i:i], w.s.data.waitlist[i+1:]...)
+			return r.userId, nil
+		}
+	}
+	return 0, repository.ErrNotFound
+}
+
+type orders struct {
+	s *Store
+}
+
+func (o orders) Create(ctx context.Context, order models.Order) (int64, error) {
+	defer o.s.lock()()
+
+	for _, r := range o.s.data.orders {
+		if r.EventID == order.EventID && r.UserID == order.UserID &&
+			(r.Status == pkg.OrderStatusPending || r.Status == pkg.OrderStatusPaid) {
+			return 0, repository.ErrAlreadyExists
+		}
+	}
+	order.ID = o.s.data.nextId()
+	order.CreatedAt = time.Now()
+	o.s.data.orders = append(o.s.data.orders, order)
+	return order.ID, nil
+}
+
+func (o orders) Get(ctx context.Context, orderId int64) (models.Order, error) {
+	defer o.s.lock()()
+
+	for _, r := range o.s.data.orders {
+		if r.ID == orderId {
+			return r, nil
+		}
+	}
+	return models.Order{}, repository.ErrNotFound
+}
+
+func (o orders) GetByPayment(ctx context.Context, paymentId string) (models.Order, error) {
+	defer o.s.lock()()
+
+	for _, r := range o.s.data.orders {
+		if r.PaymentID != nil && *r.PaymentID == paymentId {
+			return r, nil
+		}
+	}
+	return models.Order{}, repository.ErrNotFound
+}
+
+func (o orders) GetByUser(ctx context.Context, eventId, userId int64, statuses ...int) (models.Order, error) {
+	defer o.s.lock()()
+
+	for i := len(o.s.data.orders) - 1; i >= 0; i-- {
+		r := o.s.data.orders[i]
+		if r.EventID == eventId && r.UserID == userId && slices.Contains(statuses, r.Status) {
+			return r, nil
+		}
+	}
+	return models.Order{}, repository.ErrNotFound
+}
+
+func (o orders) SetPayment(ctx context.Context, orderId int64, provider, paymentId string) error {
+	defer o.s.lock()()
+
+	for i, r := range o.s.data.orders {
+		if r.ID == orderId {
+			o.s.data.orders[i].Provider = &provider
+			o.s.data.orders[i].PaymentID = &paymentId
+		}
+	}
+	return nil
+}
+
+func (o orders) SetStatus(ctx context.Context, orderId int64, status int, from ...int) (bool, error) {
+	defer o.s.lock()()
+
+	for i, r := range o.s.data.orders {
+		if r.ID != orderId || !slices.Contains(from, r.Status) {
+			continue
+		}
+		o.s.data.orders[i].Status = status
+		if status == pkg.OrderStatusPaid {
+			now := time.Now()
+			o.s.data.orders[i].PaidAt = &now
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (o orders) ListExpired(ctx context.Context, t time.Time) ([]models.Order, error) {
+	defer o.s.lock()()
+
+	var res []models.Order
+	for _, r := range o.s.data.orders {
+		if r.Status == pkg.OrderStatusPending && r.ExpiresAt.Before(t) {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+func (o orders) ListByEvent(ctx context.Context, eventId int64, statuses ...int) ([]models.Order, error) {
+	defer o.s.lock()()
+
+	var res []models.Order
+	for _, r := range o.s.data.orders {
+		if r.EventID == eventId && slices.Contains(statuses, r.Status) {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+type tickets struct {
+	s *Store
+}
+
+func (t tickets) ListTypes(ctx context.Context, eventId int64) ([]models.TicketType, error) {
+	defer t.s.lock()()
+
+	var res []models.TicketType
+	for _, r := range t.s.data.ticketTypes {
+		if r.EventID == eventId {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+func (t tickets) ReserveType(ctx context.Context, typeId int64) (bool, error) {
+	defer t.s.lock()()
+
+	for i, r := range t.s.data.ticketTypes {
+		if r.ID != typeId {
+			continue
+		}
+		if r.Quota != nil && r.SoldCount >= *r.Quota {
+			return false, nil
+		}
+		t.s.data.ticketTypes[i].SoldCount++
+		return true, nil
+	}
+	return false, nil
+}
+
+func (t tickets) ReleaseType(ctx context.Context, typeId int64) error {
+	defer t.s.lock()()
+
+	for i, r := range t.s.data.ticketTypes {
+		if r.ID == typeId && r.SoldCount > 0 {
+			t.s.data.ticketTypes[i].SoldCount--
+		}
+	}
+	return nil
+}
+
+func (t tickets) GetPromo(ctx context.Context, eventId int64, code string) (models.PromoCode, error) {
+	defer t.s.lock()()
+
+	for _, r := range t.s.data.promoCodes {
+		if r.EventID == eventId && strings.EqualFold(r.Code, code) {
+			return r, nil
+		}
+	}
+	return models.PromoCode{}, repository.ErrNotFound
+}
+
+func (t tickets) RedeemPromo(ctx context.Context, promoId int64) (bool, error) {
+	defer t.s.lock()()
+
+	for i, r := range t.s.data.promoCodes {
+		if r.ID != promoId {
+			continue
+		}
+		if r.MaxUses != nil && r.UsedCount >= *r.MaxUses {
+			return false, nil
+		}
+		t.s.data.promoCodes[i].UsedCount++
+		return true, nil
+	}
+	return false, nil
+}
+
+func (t tickets) ReleasePromo(ctx context.Context, promoId int64) error {
+	defer t.s.lock()()
+
+	for i, r := range t.s.data.promoCodes {
+		if r.ID == promoId && r.UsedCount > 0 {
+			t.s.data.promoCodes[i].UsedCount--
+		}
+	}
+	return nil
+}
+
+type comments struct {
+	s *Store
+}
+
+func (c comments) Add(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	defer c.s.lock()()
+
+	comment.CommentId = c.s.data.nextId()
+	comment.CreatedAt = time.Now()
+	if user, ok := c.s.data.users[comment.Author.ID]; ok {
+		comment.Author.Username = user.Username
+		comment.Author.ProfileImage = user.ProfileImage
+	}
+	c.s.data.comments = append(c.s.data.comments, comment)
+	return comment, nil
+}
+
+func (c comments) GetAuthor(ctx context.Context, userId int64) (models.CommentAuthor, error) {
+	defer c.s.lock()()
+
+	user, ok := c.s.data.users[userId]
+	if !ok {
+		return models.CommentAuthor{}, repository.ErrNotFound
+	}
+	return models.CommentAuthor{ID: user.ID, Username: user.Username, ProfileImage: user.ProfileImage}, nil
+}
+
+func (c comments) GetEventAuthor(ctx context.Context, eventId int64) (string, error) {
+	defer c.s.lock()()
+
+	for _, m := range c.s.data.events[eventId].Managers {
+		for _, p := range m.Role.Permissions {
+			if p == pkg.PermissionUpdate {
+				return c.s.data.users[m.User.UserID].Username, nil
+			}
+		}
+	}
+	return "", repository.ErrNotFound
+}
+
+func (c comments) ListParents(ctx context.Context, eventId, lastParentId int64) ([]models.Comment, error) {
+	defer c.s.lock()()
+
+	var list []models.Comment
+	for i := len(c.s.data.comments) - 1; i >= 0; i-- {
+		comment := c.s.data.comments[i]
+		if comment.EventId != eventId || comment.ParentId != nil {
+			continue
+		}
+		if lastParentId != 0 && comment.CommentId >= lastParentId {
+			continue
+		}
+		list = append(list, comment)
+	}
+	return list, nil
+}
+
+func (c comments) ListChildren(ctx context.Context, parentIds ...int64) ([]models.Comment, error) {
+	defer c.s.lock()()
+
+	parents := map[int64]bool{}
+	for _, id := range parentIds {
+		parents[id] = true
+	}
+
+	var list []models.Comment
+	for i := len(c.s.data.comments) - 1; i >= 0; i-- {
+		comment := c.s.data.comments[i]
+		if comment.ParentId != nil && parents[*comment.ParentId] {
+			list = append(list, comment)
+		}
+	}
+	return list, nil
+}
+
+type chat struct {
+	s *Store
+}
+
+func (c chat) AddMember(ctx context.Context, eventId, userId, roleId int64) error {
+	defer c.s.lock()()
+
+	if c.s.data.chatMembers[eventId] == nil {
+		c.s.data.chatMembers[eventId] = map[int64]int64{}
+	}
+	if _, ok := c.s.data.chatMembers[eventId][userId]; !ok {
+		c.s.data.chatMembers[eventId][userId] = roleId
+	}
+	return nil
+}
+
+func (c chat) SaveMessage(ctx context.Context, eventId, userId int64, message string) (models.ChatMessage, error) {
+	defer c.s.lock()()
+
+	user, ok := c.s.data.users[userId]
+	if !ok {
+		return models.ChatMessage{}, repository.ErrNotFound
+	}
+
+	msg := models.ChatMessage{
+		ID:           c.s.data.nextId(),
+		EventId:      eventId,
+		UserId:       userId,
+		Username:     user.Username,
+		ProfileImage: user.ProfileImage,
+		Message:      message,
+		CreatedAt:    time.Now(),
+	}
+	c.s.data.messages = append(c.s.data.messages, msg)
+	return msg, nil
+}
+
+func (c chat) ListMessages(ctx context.Context, eventId, lastId int64) ([]models.ChatMessage, error) {
+	defer c.s.lock()()
+
+	var list []models.ChatMessage
+	for i := len(c.s.data.messages) - 1; i >= 0; i-- {
+		msg := c.s.data.messages[i]
+		if msg.EventId == eventId && msg.ID > lastId {
+			list = append(list, msg)
+		}
+	}
+	return list, nil
+}
+
+type tokens struct {
+	s *Store
+}
+
+func tokenMatches(row models.Token, filter models.Token) bool {
+	if row.Type != filter.Type {
+		return false
+	}
+	if filter.Token != "" && row.Token != filter.Token {
+		return false
+	}
+	if filter.UserId != nil && (row.UserId == nil || *row.UserId != *filter.UserId) {
+		return false
+	}
+	if filter.Phone != nil && (row.Phone == nil || *row.Phone != *filter.Phone) {
+		return false
+	}
+	return true
+}
+
+func (t tokens) Create(ctx context.Context, token models.Token) error {
+	defer t.s.lock()()
+
+	now := time.Now()
+	if token.SessionStartedAt == nil {
+		token.SessionStartedAt = &now
+	}
+	t.s.data.tokens = append(t.s.data.tokens, tokenRow{token: token, createdAt: now, lastUsedAt: now, expiresAt: now.Add(token.Duration)})
+	return nil
+}
+
+func (t tokens) Get(ctx context.Context, token models.Token) (models.Token, error) {
+	defer t.s.lock()()
+
+	now := time.Now()
+	for _, row := range t.s.data.tokens {
+		if row.expiresAt.After(now) && tokenMatches(row.token, token) {
+			return row.token, nil
+		}
+	}
+	return models.Token{}, repository.ErrNotFound
+}
+
+func (t tokens) Delete(ctx context.Context, token models.Token) error {
+	defer t.s.lock()()
+
+	kept := t.s.data.tokens[:0]
+	for _, row := range t.s.data.tokens {
+		match := row.token.Type == token.Type &&
+			(token.Phone == nil || row.token.Phone != nil && *row.token.Phone == *token.Phone) &&
+			(token.UserId == nil || row.token.UserId != nil && *row.token.UserId == *token.UserId) &&
+			(token.UserAgent == nil || row.token.UserAgent != nil && *row.token.UserAgent == *token.UserAgent) &&
+			(token.SessionId == nil || row.token.SessionId != nil && *row.token.SessionId == *token.SessionId) &&
+			(token.Token == "" || row.token.Token == token.Token)
+		if !match {
+			kept = append(kept, row)
+		}
+	}
+	t.s.data.tokens = kept
+	return nil
+}
+
+func (t tokens) Rotate(ctx context.Context, token string) error {
+	defer t.s.lock()()
+
+	for i, row := range t.s.data.tokens {
+		if row.token.Token == token && row.token.RotatedAt == nil {
+			now := time.Now()
+			t.s.data.tokens[i].token.RotatedAt = &now
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (t tokens) ListSessions(ctx context.Context, userId int64) ([]models.Session, error) {
+	defer t.s.lock()()
+
+	now := time.Now()
+	var sessions []models.Session
+	for _, row := range t.s.data.tokens {
+		if row.token.SessionId == nil || row.token.RotatedAt != nil || row.token.UserId == nil || *row.token.UserId != userId || !row.expiresAt.After(now) {
+			continue
+		}
+		sessions = append(sessions, models.Session{
+			ID:         *row.token.SessionId,
+			DeviceName: row.token.DeviceName,
+			UserAgent:  row.token.UserAgent,
+			IP:         row.token.IP,
+			CreatedAt:  *row.token.SessionStartedAt,
+			LastUsedAt: row.lastUsedAt,
+			ExpiresAt:  row.expiresAt,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+func (t tokens) DeleteSession(ctx context.Context, userId int64, sessionId string) error {
+	defer t.s.lock()()
+
+	kept := t.s.data.tokens[:0]
+	found := false
+	for _, row := range t.s.data.tokens {
+		if row.token.SessionId != nil && *row.token.SessionId == sessionId && row.token.UserId != nil && *row.token.UserId == userId {
+			found = true
+			continue
+		}
+		kept = append(kept, row)
+	}
+	t.s.data.tokens = kept
+
+	if !found {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (t tokens) SessionExists(ctx context.Context, userId int64, sessionId string) (bool, error) {
+	defer t.s.lock()()
+
+	now := time.Now()
+	for _, row := range t.s.data.tokens {
+		if row.token.SessionId != nil && *row.token.SessionId == sessionId && row.token.UserId != nil && *row.token.UserId == userId && row.expiresAt.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type otps struct {
+	s *Store
+}
+
+func (o otps) Create(ctx context.Context, otp models.Otp) error {
+	defer o.s.lock()()
+
+	o.s.data.otps = append(o.s.data.otps, otpRow{otp: otp, expiresAt: time.Now().Add(otp.Duration)})
+	return nil
+}
+
+func (o otps) Get(ctx context.Context, otp models.Otp) (string, error) {
+	defer o.s.lock()()
+
+	now := time.Now()
+	for _, row := range o.s.data.otps {
+		if row.otp.Phone == otp.Phone && row.otp.OtpType == otp.OtpType && row.expiresAt.After(now) {
+			return row.otp.Code, nil
+		}
+	}
+	return "", nil
+}
+
+func (o otps) Delete(ctx context.Context, otp models.Otp) error {
+	defer o.s.lock()()
+
+	kept := o.s.data.otps[:0]
+	for _, row := range o.s.data.otps {
+		if row.otp.Phone != otp.Phone || row.otp.OtpType != otp.OtpType {
+			kept = append(kept, row)
+		}
+	}
+	o.s.data.otps = kept
+	return nil
+}
@@ -0,0 +1,420 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/NuEventTeam/events/internal/models"
+	"github.com/NuEventTeam/events/internal/storage/database"
+	"github.com/NuEventTeam/events/internal/storage/repository"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type Store struct {
+	pool *database.Database
+	db   database.DBTX
+}
+
+func New(db *database.Database) *Store {
+	return &Store{
+		pool: db,
+		db:   db.GetDb(),
+	}
+}
+
+func (s *Store) Events() repository.Events       { return events{s.db} }
+func (s *Store) Users() repository.Users         { return users{s.pool, s.db} }
+func (s *Store) Followers() repository.Followers { return followers{s.db} }
+func (s *Store) Waitlist() repository.Waitlist   { return waitlist{s.db} }
+func (s *Store) Orders() repository.Orders       { return orders{s.db} }
+func (s *Store) Tickets() repository.Tickets     { return tickets{s.db} }
+func (s *Store) Comments() repository.Comments   { return comments{s.db} }
+func (s *Store) Chat() repository.Chat           { return chat{s.db} }
+func (s *Store) Tokens() repository.Tokens       { return tokens{s.db} }
+func (s *Store) Otps() repository.Otps           { return otps{s.db} }
+
+func (s *Store) WithTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	if _, ok := s.db.(pgx.Tx); ok {
+		return fn(s)
+	}
+
+	tx, err := s.pool.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(&Store{pool: s.pool, db: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+type events struct {
+	db database.DBTX
+}
+
+func (e events) GetByID(ctx context.Context, eventId int64) (*models.Event, error) {
+	event, err := database.GetEventByID(ctx, e.db, eventId)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, repository.ErrNotFound
+	}
+	return event, nil
+}
+
+func (e events) GetLocations(ctx context.Context, eventId int64) ([]models.Location, error) {
+	return database.GetEventLocations(ctx, e.db, eventId)
+}
+
+func (e events) CheckPermission(ctx context.Context, eventId, userId int64, permissionIds ...int64) (bool, error) {
+	return database.CheckPermission(ctx, e.db, eventId, userId, permissionIds...)
+}
+
+func (e events) ChangeFollowerCount(ctx context.Context, eventId, by int64) error {
+	return database.ChangeEventFollowerCount(ctx, e.db, eventId, by)
+}
+
+func (e events) ChangeAttendeesCount(ctx context.Context, locationId, by int64) error {
+	return database.ChangeLocationAttendeesCount(ctx, e.db, locationId, by)
+}
+
+func (e events) ReserveSeat(ctx context.Context, locationId int64) (bool, error) {
+	return database.ReserveLocationSeat(ctx, e.db, locationId)
+}
+
+func (e events) IsLiked(ctx context.Context, eventId, userId int64) (bool, error) {
+	return database.CheckEventLike(ctx, e.db, eventId, userId)
+}
+
+func (e events) AddLike(ctx context.Context, eventId, userId int64) (bool, error) {
+	return database.AddEventLike(ctx, e.db, eventId, userId)
+}
+
+func (e events) RemoveLike(ctx context.Context, eventId, userId int64) (bool, error) {
+	return database.RemoveEventLike(ctx, e.db, eventId, userId)
+}
+
+func (e events) ChangeLikeCount(ctx context.Context, eventId, by int64) error {
+	return database.ChangeEventLikeCount(ctx, e.db, eventId, by)
+}
+
+type users struct {
+	pool *database.Database
+	db   database.DBTX
+}
+
+func (u users) GetProfile(ctx context.Context, userId int64) (models.User, error) {
+	user, err := database.GetUser(ctx, u.db, database.GetUserArgss{UserID: &userId})
+	return user, notFound(err)
+}
+
+func (u users) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	user, err := database.GetUser(ctx, u.db, database.GetUserArgss{Username: &username})
+	return user, notFound(err)
+}
+
+func (u users) GetCredentials(ctx context.Context, phone *string, userId *int64) (*models.User, error) {
+	return u.pool.GetUser(ctx, u.db, database.GetUserParams{Phone: phone, UserID: userId})
+}
+
+func (u users) PhoneExists(ctx context.Context, phone string) (bool, error) {
+	return u.pool.PhoneExists(ctx, u.db, phone)
+}
+
+func (u users) Create(ctx context.Context, user models.User) (int64, error) {
+	return u.pool.CreateUser(ctx, u.db, user)
+}
+
+func (u users) UpdateCredentials(ctx context.Context, userId int64, phone, hash *string) error {
+	return u.pool.UpdateUser(ctx, u.db, phone, hash, userId)
+}
+
+func (u users) DeviceTokens(ctx context.Context, userIds ...int64) ([]string, error) {
+	return database.GetUserDevices(ctx, u.db, userIds...)
+}
+
+type followers struct {
+	db database.DBTX
+}
+
+func (f followers) Add(ctx context.Context, eventId, locationId, userId int64) error {
+	added, err := database.AddEventFollower(ctx, f.db, eventId, locationId, userId)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return repository.ErrAlreadyExists
+	}
+	return nil
+}
+
+func (f followers) Remove(ctx context.Context, eventId, userId int64) (int64, error) {
+	locationId, removed, err := database.RemoveEventFollower(ctx, f.db, eventId, userId)
+	if err != nil {
+		return 0, err
+	}
+	if !removed {
+		return 0, repository.ErrNotFound
+	}
+	if locationId == nil {
+		return 0, nil
+	}
+	return *locationId, nil
+}
+
+func (f followers) Exists(ctx context.Context, eventId, userId int64) (bool, error) {
+	return database.CheckEventFollower(ctx, f.db, eventId, userId)
+}
+
+func (f followers) List(ctx context.Context, eventId int64, username string, lastId int64) ([]models.Follower, error) {
+	return database.GetEventFollowers(ctx, f.db, eventId, username, lastId)
+}
+
+func (f followers) Each(ctx context.Context, eventId int64, fn func(models.Attendee) error) error {
+	return database.EachAttendee(ctx, f.db, eventId, fn)
+}
+
+type waitlist struct {
+	db database.DBTX
+}
+
+func (w waitlist) Join(ctx context.Context, eventId, locationId, userId int64) error {
+	return database.AddToWaitlist(ctx, w.db, eventId, locationId, userId)
+}
+
+func (w waitlist) Position(ctx context.Context, eventId, userId int64) (int64, int64, error) {
+	locationId, position, ok, err := database.GetWaitlistPosition(ctx, w.db, eventId, userId)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return 0, 0, repository.ErrNotFound
+	}
+	return locationId, position, nil
+}
+
+func (w waitlist) Leave(ctx context.Context, eventId, userId int64) error {
+	removed, err := database.RemoveFromWaitlist(ctx, w.db, eventId, userId)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (w waitlist) Next(ctx context.Context, locationId int64) (int64, error) {
+	userId, err := database.PopWaitlist(ctx, w.db, locationId)
+	if err != nil {
+		return 0, err
+	}
+	if userId == nil {
+		return 0, repository.ErrNotFound
+	}
+	return *userId, nil
+}
+
+type orders struct {
+	db database.DBTX
+}
+
+func (o orders) Create(ctx context.Context, order models.Order) (int64, error) {
+	id, created, err := database.CreateOrder(ctx, o.db, order)
+	if err != nil {
+		return 0, err
+	}
+	if !created {
+		return 0, repository.ErrAlreadyExists
+	}
+	return id, nil
+}
+
+func (o orders) Get(ctx context.Context, orderId int64) (models.Order, error) {
+	return found(database.GetOrder(ctx, o.db, orderId))
+}
+
+func (o orders) GetByPayment(ctx context.Context, paymentId string) (models.Order, error) {
+	return found(database.GetOrderByPayment(ctx, o.db, paymentId))
+}
+
+func (o orders) GetByUser(ctx context.Context, eventId, userId int64, statuses ...int) (models.Order, error) {
+	return found(database.GetUserOrder(ctx, o.db, eventId, userId, statuses...))
+}
+
+func found(order *models.Order, err error) (models.Order, error) {
+	if err != nil {
+		return models.Order{}, err
+	}
+	if order == nil {
+		return models.Order{}, repository.ErrNotFound
+	}
+	return *order, nil
+}
+
+func (o orders) SetPayment(ctx context.Context, orderId int64, provider, paymentId string) error {
+	return database.SetOrderPayment(ctx, o.db, orderId, provider, paymentId)
+}
+
+func (o orders) SetStatus(ctx context.Context, orderId int64, status int, from ...int) (bool, error) {
+	return database.SetOrderStatus(ctx, o.db, orderId, status, from...)
+}
+
+func (o orders) ListExpired(ctx context.Context, t time.Time) ([]models.Order, error) {
+	return database.GetExpiredOrders(ctx, o.db, t)
+}
+
+func (o orders) ListByEvent(ctx context.Context, eventId int64, statuses ...int) ([]models.Order, error) {
+	return database.GetEventOrders(ctx, o.db, eventId, statuses...)
+}
+
+type tickets struct {
+	db database.DBTX
+}
+
+func (t tickets) ListTypes(ctx context.Context, eventId int64) ([]models.TicketType, error) {
+	return database.GetTicketTypes(ctx, t.db, eventId)
+}
+
+func (t tickets) ReserveType(ctx context.Context, typeId int64) (bool, error) {
+	return database.ReserveTicketType(ctx, t.db, typeId)
+}
+
+func (t tickets) ReleaseType(ctx context.Context, typeId int64) error {
+	return database.ReleaseTicketType(ctx, t.db, typeId)
+}
+
+func (t tickets) GetPromo(ctx context.Context, eventId int64, code string) (models.PromoCode, error) {
+	promo, err := database.GetPromoCode(ctx, t.db, eventId, code)
+	if err != nil {
+		return models.PromoCode{}, err
+	}
+	if promo == nil {
+		return models.PromoCode{}, repository.ErrNotFound
+	}
+	return *promo, nil
+}
+
+func (t tickets) RedeemPromo(ctx context.Context, promoId int64) (bool, error) {
+	return database.RedeemPromoCode(ctx, t.db, promoId)
+}
+
+func (t tickets) ReleasePromo(ctx context.Context, promoId int64) error {
+	return database.ReleasePromoCode(ctx, t.db, promoId)
+}
+
+type comments struct {
+	db database.DBTX
+}
+
+func (c comments) Add(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	return database.CreateComment(ctx, c.db, comment)
+}
+
+func (c comments) GetAuthor(ctx context.Context, userId int64) (models.CommentAuthor, error) {
+	author, err := database.GetCommentAuthor(ctx, c.db, userId)
+	return author, notFound(err)
+}
+
+func (c comments) GetEventAuthor(ctx context.Context, eventId int64) (string, error) {
+	username, err := database.GetEventAuthorUsername(ctx, c.db, eventId)
+	return username, notFound(err)
+}
+
+func (c comments) ListParents(ctx context.Context, eventId, lastParentId int64) ([]models.Comment, error) {
+	return database.GetParentComments(ctx, c.db, eventId, lastParentId)
+}
+
+func (c comments) ListChildren(ctx context.Context, parentIds ...int64) ([]models.Comment, error) {
+	return database.GetChildComments(ctx, c.db, parentIds...)
+}
+
+type chat struct {
+	db database.DBTX
+}
+
+func (c chat) AddMember(ctx context.Context, eventId, userId, roleId int64) error {
+	return database.AddChatMember(ctx, c.db, eventId, userId, roleId)
+}
+
+func (c chat) SaveMessage(ctx context.Context, eventId, userId int64, message string) (models.ChatMessage, error) {
+	return database.CreateChatMessage(ctx, c.db, eventId, userId, message)
+}
+
+func (c chat) ListMessages(ctx context.Context, eventId, lastId int64) ([]models.ChatMessage, error) {
+	return database.GetChatMessages(ctx, c.db, eventId, lastId)
+}
+
+type tokens struct {
+	db database.DBTX
+}
+
+func (t tokens) Create(ctx context.Context, token models.Token) error {
+	return database.CreateToken(ctx, t.db, token)
+}
+
+func (t tokens) Get(ctx context.Context, token models.Token) (models.Token, error) {
+	found, err := database.GetToken(ctx, t.db, token)
+	return found, notFound(err)
+}
+
+func (t tokens) Delete(ctx context.Context, token models.Token) error {
+	return database.DeleteToken(ctx, t.db, token)
+}
+
+func (t tokens) Rotate(ctx context.Context, token string) error {
+	rotated, err := database.RotateToken(ctx, t.db, token)
+	if err != nil {
+		return err
+	}
+	if !rotated {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (t tokens) ListSessions(ctx context.Context, userId int64) ([]models.Session, error) {
+	return database.GetSessions(ctx, t.db, userId)
+}
+
+func (t tokens) DeleteSession(ctx context.Context, userId int64, sessionId string) error {
+	deleted, err := database.DeleteSession(ctx, t.db, userId, sessionId)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (t tokens) SessionExists(ctx context.Context, userId int64, sessionId string) (bool, error) {
+	return database.SessionExists(ctx, t.db, userId, sessionId)
+}
+
+type otps struct {
+	db database.DBTX
+}
+
+func (o otps) Create(ctx context.Context, otp models.Otp) error {
+	return database.CreateOtp(ctx, o.db, otp)
+}
+
+func (o otps) Get(ctx context.Context, otp models.Otp) (string, error) {
+	return database.GetOtp(ctx, o.db, otp)
+}
+
+func (o otps) Delete(ctx context.Context, otp models.Otp) error {
+	return database.DeleteOtp(ctx, o.db, otp)
+}
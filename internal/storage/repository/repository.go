@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/NuEventTeam/events/internal/models"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+)
+
+type Events interface {
+	GetByID(ctx context.Context, eventId int64) (*models.Event, error)
+	GetLocations(ctx context.Context, eventId int64) ([]models.Location, error)
+	CheckPermission(ctx context.Context, eventId, userId int64, permissionIds ...int64) (bool, error)
+	ChangeFollowerCount(ctx context.Context, eventId, by int64) error
+	ChangeAttendeesCount(ctx context.Context, locationId, by int64) error
+	// ReserveSeat takes a seat of the session, false when the session is full.
+	ReserveSeat(ctx context.Context, locationId int64) (bool, error)
+	IsLiked(ctx context.Context, eventId, userId int64) (bool, error)
+	// AddLike and RemoveLike report false when nothing changed.
+	AddLike(ctx context.Context, eventId, userId int64) (bool, error)
+	RemoveLike(ctx context.Context, eventId, userId int64) (bool, error)
+	ChangeLikeCount(ctx context.Context, eventId, by int64) error
+}
+
+type Users interface {
+	GetProfile(ctx context.Context, userId int64) (models.User, error)
+	GetByUsername(ctx context.Context, username string) (models.User, error)
+	// GetCredentials returns the id and password hash, nil when the user does not exist.
+	GetCredentials(ctx context.Context, phone *string, userId *int64) (*models.User, error)
+	PhoneExists(ctx context.Context, phone string) (bool, error)
+	Create(ctx context.Context, user models.User) (int64, error)
+	UpdateCredentials(ctx context.Context, userId int64, phone, hash *string) error
+	DeviceTokens(ctx context.Context, userIds ...int64) ([]string, error)
+}
+
+type Followers interface {
+	// Add registers the user to a session of the event, it returns
+	// ErrAlreadyExists when the user already follows the event.
+	Add(ctx context.Context, eventId, locationId, userId int64) error
+	// Remove returns the session the user was registered to, 0 when unknown,
+	// and ErrNotFound when the user does not follow the event.
+	Remove(ctx context.Context, eventId, userId int64) (int64, error)
+	Exists(ctx context.Context, eventId, userId int64) (bool, error)
+	List(ctx context.Context, eventId int64, username string, lastId int64) ([]models.Follower, error)
+	// Each calls fn with every follower of the event in the order they
+	// registered and stops at the first error of fn.
+	Each(ctx context.Context, eventId int64, fn func(models.Attendee) error) error
+}
+
+// Waitlist keeps the users waiting for a seat of a full session, first come
+// first served.
+type Waitlist interface {
+	// Join puts the user at the end of the line of the session, users already
+	// waiting for the event keep their place.
+	Join(ctx context.Context, eventId, locationId, userId int64) error
+	// Position returns the session the user waits for and their place in its
+	// line starting at 1, ErrNotFound when the user is not waiting.
+	Position(ctx context.Context, eventId, userId int64) (int64, int64, error)
+	// Leave returns ErrNotFound when the user is not waiting.
+	Leave(ctx context.Context, eventId, userId int64) error
+	// Next removes the user waiting longest for the session and returns them,
+	// ErrNotFound when nobody waits.
+	Next(ctx context.Context, locationId int64) (int64, error)
+}
+
+// Orders are the seats bought for paid events, a pending order holds its seat
+// until the order is paid or its hold expires.
+type Orders interface {
+	// Create returns ErrAlreadyExists when the user already has a pending or
+	// paid order for the event.
+	Create(ctx context.Context, order models.Order) (int64, error)
+	// Get, GetByPayment and GetByUser return ErrNotFound when there is no
+	// such order.
+	Get(ctx context.Context, orderId int64) (models.Order, error)
+	GetByPayment(ctx context.Context, paymentId string) (models.Order, error)
+	// GetByUser returns the latest order of the user for the event in one of
+	// the statuses.
+	GetByUser(ctx context.Context, eventId, userId int64, statuses ...int) (models.Order, error)
+	SetPayment(ctx context.Context, orderId int64, provider, paymentId string) error
+	// SetStatus moves the order to status from one of the from statuses, it
+	// reports false when the order is in none of them.
+	SetStatus(ctx context.Context, orderId int64, status int, from ...int) (bool, error)
+	// ListExpired returns the pending orders whose hold ended before t.
+	ListExpired(ctx context.Context, t time.Time) ([]models.Order, error)
+	ListByEvent(ctx context.Context, eventId int64, statuses ...int) ([]models.Order, error)
+}
+
+// Tickets are the ticket types and promo codes of events.
+type Tickets interface {
+	ListTypes(ctx context.Context, eventId int64) ([]models.TicketType, error)
+	// ReserveType takes a ticket of the quota of the type, false when it is
+	// sold out.
+	ReserveType(ctx context.Context, typeId int64) (bool, error)
+	ReleaseType(ctx context.Context, typeId int64) error
+	// GetPromo finds a code of the event ignoring case, ErrNotFound when there
+	// is none.
+	GetPromo(ctx context.Context, eventId int64, code string) (models.PromoCode, error)
+	// RedeemPromo uses the code once, false when it has no uses left.
+	RedeemPromo(ctx context.Context, promoId int64) (bool, error)
+	ReleasePromo(ctx context.Context, promoId int64) error
+}
+
+type Comments interface {
+	Add(ctx context.Context, comment models.Comment) (models.Comment, error)
+	GetAuthor(ctx context.Context, userId int64) (models.CommentAuthor, error)
+	GetEventAuthor(ctx context.Context, eventId int64) (string, error)
+	ListParents(ctx context.Context, eventId, lastParentId int64) ([]models.Comment, error)
+	ListChildren(ctx context.Context, parentIds ...int64) ([]models.Comment, error)
+}
+
+type Chat interface {
+	AddMember(ctx context.Context, eventId, userId, roleId int64) error
+	SaveMessage(ctx context.Context, eventId, userId int64, message string) (models.ChatMessage, error)
+	ListMessages(ctx context.Context, eventId, lastId int64) ([]models.ChatMessage, error)
+}
+
+type Tokens interface {
+	Create(ctx context.Context, token models.Token) error
+	// Get returns ErrNotFound when there is no live token matching the filter.
+	// Rotated refresh tokens are returned too, with RotatedAt set.
+	Get(ctx context.Context, token models.Token) (models.Token, error)
+	Delete(ctx context.Context, token models.Token) error
+	// Rotate marks a refresh token as replaced by a newer one of its session.
+	// It returns ErrNotFound when the token was already rotated.
+	Rotate(ctx context.Context, token string) error
+	ListSessions(ctx context.Context, userId int64) ([]models.Session, error)
+	// DeleteSession returns ErrNotFound when the user has no such session.
+	DeleteSession(ctx context.Context, userId int64, sessionId string) error
+	SessionExists(ctx context.Context, userId int64, sessionId string) (bool, error)
+}
+
+type Otps interface {
+	Create(ctx context.Context, otp models.Otp) error
+	// Get returns the live code for the phone and type, empty when there is none.
+	Get(ctx context.Context, otp models.Otp) (string, error)
+	Delete(ctx context.Context, otp models.Otp) error
+}
+
+type Store interface {
+	Events() Events
+	Users() Users
+	Followers() Followers
+	Waitlist() Waitlist
+	Orders() Orders
+	Tickets() Tickets
+	Comments() Comments
+	Chat() Chat
+	Tokens() Tokens
+	Otps() Otps
+
+	// WithTx runs fn against a transactional store. The transaction is
+	// committed when fn returns nil and rolled back otherwise.
+	WithTx(ctx context.Context, fn func(tx Store) error) error
+}
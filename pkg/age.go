@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrAgeRestricted = errors.New("age restricted")
+
+// Age returns the age in full years of someone born on birthDate at t.
+func Age(birthDate, t time.Time) int64 {
+	years := int64(t.Year() - birthDate.Year())
+	if t.Month() < birthDate.Month() || (t.Month() == birthDate.Month() && t.Day() < birthDate.Day()) {
+		years--
+	}
+	return years
+}
+
+// CheckAge returns ErrAgeRestricted with the reason when someone born on
+// birthDate cannot attend an event for ages minAge to maxAge at t. Limits that
+// are nil or 0 do not apply and a zero birthDate is an unknown age.
+func CheckAge(birthDate time.Time, minAge, maxAge *int64, t time.Time) error {
+	hasMin := minAge != nil && *minAge > 0
+	hasMax := maxAge != nil && *maxAge > 0
+	if !hasMin && !hasMax {
+		return nil
+	}
+
+	if birthDate.IsZero() {
+		return fmt.Errorf("%w: add your birth date to register for this event", ErrAgeRestricted)
+	}
+
+	age := Age(birthDate, t)
+	if hasMin && age < *minAge {
+		return fmt.Errorf("%w: event is for ages %d and older", ErrAgeRestricted, *minAge)
+	}
+	if hasMax && age > *maxAge {
+		return fmt.Errorf("%w: event is for ages up to %d", ErrAgeRestricted, *maxAge)
+	}
+	return nil
+}
@@ -7,15 +7,22 @@ const (
 )
 
 const (
-	PermissionRead   = 1
-	PermissionUpdate = 2
-	PermissionVerify = 3
+	PermissionRead       = 1
+	PermissionUpdate     = 2
+	PermissionVerify     = 3
+	PermissionManageTeam = 4
+	PermissionViewPhones = 5
 )
 
 const (
 	ChatRoleUser  = 0
 	ChatRoleAdmin = 1
 )
+
+const (
+	ChatMessageKindUser   = 0
+	ChatMessageKindSystem = 1
+)
 const MinCategories = 3
 
 const (
@@ -25,6 +32,37 @@ const (
 	EventStatusFinished = 4
 )
 
+const (
+	InvitationStatusPending  = 1
+	InvitationStatusAccepted = 2
+	InvitationStatusDeclined = 3
+	InvitationStatusRevoked  = 4
+)
+
+const (
+	OrderStatusPending  = 1
+	OrderStatusPaid     = 2
+	OrderStatusExpired  = 3
+	OrderStatusCanceled = 4
+	OrderStatusRefunded = 5
+)
+
+const (
+	PromoKindPercent = 1
+	PromoKindFixed   = 2
+)
+
+const (
+	CheckInOK            = 1
+	CheckInDuplicate     = 2
+	CheckInWrongEvent    = 3
+	CheckInExpired       = 4
+	CheckInInvalid       = 5
+	CheckInNotRegistered = 6
+	CheckInWrongSession  = 7
+	CheckInCanceled      = 8
+)
+
 const (
 	EventNamespace = "event"
 	UserNamespace  = "user"
@@ -0,0 +1,238 @@
+// Package rrule implements the subset of RFC 5545 recurrence rules used by
+// recurring events: FREQ=DAILY, WEEKLY or MONTHLY with INTERVAL, COUNT, UNTIL
+// and, for weekly rules, BYDAY.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+)
+
+const untilLayout = "20060102T150405Z"
+
+// maxIterations bounds the periods a rule is expanded for, so a rule far in
+// the past cannot keep a request busy.
+const maxIterations = 100000
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+type Rule struct {
+	Freq     Freq
+	Interval int
+	Count    int
+	Until    *time.Time
+	ByDay    []time.Weekday
+}
+
+// Parse parses a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10",
+// an optional "RRULE:" prefix is accepted.
+func Parse(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("%w: %q", ErrInvalidRule, part)
+		}
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.Freq = Freq(strings.ToUpper(value))
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: interval %q", ErrInvalidRule, value)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: count %q", ErrInvalidRule, value)
+			}
+			r.Count = n
+		case "UNTIL":
+			t, err := parseUntil(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("%w: until %q", ErrInvalidRule, value)
+			}
+			r.Until = &t
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				day, ok := weekdays[strings.ToUpper(d)]
+				if !ok {
+					return Rule{}, fmt.Errorf("%w: day %q", ErrInvalidRule, d)
+				}
+				if !slices.Contains(r.ByDay, day) {
+					r.ByDay = append(r.ByDay, day)
+				}
+			}
+		default:
+			return Rule{}, fmt.Errorf("%w: unsupported part %q", ErrInvalidRule, name)
+		}
+	}
+
+	return r, r.validate()
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse(untilLayout, value); err == nil {
+		return t, nil
+	}
+	// a date UNTIL includes the whole day
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+func (r Rule) validate() error {
+	switch r.Freq {
+	case Daily, Weekly, Monthly:
+	case "":
+		return fmt.Errorf("%w: freq is missing", ErrInvalidRule)
+	default:
+		return fmt.Errorf("%w: unsupported freq %q", ErrInvalidRule, r.Freq)
+	}
+
+	if r.Count > 0 && r.Until != nil {
+		return fmt.Errorf("%w: count and until cannot be used together", ErrInvalidRule)
+	}
+	if len(r.ByDay) > 0 && r.Freq != Weekly {
+		return fmt.Errorf("%w: byday is only supported for weekly rules", ErrInvalidRule)
+	}
+	return nil
+}
+
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for name, day := range weekdays {
+			if slices.Contains(r.ByDay, day) {
+				days = append(days, name)
+			}
+		}
+		slices.SortFunc(days, func(a, b string) int {
+			return isoWeekday(weekdays[a]) - isoWeekday(weekdays[b])
+		})
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Finite reports whether the rule ends by count or until.
+func (r Rule) Finite() bool {
+	return r.Count > 0 || r.Until != nil
+}
+
+// Between returns the occurrences of the rule starting at dtstart which fall
+// in [from, to). COUNT is counted from dtstart, so occurrences before from
+// still use up the count.
+func (r Rule) Between(dtstart, from, to time.Time) []time.Time {
+	var (
+		occurrences []time.Time
+		n           int
+	)
+
+	for period := 0; period < maxIterations; period++ {
+		candidates := r.period(dtstart, period)
+		if candidates == nil {
+			continue
+		}
+
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return occurrences
+			}
+			if !t.Before(to) {
+				return occurrences
+			}
+			n++
+			if r.Count > 0 && n > r.Count {
+				return occurrences
+			}
+			if !t.Before(from) {
+				occurrences = append(occurrences, t)
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// period returns the candidate occurrences of the nth period, nil when the
+// period has none, such as the 31st of a month with 30 days.
+func (r Rule) period(dtstart time.Time, n int) []time.Time {
+	step := n * r.Interval
+
+	switch r.Freq {
+	case Daily:
+		return []time.Time{dtstart.AddDate(0, 0, step)}
+	case Monthly:
+		y, m, d := dtstart.Date()
+		t := time.Date(y, m+time.Month(step), d, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+		if t.Day() != d {
+			return nil
+		}
+		return []time.Time{t}
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{dtstart.AddDate(0, 0, 7*step)}
+		}
+		// weeks start on monday
+		monday := dtstart.AddDate(0, 0, 7*step-isoWeekday(dtstart.Weekday()))
+		days := slices.Clone(r.ByDay)
+		slices.SortFunc(days, func(a, b time.Weekday) int { return isoWeekday(a) - isoWeekday(b) })
+
+		candidates := make([]time.Time, 0, len(days))
+		for _, d := range days {
+			candidates = append(candidates, monday.AddDate(0, 0, isoWeekday(d)))
+		}
+		return candidates
+	}
+	return nil
+}
+
+// isoWeekday numbers the days from monday, 0, to sunday, 6.
+func isoWeekday(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
@@ -0,0 +1,145 @@
+package rrule
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 10, 0, 0, 0, time.UTC)
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		dtstart  time.Time
+		from, to time.Time
+		want     []time.Time
+	}{
+		{
+			name:    "byday starts mid week",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE,SU",
+			dtstart: day(2024, 1, 3), // wednesday
+			from:    day(2024, 1, 3),
+			to:      day(2024, 1, 15),
+			want:    []time.Time{day(2024, 1, 3), day(2024, 1, 7), day(2024, 1, 8), day(2024, 1, 10), day(2024, 1, 14)},
+		},
+		{
+			name:    "byday starts on sunday, the last day of the week",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,SU",
+			dtstart: day(2024, 1, 7),
+			from:    day(2024, 1, 7),
+			to:      day(2024, 1, 15),
+			want:    []time.Time{day(2024, 1, 7), day(2024, 1, 8), day(2024, 1, 14)},
+		},
+		{
+			name:    "byday every other week",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=WE,SU",
+			dtstart: day(2024, 1, 3),
+			from:    day(2024, 1, 3),
+			to:      day(2024, 1, 22),
+			want:    []time.Time{day(2024, 1, 3), day(2024, 1, 7), day(2024, 1, 17), day(2024, 1, 21)},
+		},
+		{
+			name:    "monthly skips months without the day",
+			rule:    "FREQ=MONTHLY",
+			dtstart: day(2024, 1, 31),
+			from:    day(2024, 1, 1),
+			to:      day(2024, 8, 1),
+			want:    []time.Time{day(2024, 1, 31), day(2024, 3, 31), day(2024, 5, 31), day(2024, 7, 31)},
+		},
+		{
+			name:    "skipped months do not use up the count",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: day(2024, 1, 31),
+			from:    day(2024, 1, 1),
+			to:      day(2025, 1, 1),
+			want:    []time.Time{day(2024, 1, 31), day(2024, 3, 31), day(2024, 5, 31)},
+		},
+		{
+			name:    "until includes its whole day",
+			rule:    "FREQ=DAILY;UNTIL=20240103",
+			dtstart: day(2024, 1, 1),
+			from:    day(2024, 1, 1),
+			to:      day(2024, 2, 1),
+			want:    []time.Time{day(2024, 1, 1), day(2024, 1, 2), day(2024, 1, 3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := r.Between(tt.dtstart, tt.from, tt.to)
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("Between() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// materializing a rule window by window must give the same occurrences as
+// expanding it at once, COUNT included.
+func TestBetweenCountAcrossWindows(t *testing.T) {
+	r, err := Parse("FREQ=DAILY;COUNT=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dtstart := day(2024, 1, 1)
+
+	windows := []struct {
+		from, to time.Time
+		want     []time.Time
+	}{
+		{day(2024, 1, 1), day(2024, 1, 3), []time.Time{day(2024, 1, 1), day(2024, 1, 2)}},
+		{day(2024, 1, 3), day(2024, 1, 10), []time.Time{day(2024, 1, 3), day(2024, 1, 4), day(2024, 1, 5)}},
+		{day(2024, 1, 10), day(2024, 2, 1), nil},
+	}
+
+	for _, w := range windows {
+		got := r.Between(dtstart, w.from, w.to)
+		if !slices.EqualFunc(got, w.want, time.Time.Equal) {
+			t.Errorf("Between(%v, %v) = %v, want %v", w.from, w.to, got, w.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string
+		invalid bool
+	}{
+		{rule: "RRULE:freq=weekly;byday=we,mo;interval=2;count=10", want: "FREQ=WEEKLY;INTERVAL=2;COUNT=10;BYDAY=MO,WE"},
+		{rule: "FREQ=DAILY;UNTIL=20240103T000000Z", want: "FREQ=DAILY;UNTIL=20240103T000000Z"},
+		{rule: "INTERVAL=2", invalid: true},
+		{rule: "FREQ=YEARLY", invalid: true},
+		{rule: "FREQ=DAILY;BYDAY=MO", invalid: true},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20240103", invalid: true},
+		{rule: "FREQ=DAILY;COUNT=0", invalid: true},
+		{rule: "FREQ=WEEKLY;BYDAY=XX", invalid: true},
+	}
+
+	for _, tt := range tests {
+		r, err := Parse(tt.rule)
+		if tt.invalid {
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidRule", tt.rule, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.rule, err)
+			continue
+		}
+		if r.String() != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.rule, r.String(), tt.want)
+		}
+	}
+}
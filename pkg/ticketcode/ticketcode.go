@@ -0,0 +1,145 @@
+// Package ticketcode encodes event tickets as compact payloads signed with
+// Ed25519, so they can be verified offline with the published public keys.
+//
+// A code is "<kid>.<payload>.<signature>" where payload and signature are
+// base64url without padding. The payload is a version byte, the 16 bytes of
+// the ticket id and the uvarint fields of Ticket in their order. The
+// signature covers "<kid>.<payload>".
+//
+// Tickets of canceled events are not revoked through their codes, verifiers
+// also have to reject the tickets matched by the published revocations.
+package ticketcode
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"github.com/oklog/ulid/v2"
+	"strings"
+	"time"
+)
+
+const version = 1
+
+var (
+	ErrMalformed    = errors.New("malformed ticket code")
+	ErrUnknownKey   = errors.New("ticket code is signed with an unknown key")
+	ErrBadSignature = errors.New("invalid ticket signature")
+	ErrExpired      = errors.New("ticket expired")
+)
+
+var encoding = base64.RawURLEncoding
+
+type Ticket struct {
+	ID         ulid.ULID
+	EventID    int64
+	LocationID int64
+	UserID     int64
+	// TicketTypeID is 0 for events without ticket types.
+	TicketTypeID int64
+	IssuedAt     time.Time
+	ExpiresAt    time.Time
+}
+
+// Revocation voids the tickets of the event issued before IssuedBefore.
+type Revocation struct {
+	EventID      int64     `json:"eventId"`
+	IssuedBefore time.Time `json:"issuedBefore"`
+}
+
+// Revokes reports whether the ticket is void, issue times are whole seconds so
+// a ticket issued in the second of the revocation is void too.
+func (r Revocation) Revokes(t Ticket) bool {
+	return t.EventID == r.EventID && t.IssuedAt.Before(r.IssuedBefore)
+}
+
+// Encode signs the ticket with the key named kid.
+func Encode(t Ticket, kid string, key ed25519.PrivateKey) string {
+	buf := make([]byte, 0, 1+len(t.ID)+6*binary.MaxVarintLen64)
+	buf = append(buf, version)
+	buf = append(buf, t.ID[:]...)
+	for _, v := range []int64{t.EventID, t.LocationID, t.UserID, t.TicketTypeID, t.IssuedAt.Unix(), t.ExpiresAt.Unix()} {
+		buf = binary.AppendUvarint(buf, uint64(v))
+	}
+
+	signed := kid + "." + encoding.EncodeToString(buf)
+	return signed + "." + encoding.EncodeToString(ed25519.Sign(key, []byte(signed)))
+}
+
+// IsCode tells codes apart from other ticket references such as the legacy
+// ticket keys, it does not validate the code.
+func IsCode(s string) bool {
+	return strings.Count(s, ".") == 2
+}
+
+// Verify checks the signature of the code with the key its kid names and
+// returns its ticket, ErrExpired once the ticket expired at now.
+func Verify(code string, keys func(kid string) (ed25519.PublicKey, bool), now time.Time) (Ticket, error) {
+	kid, payload, signature, err := split(code)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	key, ok := keys(kid)
+	if !ok {
+		return Ticket{}, ErrUnknownKey
+	}
+	if !ed25519.Verify(key, []byte(code[:strings.LastIndexByte(code, '.')]), signature) {
+		return Ticket{}, ErrBadSignature
+	}
+
+	t, err := decode(payload)
+	if err != nil {
+		return Ticket{}, err
+	}
+	if !now.Before(t.ExpiresAt) {
+		return t, ErrExpired
+	}
+
+	return t, nil
+}
+
+func split(code string) (string, []byte, []byte, error) {
+	parts := strings.Split(code, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", nil, nil, ErrMalformed
+	}
+
+	payload, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return "", nil, nil, ErrMalformed
+	}
+
+	return parts[0], payload, signature, nil
+}
+
+func decode(payload []byte) (Ticket, error) {
+	var t Ticket
+	if len(payload) < 1+len(t.ID) || payload[0] != version {
+		return Ticket{}, ErrMalformed
+	}
+	copy(t.ID[:], payload[1:])
+	rest := payload[1+len(t.ID):]
+
+	var fields [6]int64
+	for i := range fields {
+		v, n := binary.Uvarint(rest)
+		if n <= 0 {
+			return Ticket{}, ErrMalformed
+		}
+		fields[i] = int64(v)
+		rest = rest[n:]
+	}
+	if len(rest) != 0 {
+		return Ticket{}, ErrMalformed
+	}
+
+	t.EventID, t.LocationID, t.UserID, t.TicketTypeID = fields[0], fields[1], fields[2], fields[3]
+	t.IssuedAt, t.ExpiresAt = time.Unix(fields[4], 0), time.Unix(fields[5], 0)
+	return t, nil
+}
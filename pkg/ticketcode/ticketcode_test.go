@@ -0,0 +1,117 @@
+package ticketcode
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"github.com/oklog/ulid/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return public, private
+}
+
+func keysOf(keys map[string]ed25519.PublicKey) func(string) (ed25519.PublicKey, bool) {
+	return func(kid string) (ed25519.PublicKey, bool) {
+		key, ok := keys[kid]
+		return key, ok
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	public, private := newKey(t)
+	now := time.Unix(1700000000, 0)
+	ticket := Ticket{
+		ID:           ulid.Make(),
+		EventID:      42,
+		LocationID:   7,
+		UserID:       1 << 40,
+		TicketTypeID: 3,
+		IssuedAt:     now,
+		ExpiresAt:    now.Add(48 * time.Hour),
+	}
+
+	code := Encode(ticket, "t1", private)
+	if !IsCode(code) {
+		t.Fatalf("IsCode(%q) = false", code)
+	}
+
+	got, err := Verify(code, keysOf(map[string]ed25519.PublicKey{"t1": public}), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != ticket {
+		t.Errorf("Verify() = %+v, want %+v", got, ticket)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	public, private := newKey(t)
+	otherPublic, _ := newKey(t)
+	now := time.Unix(1700000000, 0)
+	ticket := Ticket{ID: ulid.Make(), EventID: 1, LocationID: 2, UserID: 3, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}
+	code := Encode(ticket, "t1", private)
+
+	// flipping a bit of the payload keeps the code well formed
+	parts := strings.Split(code, ".")
+	payload, _ := encoding.DecodeString(parts[1])
+	payload[len(payload)-1] ^= 1
+	tampered := parts[0] + "." + encoding.EncodeToString(payload) + "." + parts[2]
+
+	tests := []struct {
+		name string
+		code string
+		keys map[string]ed25519.PublicKey
+		now  time.Time
+		err  error
+	}{
+		{name: "valid", code: code, keys: map[string]ed25519.PublicKey{"t1": public}, now: now},
+		{name: "retired key", code: code, keys: map[string]ed25519.PublicKey{"t2": otherPublic, "t1": public}, now: now},
+		{name: "tampered payload", code: tampered, keys: map[string]ed25519.PublicKey{"t1": public}, now: now, err: ErrBadSignature},
+		{name: "other key with the same kid", code: code, keys: map[string]ed25519.PublicKey{"t1": otherPublic}, now: now, err: ErrBadSignature},
+		{name: "unknown kid", code: code, keys: map[string]ed25519.PublicKey{"t2": public}, now: now, err: ErrUnknownKey},
+		{name: "renamed kid", code: "t2" + strings.TrimPrefix(code, "t1"), keys: map[string]ed25519.PublicKey{"t2": public}, now: now, err: ErrBadSignature},
+		{name: "just before expiry", code: code, keys: map[string]ed25519.PublicKey{"t1": public}, now: now.Add(time.Hour - time.Second)},
+		{name: "expired", code: code, keys: map[string]ed25519.PublicKey{"t1": public}, now: now.Add(time.Hour), err: ErrExpired},
+		{name: "missing part", code: parts[0] + "." + parts[1], keys: map[string]ed25519.PublicKey{"t1": public}, now: now, err: ErrMalformed},
+		{name: "short signature", code: parts[0] + "." + parts[1] + ".AAAA", keys: map[string]ed25519.PublicKey{"t1": public}, now: now, err: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(tt.code, keysOf(tt.keys), tt.now)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRevocation(t *testing.T) {
+	canceledAt := time.Unix(1700000000, 500_000_000)
+	r := Revocation{EventID: 1, IssuedBefore: canceledAt}
+
+	tests := []struct {
+		name   string
+		ticket Ticket
+		want   bool
+	}{
+		{name: "issued before", ticket: Ticket{EventID: 1, IssuedAt: canceledAt.Add(-time.Hour)}, want: true},
+		{name: "issued in the same second", ticket: Ticket{EventID: 1, IssuedAt: canceledAt.Truncate(time.Second)}, want: true},
+		{name: "issued after", ticket: Ticket{EventID: 1, IssuedAt: canceledAt.Add(time.Second)}},
+		{name: "other event", ticket: Ticket{EventID: 2, IssuedAt: canceledAt.Add(-time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		if got := r.Revokes(tt.ticket); got != tt.want {
+			t.Errorf("%s: Revokes() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
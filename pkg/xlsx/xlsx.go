@@ -0,0 +1,97 @@
+// Package xlsx streams a workbook of a single sheet of text cells. Rows are
+// written as they come, so large sheets are never kept in memory.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+const header = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+var parts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+type Writer struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	err   error
+}
+
+// NewWriter starts a workbook whose only sheet is named sheetName, errors are
+// returned by the first Write or Close.
+func NewWriter(w io.Writer, sheetName string) *Writer {
+	x := &Writer{zw: zip.NewWriter(w)}
+
+	for _, p := range parts {
+		x.writePart(p.name, p.body)
+	}
+
+	var name bytes.Buffer
+	_ = xml.EscapeText(&name, []byte(sheetName))
+	x.writePart("xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" `+
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+
+		`<sheets><sheet name="`+name.String()+`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+
+	if x.err == nil {
+		x.sheet, x.err = x.zw.Create("xl/worksheets/sheet1.xml")
+	}
+	x.writeString(header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	return x
+}
+
+// Write adds a row of text cells to the sheet.
+func (x *Writer) Write(row []string) error {
+	x.writeString(`<row>`)
+	for _, cell := range row {
+		x.writeString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if x.err == nil {
+			x.err = xml.EscapeText(x.sheet, []byte(cell))
+		}
+		x.writeString(`</t></is></c>`)
+	}
+	x.writeString(`</row>`)
+	return x.err
+}
+
+// Close ends the sheet and the workbook, it does not close the underlying
+// writer.
+func (x *Writer) Close() error {
+	x.writeString(`</sheetData></worksheet>`)
+	if x.err != nil {
+		return x.err
+	}
+	return x.zw.Close()
+}
+
+func (x *Writer) writePart(name, body string) {
+	if x.err != nil {
+		return
+	}
+	var f io.Writer
+	f, x.err = x.zw.Create(name)
+	if x.err == nil {
+		_, x.err = io.WriteString(f, header+body)
+	}
+}
+
+func (x *Writer) writeString(s string) {
+	if x.err == nil {
+		_, x.err = io.WriteString(x.sheet, s)
+	}
+}